@@ -0,0 +1,180 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	backup "github.com/iamblueslime/shulker/libs/backup/src"
+	sidecar "github.com/iamblueslime/shulker/libs/sidecar/src"
+)
+
+const serverProcessName = "java"
+const processPollInterval = 2 * time.Second
+
+func main() {
+	var dataDir, tmpDir, procDir, credentialsDir string
+	var schedule string
+	var retention int
+	var onShutdown bool
+	var rconAddress string
+	var s3Endpoint, s3Region, s3Bucket, s3Prefix string
+	flag.StringVar(&dataDir, "data-dir", "/data", "Directory of the server containing the worlds.")
+	flag.StringVar(&tmpDir, "tmp-dir", os.TempDir(), "Directory where the archives are written before being uploaded.")
+	flag.StringVar(&procDir, "proc-dir", "/proc", "Directory of the processes of the Pod.")
+	flag.StringVar(&credentialsDir, "credentials-dir", "/mnt/shulker/backup-credentials", "Directory containing the accessKeyId and secretAccessKey files.")
+	flag.StringVar(&schedule, "schedule", "", "Cron expression of the backups.")
+	flag.IntVar(&retention, "retention", 0, "Number of backups to keep, 0 keeps all of them.")
+	flag.BoolVar(&onShutdown, "on-shutdown", false, "Whether to take a backup when the server stops.")
+	flag.StringVar(&rconAddress, "rcon-address", "localhost:25575", "Address of the RCON server of the Minecraft server.")
+	flag.StringVar(&s3Endpoint, "s3-endpoint", "", "URL of the S3-compatible storage.")
+	flag.StringVar(&s3Region, "s3-region", "us-east-1", "Region of the bucket.")
+	flag.StringVar(&s3Bucket, "s3-bucket", "", "Name of the bucket.")
+	flag.StringVar(&s3Prefix, "s3-prefix", "", "Prefix of the object keys.")
+	opts := zap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	logger := zap.New(zap.UseFlagOptions(&opts))
+
+	accessKeyId, err := readCredential(credentialsDir, "accessKeyId")
+	if err != nil {
+		logger.Error(err, "unable to read the storage credentials")
+		os.Exit(1)
+	}
+	secretAccessKey, err := readCredential(credentialsDir, "secretAccessKey")
+	if err != nil {
+		logger.Error(err, "unable to read the storage credentials")
+		os.Exit(1)
+	}
+
+	var parsedSchedule *backup.Schedule
+	if schedule != "" {
+		parsedSchedule, err = backup.ParseSchedule(schedule)
+		if err != nil {
+			logger.Error(err, "invalid schedule")
+			os.Exit(1)
+		}
+	}
+
+	rconPassword := os.Getenv("RCON_PASSWORD")
+	backuper := &backup.Backuper{
+		S3: &backup.S3Client{
+			Endpoint:        s3Endpoint,
+			Region:          s3Region,
+			Bucket:          s3Bucket,
+			AccessKeyId:     accessKeyId,
+			SecretAccessKey: secretAccessKey,
+		},
+		Logger:    logger,
+		DataDir:   dataDir,
+		TmpDir:    tmpDir,
+		Prefix:    strings.Trim(s3Prefix, "/"),
+		Retention: retention,
+		DialRcon: func() (*backup.RconClient, error) {
+			return backup.DialRcon(rconAddress, rconPassword, 10*time.Second)
+		},
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+	// The server takes some time to start, there is nothing to save
+	// before it does
+	logger.Info("Waiting for the server to start")
+	if !waitForProcess(procDir, true, signals) {
+		logger.Info("Stopped before the server started")
+		return
+	}
+	logger.Info("Server started")
+
+	ctx := context.Background()
+	var nextBackup <-chan time.Time
+	scheduleNextBackup := func() {
+		if parsedSchedule != nil {
+			next := parsedSchedule.Next(time.Now())
+			logger.Info("Scheduled next backup", "time", next)
+			nextBackup = time.After(time.Until(next))
+		}
+	}
+	scheduleNextBackup()
+
+	processPoll := time.NewTicker(processPollInterval)
+	defer processPoll.Stop()
+
+	for {
+		select {
+		case <-nextBackup:
+			if err := backuper.Backup(ctx, true); err != nil {
+				logger.Error(err, "Scheduled backup failed")
+			}
+			scheduleNextBackup()
+
+		case <-processPoll.C:
+			if sidecar.IsProcessRunning(procDir, serverProcessName) {
+				continue
+			}
+			logger.Info("Server stopped")
+			shutdown(ctx, logger, backuper, onShutdown)
+			return
+
+		case <-signals:
+			// The server saves its worlds when stopping, the backup
+			// is only consistent once it is done
+			logger.Info("Waiting for the server to stop")
+			waitForProcess(procDir, false, nil)
+			shutdown(ctx, logger, backuper, onShutdown)
+			return
+		}
+	}
+}
+
+func shutdown(ctx context.Context, logger logr.Logger, backuper *backup.Backuper, onShutdown bool) {
+	if !onShutdown {
+		return
+	}
+
+	// The sidecar exits successfully anyway, a failure would end the
+	// Pod as Failed and keep the server from being started again
+	if err := backuper.Backup(ctx, false); err != nil {
+		logger.Error(err, "Shutdown backup failed")
+	}
+}
+
+// Waits for the server process to be running or stopped. Returns
+// false if a signal was received before.
+func waitForProcess(procDir string, running bool, signals <-chan os.Signal) bool {
+	ticker := time.NewTicker(processPollInterval)
+	defer ticker.Stop()
+
+	for sidecar.IsProcessRunning(procDir, serverProcessName) != running {
+		select {
+		case <-ticker.C:
+		case <-signals:
+			return false
+		}
+	}
+
+	return true
+}
+
+func readCredential(credentialsDir string, name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(credentialsDir, name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	cache "github.com/iamblueslime/shulker/libs/cache/src"
+	initfs "github.com/iamblueslime/shulker/libs/initfs/src"
+)
+
+func main() {
+	var dir, listenAddress, maxSize string
+	var retries int
+	var retryDelay, timeout time.Duration
+	flag.StringVar(&dir, "dir", "/var/cache/shulker", "Directory the resources are stored in.")
+	flag.StringVar(&listenAddress, "listen-address", ":8080", "Address the HTTP server listens on.")
+	flag.StringVar(&maxSize, "max-size", "0", "Maximum size of the stored resources, e.g. 10Gi. No limit when 0.")
+	flag.IntVar(&retries, "retries", 3, "Number of retries of a failed download.")
+	flag.DurationVar(&retryDelay, "retry-delay", 2*time.Second, "Delay before the first retry, doubled for each retry.")
+	flag.DurationVar(&timeout, "timeout", 5*time.Minute, "Timeout of a download attempt.")
+	opts := zap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	logger := zap.New(zap.UseFlagOptions(&opts))
+
+	maxSizeQuantity, err := resource.ParseQuantity(maxSize)
+	if err != nil {
+		logger.Error(err, "invalid maximum size")
+		os.Exit(1)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.Error(err, "unable to create cache directory")
+		os.Exit(1)
+	}
+
+	server := &cache.Server{
+		Dir: dir,
+		Downloader: &initfs.Downloader{
+			HTTPClient: &http.Client{Timeout: timeout},
+			Logger:     logger,
+			Retries:    retries,
+			RetryDelay: retryDelay,
+		},
+		Logger:  logger,
+		MaxSize: maxSizeQuantity.Value(),
+	}
+
+	httpServer := &http.Server{
+		Addr:              listenAddress,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Starting cache", "address", listenAddress, "dir", dir)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error(err, "unable to serve")
+		os.Exit(1)
+	}
+}
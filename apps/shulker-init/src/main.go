@@ -0,0 +1,66 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	initfs "github.com/iamblueslime/shulker/libs/initfs/src"
+)
+
+func main() {
+	var manifestPath, resultPath string
+	var retries int
+	var retryDelay, timeout time.Duration
+	flag.StringVar(&manifestPath, "manifest", "/mnt/shulker/init/manifest.json", "Path of the manifest to follow.")
+	flag.StringVar(&resultPath, "result-file", "/dev/termination-log", "Path where the result is written.")
+	flag.IntVar(&retries, "retries", 3, "Number of retries of a failed download.")
+	flag.DurationVar(&retryDelay, "retry-delay", 2*time.Second, "Delay before the first retry, doubled for each retry.")
+	flag.DurationVar(&timeout, "timeout", 5*time.Minute, "Timeout of a download attempt.")
+	opts := zap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	logger := zap.New(zap.UseFlagOptions(&opts))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	var result *initfs.Result
+	manifest, err := initfs.ReadManifest(manifestPath)
+	if err != nil {
+		result = &initfs.Result{Error: err.Error()}
+	} else {
+		initializer := &initfs.Initializer{
+			Downloader: &initfs.Downloader{
+				HTTPClient: &http.Client{Timeout: timeout},
+				Logger:     logger,
+				Retries:    retries,
+				RetryDelay: retryDelay,
+			},
+			Logger: logger,
+		}
+		result = initializer.Run(ctx, manifest)
+	}
+
+	if err := initfs.WriteResult(resultPath, result); err != nil {
+		logger.Error(err, "unable to write result")
+	}
+
+	if !result.Success {
+		logger.Info("Initialization failed", "error", result.Error)
+		os.Exit(1)
+	}
+	logger.Info("Initialization succeeded")
+}
@@ -8,6 +8,7 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
@@ -17,9 +18,12 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	controllers "github.com/iamblueslime/shulker/libs/controllers/src"
 	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	resources "github.com/iamblueslime/shulker/libs/resources/src"
+	webhooks "github.com/iamblueslime/shulker/libs/webhooks/src"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -38,16 +42,25 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var minecraftServerPingInterval time.Duration
+	var proxyPingInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&minecraftServerPingInterval, "minecraft-server-ping-interval", 30*time.Second,
+		"Interval between two Server List Pings of a ready MinecraftServer, to report its players in its status.")
+	flag.DurationVar(&proxyPingInterval, "proxy-ping-interval", 30*time.Second,
+		"Interval between two Server List Pings of a running Proxy, to report its players in its status.")
+	images := resources.NewDefaultImages()
+	images.BindFlags(flag.CommandLine)
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
+	images.Complete()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
@@ -65,42 +78,92 @@ func main() {
 	}
 
 	if err = (&controllers.MinecraftClusterReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("minecraftcluster-controller"),
+		Images:   images,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MinecraftCluster")
 		os.Exit(1)
 	}
 	if err = (&controllers.ProxyReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("proxy-controller"),
+		Images:       images,
+		PingInterval: proxyPingInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Proxy")
 		os.Exit(1)
 	}
 	if err = (&controllers.ProxyDeploymentReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("proxydeployment-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ProxyDeployment")
 		os.Exit(1)
 	}
 	if err = (&controllers.MinecraftServerReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("minecraftserver-controller"),
+		Images:       images,
+		PingInterval: minecraftServerPingInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MinecraftServer")
 		os.Exit(1)
 	}
 	if err = (&controllers.MinecraftServerDeploymentReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("minecraftserverdeployment-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MinecraftServerDeployment")
 		os.Exit(1)
 	}
+
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		// Webhooks read from the API server directly as resources
+		// created together would not be in the cache yet
+		if err = (&webhooks.MinecraftClusterWebhook{
+			Reader: mgr.GetAPIReader(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MinecraftCluster")
+			os.Exit(1)
+		}
+		if err = (&webhooks.ProxyWebhook{
+			Reader: mgr.GetAPIReader(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Proxy")
+			os.Exit(1)
+		}
+		if err = (&webhooks.ProxyDeploymentWebhook{
+			Reader: mgr.GetAPIReader(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ProxyDeployment")
+			os.Exit(1)
+		}
+		if err = (&webhooks.MinecraftServerWebhook{
+			Reader: mgr.GetAPIReader(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MinecraftServer")
+			os.Exit(1)
+		}
+		if err = (&webhooks.MinecraftServerDeploymentWebhook{
+			Reader: mgr.GetAPIReader(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MinecraftServerDeployment")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	if err := metrics.Registry.Register(&controllers.FleetCollector{Reader: mgr.GetClient()}); err != nil {
+		setupLog.Error(err, "unable to register metrics", "collector", "Fleet")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -0,0 +1,74 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	serverlistping "github.com/iamblueslime/shulker/libs/serverlistping/src"
+	sidecar "github.com/iamblueslime/shulker/libs/sidecar/src"
+)
+
+const processPollInterval = 2 * time.Second
+
+func main() {
+	var listenAddress, targetAddress, drainLockFile string
+	var procDir, processName string
+	var timeout time.Duration
+	flag.StringVar(&listenAddress, "listen-address", ":8081", "Address the HTTP server answering the probes listens on.")
+	flag.StringVar(&targetAddress, "target-address", "localhost:25565", "Address of the server to ping.")
+	flag.StringVar(&drainLockFile, "drain-lock-file", "", "File whose existence makes the server not ready, if any.")
+	flag.DurationVar(&timeout, "timeout", 10*time.Second, "Timeout of a ping.")
+	flag.StringVar(&procDir, "proc-dir", "/proc", "Directory of the processes of the Pod.")
+	flag.StringVar(&processName, "process-name", "java", "Name of the process of the server, the probe stops once it exited.")
+	opts := zap.Options{}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	logger := zap.New(zap.UseFlagOptions(&opts))
+
+	httpServer := &http.Server{
+		Addr:              listenAddress,
+		Handler:           serverlistping.ProbeHandler(targetAddress, timeout, drainLockFile, logger),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	// The Pods are never restarted, they only complete once all
+	// their containers exited, so the probe ends with the server.
+	// Its process is only visible when the Pod shares its process
+	// namespace
+	go func() {
+		if sidecar.WaitForProcessEnd(ctx, procDir, processName, processPollInterval) {
+			logger.Info("Server stopped")
+			stop()
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Starting probe", "address", listenAddress, "target", targetAddress)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error(err, "unable to serve")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Lists the directories directly under the data directory which
+// contain a Minecraft world.
+func FindWorlds(dataDir string) ([]string, error) {
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var worlds []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(dataDir, entry.Name(), "level.dat")); err == nil {
+			worlds = append(worlds, entry.Name())
+		}
+	}
+
+	sort.Strings(worlds)
+	return worlds, nil
+}
+
+// Writes a gzipped tarball of the given worlds, with each world
+// directory at the root of the archive. This is the layout expected
+// from the world of a MinecraftServer.
+func ArchiveWorlds(dataDir string, worlds []string, w io.Writer) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, world := range worlds {
+		err := filepath.WalkDir(filepath.Join(dataDir, world), func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// The lock only matters to the running server
+			if entry.Name() == "session.lock" {
+				return nil
+			}
+
+			return addToArchive(tarWriter, dataDir, path, entry)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+func addToArchive(tarWriter *tar.Writer, dataDir string, path string, entry fs.DirEntry) error {
+	info, err := entry.Info()
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() && !info.Mode().IsRegular() {
+		return nil
+	}
+
+	name, err := filepath.Rel(dataDir, path)
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(name)
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.CopyN(tarWriter, file, header.Size)
+	return err
+}
@@ -0,0 +1,144 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const backupKeySuffix = ".tar.gz"
+const backupKeyTimeFormat = "20060102T150405Z"
+
+// Backuper takes backups of the worlds of a Minecraft server and
+// uploads them to an S3-compatible bucket.
+type Backuper struct {
+	S3     *S3Client
+	Logger logr.Logger
+
+	// Directory of the server containing the worlds.
+	DataDir string
+
+	// Directory where the archive is written before being uploaded.
+	TmpDir string
+
+	// Prefix of the object keys, the backups are stored as
+	// <prefix>/<time>.tar.gz.
+	Prefix string
+
+	// Number of backups to keep, 0 keeps all of them.
+	Retention int
+
+	// Function opening an RCON connection to the server.
+	DialRcon func() (*RconClient, error)
+}
+
+// Takes a backup of the worlds. When the server is running, its
+// automatic saving is paused and the worlds are flushed to the disk
+// first.
+func (b *Backuper) Backup(ctx context.Context, serverRunning bool) error {
+	if serverRunning {
+		rcon, err := b.DialRcon()
+		if err != nil {
+			return fmt.Errorf("failed to connect to the server: %v", err)
+		}
+		defer rcon.Close()
+
+		if _, err := rcon.Execute("save-off"); err != nil {
+			return fmt.Errorf("failed to disable automatic saving: %v", err)
+		}
+		defer func() {
+			if _, err := rcon.Execute("save-on"); err != nil {
+				b.Logger.Error(err, "Failed to enable automatic saving again")
+			}
+		}()
+
+		if _, err := rcon.Execute("save-all flush"); err != nil {
+			return fmt.Errorf("failed to save the worlds: %v", err)
+		}
+	}
+
+	worlds, err := FindWorlds(b.DataDir)
+	if err != nil {
+		return err
+	}
+	if len(worlds) == 0 {
+		return errors.New("no world to backup")
+	}
+
+	archive, err := os.CreateTemp(b.TmpDir, "backup-*"+backupKeySuffix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	hash := sha256.New()
+	if err := ArchiveWorlds(b.DataDir, worlds, io.MultiWriter(archive, hash)); err != nil {
+		return fmt.Errorf("failed to archive the worlds: %v", err)
+	}
+
+	size, err := archive.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	key := path.Join(b.Prefix, time.Now().UTC().Format(backupKeyTimeFormat)+backupKeySuffix)
+	err = b.S3.PutObject(ctx, key, archive, size, hex.EncodeToString(hash.Sum(nil)), "application/gzip")
+	if err != nil {
+		return fmt.Errorf("failed to upload the backup: %v", err)
+	}
+	b.Logger.Info("Uploaded backup", "key", key, "worlds", worlds, "size", size)
+
+	return b.prune(ctx)
+}
+
+// Deletes the oldest backups exceeding the retention.
+func (b *Backuper) prune(ctx context.Context) error {
+	if b.Retention <= 0 {
+		return nil
+	}
+
+	objects, err := b.S3.ListObjects(ctx, b.Prefix+"/")
+	if err != nil {
+		return fmt.Errorf("failed to list the backups: %v", err)
+	}
+
+	// Only consider the backups of this server, the keys are sortable
+	// by their creation time
+	var keys []string
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, b.Prefix+"/")
+		if !strings.Contains(name, "/") && strings.HasSuffix(name, backupKeySuffix) {
+			keys = append(keys, object.Key)
+		}
+	}
+	sort.Strings(keys)
+
+	for len(keys) > b.Retention {
+		if err := b.S3.DeleteObject(ctx, keys[0]); err != nil {
+			return fmt.Errorf("failed to delete the backup %s: %v", keys[0], err)
+		}
+		b.Logger.Info("Deleted old backup", "key", keys[0])
+		keys = keys[1:]
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Fake RCON server recording the commands it receives.
+type rconStandIn struct {
+	listener net.Listener
+	password string
+	mutex    sync.Mutex
+	commands []string
+}
+
+func newRconStandIn(password string) *rconStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+
+	standIn := &rconStandIn{listener: listener, password: password}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go standIn.serve(conn)
+		}
+	}()
+	return standIn
+}
+
+func (s *rconStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		var size, requestId, packetType int32
+		if binary.Read(conn, binary.LittleEndian, &size) != nil {
+			return
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return
+		}
+		reader := bytes.NewReader(payload)
+		binary.Read(reader, binary.LittleEndian, &requestId)
+		binary.Read(reader, binary.LittleEndian, &packetType)
+		body := string(payload[8 : size-2])
+
+		if packetType == rconPacketTypeAuth && body != s.password {
+			requestId = -1
+		} else if packetType == rconPacketTypeCommand {
+			s.mutex.Lock()
+			s.commands = append(s.commands, body)
+			s.mutex.Unlock()
+		}
+
+		response := bytes.Buffer{}
+		binary.Write(&response, binary.LittleEndian, int32(10))
+		binary.Write(&response, binary.LittleEndian, requestId)
+		binary.Write(&response, binary.LittleEndian, rconPacketTypeCommand)
+		response.Write([]byte{0, 0})
+		conn.Write(response.Bytes())
+	}
+}
+
+func (s *rconStandIn) Commands() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.commands...)
+}
+
+func listArchive(archive []byte) []string {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(archive))
+	Expect(err).NotTo(HaveOccurred())
+	tarReader := tar.NewReader(gzipReader)
+
+	var names []string
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return names
+		}
+		Expect(err).NotTo(HaveOccurred())
+		names = append(names, header.Name)
+	}
+}
+
+var _ = Describe("Backuper", func() {
+	var dataDir string
+	var rcon *rconStandIn
+	var s3 *S3Client
+	var cleanupS3 func()
+	var backuper *Backuper
+
+	BeforeEach(func() {
+		dataDir = GinkgoT().TempDir()
+		for _, file := range []string{"world/level.dat", "world/session.lock", "world/region/r.0.0.mca", "world_nether/level.dat", "plugins/plugin.jar"} {
+			Expect(os.MkdirAll(filepath.Join(dataDir, filepath.Dir(file)), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dataDir, file), []byte(file), 0o644)).To(Succeed())
+		}
+
+		rcon = newRconStandIn("secret")
+		s3, cleanupS3 = newTestS3Client()
+
+		backuper = &Backuper{
+			S3:        s3,
+			Logger:    logr.Discard(),
+			DataDir:   dataDir,
+			TmpDir:    GinkgoT().TempDir(),
+			Prefix:    "default/my-server",
+			Retention: 2,
+			DialRcon: func() (*RconClient, error) {
+				return DialRcon(rcon.listener.Addr().String(), "secret", time.Second)
+			},
+		}
+	})
+
+	AfterEach(func() {
+		rcon.listener.Close()
+		cleanupS3()
+	})
+
+	It("flushes and uploads the worlds of a running server", func() {
+		Expect(backuper.Backup(context.Background(), true)).To(Succeed())
+
+		Expect(rcon.Commands()).To(Equal([]string{"save-off", "save-all flush", "save-on"}))
+
+		objects, err := s3.ListObjects(context.Background(), "default/my-server/")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects).To(HaveLen(1))
+		Expect(objects[0].Key).To(HaveSuffix(".tar.gz"))
+
+		archive, err := s3.GetObject(context.Background(), objects[0].Key)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(listArchive(archive)).To(ConsistOf(
+			"world/", "world/level.dat", "world/region/", "world/region/r.0.0.mca",
+			"world_nether/", "world_nether/level.dat",
+		))
+	})
+
+	It("does not talk to a stopped server", func() {
+		Expect(backuper.Backup(context.Background(), false)).To(Succeed())
+		Expect(rcon.Commands()).To(BeEmpty())
+	})
+
+	It("keeps only the most recent backups", func() {
+		ctx := context.Background()
+		for _, key := range []string{"20230101T000000Z.tar.gz", "20230102T000000Z.tar.gz", "20230103T000000Z.tar.gz"} {
+			Expect(s3.PutObject(ctx, "default/my-server/"+key, strings.NewReader(""), 0, s3EmptyPayloadHash, "application/gzip")).To(Succeed())
+		}
+
+		Expect(backuper.Backup(ctx, false)).To(Succeed())
+
+		objects, err := s3.ListObjects(ctx, "default/my-server/")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(objects).To(HaveLen(2))
+		Expect(objects[0].Key).To(Equal("default/my-server/20230103T000000Z.tar.gz"))
+	})
+
+	It("fails when the RCON password is wrong", func() {
+		backuper.DialRcon = func() (*RconClient, error) {
+			return DialRcon(rcon.listener.Addr().String(), "wrong", time.Second)
+		}
+
+		Expect(backuper.Backup(context.Background(), true)).To(MatchError(ContainSubstring("authentication failed")))
+	})
+})
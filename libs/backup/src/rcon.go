@@ -0,0 +1,134 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package backup
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	rconPacketTypeCommand  int32 = 2
+	rconPacketTypeAuth     int32 = 3
+	rconMaxPacketBodyBytes       = 4096
+)
+
+// RconClient sends commands to a Minecraft server using the RCON
+// protocol.
+type RconClient struct {
+	conn      net.Conn
+	requestId int32
+}
+
+// Connects and authenticates to the RCON server listening at the
+// given address.
+func DialRcon(address string, password string, timeout time.Duration) (*RconClient, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &RconClient{conn: conn}
+	if err := client.authenticate(password); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (c *RconClient) authenticate(password string) error {
+	requestId, err := c.send(rconPacketTypeAuth, password)
+	if err != nil {
+		return err
+	}
+
+	// Some servers send an empty command response before the
+	// authentication response
+	for {
+		responseId, responseType, _, err := c.receive()
+		if err != nil {
+			return err
+		}
+
+		if responseType != rconPacketTypeCommand {
+			continue
+		}
+		if responseId == -1 {
+			return errors.New("rcon authentication failed")
+		}
+		if responseId == requestId {
+			return nil
+		}
+	}
+}
+
+// Executes a command and returns its output.
+func (c *RconClient) Execute(command string) (string, error) {
+	requestId, err := c.send(rconPacketTypeCommand, command)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		responseId, _, body, err := c.receive()
+		if err != nil {
+			return "", err
+		}
+		if responseId == requestId {
+			return body, nil
+		}
+	}
+}
+
+func (c *RconClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *RconClient) send(packetType int32, body string) (int32, error) {
+	if len(body) > rconMaxPacketBodyBytes {
+		return 0, fmt.Errorf("rcon packet body is too large: %d bytes", len(body))
+	}
+
+	c.requestId += 1
+
+	packet := bytes.Buffer{}
+	binary.Write(&packet, binary.LittleEndian, int32(len(body)+10))
+	binary.Write(&packet, binary.LittleEndian, c.requestId)
+	binary.Write(&packet, binary.LittleEndian, packetType)
+	packet.WriteString(body)
+	packet.Write([]byte{0, 0})
+
+	_, err := c.conn.Write(packet.Bytes())
+	return c.requestId, err
+}
+
+func (c *RconClient) receive() (int32, int32, string, error) {
+	var size, requestId, packetType int32
+
+	if err := binary.Read(c.conn, binary.LittleEndian, &size); err != nil {
+		return 0, 0, "", err
+	}
+	if size < 10 || size > rconMaxPacketBodyBytes+10 {
+		return 0, 0, "", fmt.Errorf("invalid rcon packet size: %d", size)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.conn, payload); err != nil {
+		return 0, 0, "", err
+	}
+
+	reader := bytes.NewReader(payload)
+	binary.Read(reader, binary.LittleEndian, &requestId)
+	binary.Read(reader, binary.LittleEndian, &packetType)
+
+	return requestId, packetType, string(payload[8 : size-2]), nil
+}
@@ -0,0 +1,280 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package backup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Client is a minimal client for S3-compatible object storages,
+// like AWS S3 or MinIO. Requests are path-style and signed using
+// AWS Signature Version 4.
+type S3Client struct {
+	// Base URL of the storage, e.g. https://s3.eu-west-3.amazonaws.com
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyId     string
+	SecretAccessKey string
+	HTTPClient      *http.Client
+}
+
+type S3Object struct {
+	Key          string
+	LastModified time.Time
+	Size         int64
+}
+
+type s3ListBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+type s3ErrorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// Uploads an object. The hash is the hex-encoded SHA-256 of the body.
+func (c *S3Client) PutObject(ctx context.Context, key string, body io.Reader, size int64, payloadHash string, contentType string) error {
+	req, err := c.newRequest(ctx, http.MethodPut, key, nil, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	_, err = c.do(req, payloadHash)
+	return err
+}
+
+// Downloads an object.
+func (c *S3Client) GetObject(ctx context.Context, key string) ([]byte, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.do(req, s3EmptyPayloadHash)
+}
+
+// Lists all the objects whose key starts with the given prefix.
+func (c *S3Client) ListObjects(ctx context.Context, prefix string) ([]S3Object, error) {
+	var objects []S3Object
+	continuationToken := ""
+
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		req, err := c.newRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := c.do(req, s3EmptyPayloadHash)
+		if err != nil {
+			return nil, err
+		}
+
+		result := s3ListBucketResult{}
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse object list: %v", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, S3Object{
+				Key:          content.Key,
+				LastModified: content.LastModified,
+				Size:         content.Size,
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+func (c *S3Client) DeleteObject(ctx context.Context, key string) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(req, s3EmptyPayloadHash)
+	return err
+}
+
+func (c *S3Client) newRequest(ctx context.Context, method string, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %v", err)
+	}
+
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/" + c.Bucket
+	if key != "" {
+		endpoint.Path += "/" + key
+	}
+	endpoint.RawPath = s3EncodeURI(endpoint.Path, false)
+	if query != nil {
+		endpoint.RawQuery = s3CanonicalQuery(query)
+	}
+
+	return http.NewRequestWithContext(ctx, method, endpoint.String(), body)
+}
+
+func (c *S3Client) do(req *http.Request, payloadHash string) ([]byte, error) {
+	c.sign(req, payloadHash, time.Now())
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		errorResponse := s3ErrorResponse{}
+		if xml.Unmarshal(body, &errorResponse) == nil && errorResponse.Code != "" {
+			return nil, fmt.Errorf("%s %s failed with status %d: %s: %s", req.Method, req.URL.Path, res.StatusCode, errorResponse.Code, errorResponse.Message)
+		}
+		return nil, fmt.Errorf("%s %s failed with status %d", req.Method, req.URL.Path, res.StatusCode)
+	}
+
+	return body, nil
+}
+
+// Adds the AWS Signature Version 4 headers to the request. Every
+// header already set on the request is signed.
+func (c *S3Client) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, c.Region)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	headerNames := make([]string, 0, len(headers))
+	for name := range headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+
+	canonicalHeaders := strings.Builder{}
+	for _, name := range headerNames {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EncodeURI(req.URL.Path, false),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSha256([]byte("AWS4"+c.SecretAccessKey), date)
+	signingKey = hmacSha256(signingKey, c.Region)
+	signingKey = hmacSha256(signingKey, "s3")
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.AccessKeyId, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3EncodeURI(key, true)+"="+s3EncodeURI(value, true))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// Encodes a string the way AWS expects it in canonical requests:
+// every byte except the unreserved characters is percent-encoded.
+func s3EncodeURI(value string, encodeSlash bool) string {
+	encoded := strings.Builder{}
+
+	for i := 0; i < len(value); i++ {
+		ch := value[i]
+		if (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' || (ch == '/' && !encodeSlash) {
+			encoded.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", ch)
+		}
+	}
+
+	return encoded.String()
+}
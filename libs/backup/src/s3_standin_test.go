@@ -0,0 +1,144 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Minimal in-memory stand-in of a MinIO server, implementing the
+// subset of the S3 API used by the backups. Setting the
+// SHULKER_TEST_S3_ENDPOINT, SHULKER_TEST_S3_ACCESS_KEY_ID,
+// SHULKER_TEST_S3_SECRET_ACCESS_KEY and SHULKER_TEST_S3_BUCKET
+// environment variables runs the tests against a real server instead.
+type s3StandIn struct {
+	server  *httptest.Server
+	mutex   sync.Mutex
+	bucket  string
+	objects map[string][]byte
+}
+
+func newS3StandIn(bucket string) *s3StandIn {
+	standIn := &s3StandIn{
+		bucket:  bucket,
+		objects: make(map[string][]byte),
+	}
+	standIn.server = httptest.NewServer(http.HandlerFunc(standIn.handle))
+	return standIn
+}
+
+func newTestS3Client() (*S3Client, func()) {
+	if endpoint := os.Getenv("SHULKER_TEST_S3_ENDPOINT"); endpoint != "" {
+		return &S3Client{
+			Endpoint:        endpoint,
+			Region:          "us-east-1",
+			Bucket:          os.Getenv("SHULKER_TEST_S3_BUCKET"),
+			AccessKeyId:     os.Getenv("SHULKER_TEST_S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("SHULKER_TEST_S3_SECRET_ACCESS_KEY"),
+		}, func() {}
+	}
+
+	standIn := newS3StandIn("backups")
+	return &S3Client{
+		Endpoint:        standIn.server.URL,
+		Region:          "us-east-1",
+		Bucket:          "backups",
+		AccessKeyId:     "minioadmin",
+		SecretAccessKey: "minioadmin",
+	}, standIn.server.Close
+}
+
+func (s *s3StandIn) handle(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minioadmin/") {
+		s.writeError(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.bucket {
+		s.writeError(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPut && key != "":
+		body, _ := io.ReadAll(r.Body)
+		hash := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(hash[:]) {
+			s.writeError(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch")
+			return
+		}
+		s.objects[key] = body
+
+	case r.Method == http.MethodGet && key == "" && r.URL.Query().Get("list-type") == "2":
+		s.writeList(w, r.URL.Query().Get("prefix"))
+
+	case r.Method == http.MethodGet && key != "":
+		object, ok := s.objects[key]
+		if !ok {
+			s.writeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Write(object)
+
+	case r.Method == http.MethodDelete && key != "":
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s.writeError(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (s *s3StandIn) writeList(w http.ResponseWriter, prefix string) {
+	type content struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int       `xml:"Size"`
+	}
+	result := struct {
+		XMLName     xml.Name  `xml:"ListBucketResult"`
+		IsTruncated bool      `xml:"IsTruncated"`
+		Contents    []content `xml:"Contents"`
+	}{}
+
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		result.Contents = append(result.Contents, content{Key: key, LastModified: time.Now().UTC(), Size: len(s.objects[key])})
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func (s *s3StandIn) writeError(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{Code: code, Message: code})
+}
@@ -0,0 +1,44 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package backup
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schedule", func() {
+	// Wednesday
+	now := time.Date(2023, time.February, 15, 10, 30, 0, 0, time.UTC)
+
+	DescribeTable("computes the next run",
+		func(expression string, expected time.Time) {
+			schedule, err := ParseSchedule(expression)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schedule.Next(now)).To(Equal(expected))
+		},
+		Entry("every minute", "* * * * *", time.Date(2023, time.February, 15, 10, 31, 0, 0, time.UTC)),
+		Entry("every 6 hours", "0 */6 * * *", time.Date(2023, time.February, 15, 12, 0, 0, 0, time.UTC)),
+		Entry("daily macro", "@daily", time.Date(2023, time.February, 16, 0, 0, 0, 0, time.UTC)),
+		Entry("list of hours", "15 4,11 * * *", time.Date(2023, time.February, 15, 11, 15, 0, 0, time.UTC)),
+		Entry("sunday as 7", "0 3 * * 7", time.Date(2023, time.February, 19, 3, 0, 0, 0, time.UTC)),
+		Entry("day of month or week", "0 0 1 * 5", time.Date(2023, time.February, 17, 0, 0, 0, 0, time.UTC)),
+		Entry("next year", "0 0 1 1 *", time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)),
+	)
+
+	DescribeTable("rejects invalid expressions",
+		func(expression string) {
+			_, err := ParseSchedule(expression)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too few fields", "* * * *"),
+		Entry("out of range", "60 * * * *"),
+		Entry("invalid step", "*/0 * * * *"),
+		Entry("reversed range", "0 10-2 * * *"),
+	)
+})
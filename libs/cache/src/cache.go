@@ -0,0 +1,257 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	initfs "github.com/iamblueslime/shulker/libs/initfs/src"
+)
+
+const partialFileSuffix = ".part"
+
+// Server downloads the resources asked by the init containers once,
+// verifies them and serves them from its directory afterwards.
+//
+// Resources are asked with GET /artifacts/<file name>?url=<url>, with
+// the optional sha256, sha512 and verifyPublishedChecksums query
+// parameters having the same meaning as in the initfs manifest.
+type Server struct {
+	// Directory the resources are stored in.
+	Dir string
+
+	Downloader *initfs.Downloader
+	Logger     logr.Logger
+
+	// Maximum size in bytes of the stored resources, the least
+	// recently used ones are removed when it is exceeded. No limit
+	// when zero.
+	MaxSize int64
+
+	mutex     sync.Mutex
+	downloads map[string]*pendingDownload
+}
+
+// Download shared by all the requests of the same resource.
+type pendingDownload struct {
+	done chan struct{}
+	err  error
+}
+
+// Handler returns the HTTP handler of the cache.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/artifacts/", s.serveArtifact)
+	return mux
+}
+
+func (s *Server) serveArtifact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fileName := strings.TrimPrefix(r.URL.Path, "/artifacts/")
+	if fileName == "" || strings.Contains(fileName, "/") {
+		http.Error(w, "invalid file name", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	resource := &initfs.ManifestResource{
+		Name:                     fileName,
+		Url:                      query.Get("url"),
+		Sha256:                   query.Get("sha256"),
+		Sha512:                   query.Get("sha512"),
+		VerifyPublishedChecksums: query.Get("verifyPublishedChecksums") == "true",
+	}
+	if upstreamUrl, err := url.Parse(resource.Url); err != nil || (upstreamUrl.Scheme != "http" && upstreamUrl.Scheme != "https") {
+		http.Error(w, "url must be an absolute http or https URL", http.StatusBadRequest)
+		return
+	}
+
+	path, err := s.get(r.Context(), resource)
+	if err != nil {
+		s.Logger.Info("Failed to get resource", "url", resource.Url, "error", err.Error())
+		http.Error(w, err.Error(), getErrorStatusCode(err))
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The modification time tells which resources were used recently
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		s.Logger.Info("Failed to update modification time of resource", "path", path, "error", err.Error())
+	}
+
+	http.ServeContent(w, r, fileName, stat.ModTime(), file)
+}
+
+// Returns the path of the stored resource, downloading it first if
+// needed. Concurrent requests of the same resource share the same
+// download.
+func (s *Server) get(ctx context.Context, resource *initfs.ManifestResource) (string, error) {
+	key := getCacheKey(resource)
+	path := filepath.Join(s.Dir, key)
+
+	s.mutex.Lock()
+	if _, err := os.Stat(path); err == nil {
+		s.mutex.Unlock()
+		return path, nil
+	}
+
+	download, ok := s.downloads[key]
+	if !ok {
+		if s.downloads == nil {
+			s.downloads = map[string]*pendingDownload{}
+		}
+		download = &pendingDownload{done: make(chan struct{})}
+		s.downloads[key] = download
+
+		// The download goes on even if the request which started it
+		// is cancelled, other Pods are likely to ask for it
+		go s.download(resource, key, download)
+	}
+	s.mutex.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-download.done:
+	}
+
+	if download.err != nil {
+		return "", download.err
+	}
+	return path, nil
+}
+
+func (s *Server) download(resource *initfs.ManifestResource, key string, download *pendingDownload) {
+	defer func() {
+		s.mutex.Lock()
+		delete(s.downloads, key)
+		s.mutex.Unlock()
+		close(download.done)
+	}()
+
+	s.Logger.Info("Downloading resource", "url", resource.Url)
+	partialPath := filepath.Join(s.Dir, key+partialFileSuffix)
+	result, err := s.Downloader.Download(context.Background(), resource, partialPath)
+	if err != nil {
+		os.Remove(partialPath)
+		download.err = fmt.Errorf("failed to download %s after %d attempts: %w", resource.Url, result.Attempts, err)
+		return
+	}
+
+	if err := os.Rename(partialPath, filepath.Join(s.Dir, key)); err != nil {
+		os.Remove(partialPath)
+		download.err = err
+		return
+	}
+
+	if err := s.prune(key); err != nil {
+		s.Logger.Error(err, "failed to prune cache")
+	}
+}
+
+// Removes the least recently used resources until their total size
+// fits in MaxSize, except the one which was just downloaded.
+func (s *Server) prune(keep string) error {
+	if s.MaxSize <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return err
+	}
+
+	var files []os.FileInfo
+	var totalSize int64
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), partialFileSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, info)
+		totalSize += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, file := range files {
+		if totalSize <= s.MaxSize {
+			break
+		}
+		if file.Name() == keep {
+			continue
+		}
+
+		s.Logger.Info("Removing least recently used resource", "key", file.Name(), "size", file.Size())
+		if err := os.Remove(filepath.Join(s.Dir, file.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		totalSize -= file.Size()
+	}
+
+	return nil
+}
+
+// Resources are stored by what identifies their content, two
+// requests with different checksums must not share a file.
+func getCacheKey(resource *initfs.ManifestResource) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s\n%t", resource.Url, resource.Sha256, resource.Sha512, resource.VerifyPublishedChecksums)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// The init containers do not retry on client errors, which are kept
+// for the errors which would happen again.
+func getErrorStatusCode(err error) int {
+	var checksumError *initfs.ChecksumMismatchError
+	if errors.As(err, &checksumError) {
+		return http.StatusConflict
+	} else if initfs.IsPermanentDownloadError(err) {
+		return http.StatusNotFound
+	} else if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadGateway
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	initfs "github.com/iamblueslime/shulker/libs/initfs/src"
+)
+
+var _ = Describe("Server", func() {
+	var mutex sync.Mutex
+	var files map[string][]byte
+	var requests map[string]int
+	var upstream *httptest.Server
+	var cache *Server
+	var server *httptest.Server
+
+	getArtifact := func(fileName string, query url.Values) (int, string) {
+		res, err := http.Get(server.URL + "/artifacts/" + fileName + "?" + query.Encode())
+		Expect(err).NotTo(HaveOccurred())
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return res.StatusCode, string(body)
+	}
+
+	getRequests := func(path string) int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return requests[path]
+	}
+
+	BeforeEach(func() {
+		files = map[string][]byte{}
+		requests = map[string]int{}
+		upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			requests[r.URL.Path]++
+			content, ok := files[r.URL.Path]
+			mutex.Unlock()
+
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(content)
+		}))
+
+		cache = &Server{
+			Dir: GinkgoT().TempDir(),
+			Downloader: &initfs.Downloader{
+				HTTPClient: &http.Client{Timeout: 5 * time.Second},
+				Logger:     logr.Discard(),
+				Retries:    1,
+				RetryDelay: time.Millisecond,
+			},
+			Logger: logr.Discard(),
+		}
+		server = httptest.NewServer(cache.Handler())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		upstream.Close()
+	})
+
+	It("downloads a resource once", func() {
+		checksum := sha256.Sum256([]byte("plugin"))
+		files["/plugin.jar"] = []byte("plugin")
+		query := url.Values{"url": {upstream.URL + "/plugin.jar"}, "sha256": {hex.EncodeToString(checksum[:])}}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				status, body := getArtifact("plugin.jar", query)
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(body).To(Equal("plugin"))
+			}()
+		}
+		wg.Wait()
+
+		status, body := getArtifact("plugin.jar", query)
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("plugin"))
+		Expect(getRequests("/plugin.jar")).To(Equal(1))
+	})
+
+	It("does not serve a resource with different checksums", func() {
+		files["/plugin.jar"] = []byte("plugin")
+		status, _ := getArtifact("plugin.jar", url.Values{"url": {upstream.URL + "/plugin.jar"}})
+		Expect(status).To(Equal(http.StatusOK))
+
+		status, body := getArtifact("plugin.jar", url.Values{"url": {upstream.URL + "/plugin.jar"}, "sha256": {"0000"}})
+		Expect(status).To(Equal(http.StatusConflict))
+		Expect(body).To(ContainSubstring("checksum mismatch"))
+		Expect(getRequests("/plugin.jar")).To(Equal(2))
+	})
+
+	It("answers missing resources with a client error", func() {
+		status, _ := getArtifact("missing.jar", url.Values{"url": {upstream.URL + "/missing.jar"}})
+		Expect(status).To(Equal(http.StatusNotFound))
+		Expect(getRequests("/missing.jar")).To(Equal(1))
+
+		entries, err := os.ReadDir(cache.Dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("rejects invalid requests", func() {
+		status, _ := getArtifact("plugin.jar", url.Values{"url": {"file:///etc/passwd"}})
+		Expect(status).To(Equal(http.StatusBadRequest))
+
+		status, _ = getArtifact("", url.Values{"url": {upstream.URL + "/plugin.jar"}})
+		Expect(status).To(Equal(http.StatusBadRequest))
+	})
+
+	It("removes the least recently used resources", func() {
+		cache.MaxSize = 10
+		files["/a.jar"] = []byte("aaaaaa")
+		files["/b.jar"] = []byte("bbbbbb")
+
+		status, _ := getArtifact("a.jar", url.Values{"url": {upstream.URL + "/a.jar"}})
+		Expect(status).To(Equal(http.StatusOK))
+		status, _ = getArtifact("b.jar", url.Values{"url": {upstream.URL + "/b.jar"}})
+		Expect(status).To(Equal(http.StatusOK))
+
+		entries, err := os.ReadDir(cache.Dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+
+		status, body := getArtifact("b.jar", url.Values{"url": {upstream.URL + "/b.jar"}})
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("bbbbbb"))
+		Expect(getRequests("/b.jar")).To(Equal(1))
+
+		status, _ = getArtifact("a.jar", url.Values{"url": {upstream.URL + "/a.jar"}})
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(getRequests("/a.jar")).To(Equal(2))
+	})
+})
@@ -0,0 +1,46 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+// Resolves the maximum number of replicas that can be created above
+// the desired replicas and the maximum number of replicas that can be
+// unavailable during a rolling update.
+func resolveRollingUpdateBounds(strategy *shulkermciov1alpha1.DeploymentStrategy, replicas int32) (int, int, error) {
+	maxSurge := &shulkermciov1alpha1.DefaultRollingUpdateMaxSurge
+	maxUnavailable := &shulkermciov1alpha1.DefaultRollingUpdateMaxUnavailable
+
+	if strategy.RollingUpdate != nil {
+		if strategy.RollingUpdate.MaxSurge != nil {
+			maxSurge = strategy.RollingUpdate.MaxSurge
+		}
+		if strategy.RollingUpdate.MaxUnavailable != nil {
+			maxUnavailable = strategy.RollingUpdate.MaxUnavailable
+		}
+	}
+
+	surge, err := intstr.GetScaledValueFromIntOrPercent(maxSurge, int(replicas), true)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	unavailable, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, int(replicas), false)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Like Deployments, a rollout must always be able to progress
+	if surge == 0 && unavailable == 0 {
+		unavailable = 1
+	}
+
+	return surge, unavailable, nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("Deployment strategy", func() {
+	newStrategy := func(maxSurge *intstr.IntOrString, maxUnavailable *intstr.IntOrString) *shulkermciov1alpha1.DeploymentStrategy {
+		return &shulkermciov1alpha1.DeploymentStrategy{
+			Type: shulkermciov1alpha1.DeploymentStrategyRollingUpdate,
+			RollingUpdate: &shulkermciov1alpha1.RollingUpdateDeploymentStrategy{
+				MaxSurge:       maxSurge,
+				MaxUnavailable: maxUnavailable,
+			},
+		}
+	}
+
+	intOrPercent := func(value string) *intstr.IntOrString {
+		parsed := intstr.Parse(value)
+		return &parsed
+	}
+
+	DescribeTable("resolveRollingUpdateBounds",
+		func(strategy *shulkermciov1alpha1.DeploymentStrategy, replicas int32, expectedSurge int, expectedUnavailable int) {
+			surge, unavailable, err := resolveRollingUpdateBounds(strategy, replicas)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(surge).To(Equal(expectedSurge))
+			Expect(unavailable).To(Equal(expectedUnavailable))
+		},
+		Entry("defaults, rounding the surge up and the unavailability down", &shulkermciov1alpha1.DeploymentStrategy{}, int32(10), 3, 2),
+		Entry("defaults of a single replica", &shulkermciov1alpha1.DeploymentStrategy{}, int32(1), 1, 0),
+		Entry("defaults without replicas", &shulkermciov1alpha1.DeploymentStrategy{}, int32(0), 0, 1),
+		Entry("percentages", newStrategy(intOrPercent("50%"), intOrPercent("50%")), int32(3), 2, 1),
+		Entry("percentages below a replica", newStrategy(intOrPercent("10%"), intOrPercent("10%")), int32(5), 1, 0),
+		Entry("absolute numbers", newStrategy(intOrPercent("2"), intOrPercent("1")), int32(10), 2, 1),
+		Entry("only the surge set", newStrategy(intOrPercent("1"), nil), int32(8), 1, 2),
+		Entry("no surge", newStrategy(intOrPercent("0"), intOrPercent("25%")), int32(10), 0, 2),
+		Entry("no unavailability", newStrategy(intOrPercent("25%"), intOrPercent("0")), int32(10), 3, 0),
+		Entry("neither surge nor unavailability", newStrategy(intOrPercent("0"), intOrPercent("0")), int32(10), 0, 1),
+		Entry("percentages rounding to neither surge nor unavailability", newStrategy(intOrPercent("0%"), intOrPercent("10%")), int32(5), 0, 1),
+	)
+
+	It("fails on a malformed percentage", func() {
+		_, _, err := resolveRollingUpdateBounds(newStrategy(intOrPercent("many%"), nil), 10)
+
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,31 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Records an Event telling what happened to a resource of the owner,
+// e.g. "Created Pod my-server".
+func recordResourceEvent(recorder record.EventRecorder, owner runtime.Object, reason string, verb string, resource client.Object) {
+	kind := reflect.Indirect(reflect.ValueOf(resource)).Type().Name()
+	recorder.Eventf(owner, corev1.EventTypeNormal, reason, "%s %s %s", verb, kind, resource.GetName())
+}
+
+// Records a warning when the MinecraftCluster referenced by the
+// object does not exist, other errors being transient.
+func recordClusterNotFoundEvent(recorder record.EventRecorder, object runtime.Object, clusterName string, err error) {
+	if k8serrors.IsNotFound(err) {
+		recorder.Eventf(object, corev1.EventTypeWarning, "ClusterNotFound", "Referenced MinecraftCluster %s does not exist", clusterName)
+	}
+}
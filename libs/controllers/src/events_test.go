@@ -0,0 +1,129 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	common "github.com/iamblueslime/shulker/libs/resources/src"
+)
+
+var _ = Describe("Events", func() {
+	var recorder *record.FakeRecorder
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(16)
+	})
+
+	// Returns the Events recorded so far.
+	getEvents := func() []string {
+		var events []string
+		for {
+			select {
+			case event := <-recorder.Events:
+				events = append(events, event)
+			default:
+				return events
+			}
+		}
+	}
+
+	Describe("MinecraftServer controller", func() {
+		newMinecraftServer := func(clusterName string) *shulkermciov1alpha1.MinecraftServer {
+			minecraftServer := &shulkermciov1alpha1.MinecraftServer{}
+			minecraftServer.Namespace = testNamespace
+			minecraftServer.Name = "my-server"
+			minecraftServer.Spec.ClusterRef.Name = clusterName
+			minecraftServer.Spec.Version = shulkermciov1alpha1.MinecraftServerVersionSpec{
+				Channel: shulkermciov1alpha1.MinecraftServerVersionPaper,
+				Name:    "1.20.4",
+			}
+			return minecraftServer
+		}
+
+		reconcile := func(objects ...client.Object) error {
+			fakeClient, fakeScheme := newFakeClient(objects...)
+			reconciler := &MinecraftServerReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeScheme,
+				Recorder: recorder,
+				Images:   common.NewDefaultImages(),
+			}
+
+			_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: "my-server"}})
+			return err
+		}
+
+		It("warns when the resources cannot be resolved", func() {
+			minecraftServer := newMinecraftServer(testClusterName)
+			minecraftServer.Spec.Configuration.Plugins = []shulkermciov1alpha1.ResourceRef{{}}
+			Expect(reconcile(minecraftServer)).To(Succeed())
+
+			Expect(getEvents()).To(Equal([]string{"Warning ResourceResolutionFailed failed to resolve spec.config.plugins[0]: no resourceRef combination"}))
+		})
+
+		It("warns when the cluster does not exist", func() {
+			Expect(reconcile(newMinecraftServer("unknown-cluster"))).NotTo(Succeed())
+
+			Expect(getEvents()).To(Equal([]string{"Warning ClusterNotFound Referenced MinecraftCluster unknown-cluster does not exist"}))
+		})
+
+		It("tells the created resources", func() {
+			Expect(reconcile(newMinecraftServer(testClusterName))).To(Succeed())
+
+			Expect(getEvents()).To(Equal([]string{
+				"Normal SuccessfulCreate Created ConfigMap my-server-config",
+				"Normal SuccessfulCreate Created ConfigMap my-server-init",
+				"Normal SuccessfulCreate Created Pod my-server",
+			}))
+		})
+
+		It("tells when the Pod has ended", func() {
+			pod := &corev1.Pod{}
+			pod.Namespace = testNamespace
+			pod.Name = "my-server"
+			pod.Status.Phase = corev1.PodSucceeded
+			Expect(reconcile(newMinecraftServer(testClusterName), pod)).To(Succeed())
+
+			Expect(getEvents()).To(ContainElement("Normal PodEnded Pod my-server has ended, deleting MinecraftServer"))
+		})
+	})
+
+	Describe("ProxyDeployment controller", func() {
+		It("tells which proxies are drained and why", func() {
+			deployment := &shulkermciov1alpha1.ProxyDeployment{}
+			deployment.Namespace = testNamespace
+			deployment.Name = "proxy"
+
+			proxy := &shulkermciov1alpha1.Proxy{}
+			proxy.Namespace = testNamespace
+			proxy.Name = "proxy-0"
+
+			fakeClient, fakeScheme := newFakeClient(deployment, proxy)
+			reconciler := &ProxyDeploymentReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeScheme,
+				Recorder: recorder,
+			}
+
+			Expect(reconciler.drainProxies(context.Background(), deployment, []*shulkermciov1alpha1.Proxy{proxy}, "its template is outdated")).To(Succeed())
+			Expect(getEvents()).To(Equal([]string{"Normal Draining Draining Proxy proxy-0 as its template is outdated"}))
+
+			// Proxies already draining are not told about again
+			Expect(reconciler.drainProxies(context.Background(), deployment, []*shulkermciov1alpha1.Proxy{proxy}, "its template is outdated")).To(Succeed())
+			Expect(getEvents()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,241 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+const (
+	minecraftServerDeploymentNameLabel = "minecraftserverdeployment.shulkermc.io/name"
+	proxyDeploymentNameLabel           = "proxydeployment.shulkermc.io/name"
+
+	// Upper bound of the listing of the servers and proxies from
+	// the cache when Prometheus scrapes the metrics.
+	fleetCollectTimeout = 10 * time.Second
+)
+
+var fleetLabels = []string{"namespace", "cluster", "deployment"}
+
+var (
+	resourceResolutionFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "shulker_resource_resolution_failures_total",
+		Help: "Number of times the ResourceRefs of an object failed to resolve",
+	}, append([]string{"kind"}, fleetLabels...))
+
+	podRestartsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "shulker_pod_restarts_total",
+		Help: "Number of Pods of servers and proxies which completed, to be created again",
+	}, append([]string{"kind"}, fleetLabels...))
+)
+
+var (
+	minecraftServersDesc = prometheus.NewDesc(
+		"shulker_minecraftservers",
+		"Number of MinecraftServers per phase",
+		append(fleetLabels, "phase"), nil,
+	)
+	minecraftServerReadyReplicasDesc = prometheus.NewDesc(
+		"shulker_minecraftserver_ready_replicas",
+		"Number of MinecraftServers which are ready",
+		fleetLabels, nil,
+	)
+	minecraftServerUnreadyReplicasDesc = prometheus.NewDesc(
+		"shulker_minecraftserver_unready_replicas",
+		"Number of MinecraftServers which are not ready",
+		fleetLabels, nil,
+	)
+	minecraftServerOnlinePlayersDesc = prometheus.NewDesc(
+		"shulker_minecraftserver_online_players",
+		"Number of players connected to a MinecraftServer, once it answered a ping",
+		append(fleetLabels, "name"), nil,
+	)
+
+	proxiesDesc = prometheus.NewDesc(
+		"shulker_proxies",
+		"Number of Proxies per phase",
+		append(fleetLabels, "phase"), nil,
+	)
+	proxyReadyReplicasDesc = prometheus.NewDesc(
+		"shulker_proxy_ready_replicas",
+		"Number of Proxies which are ready",
+		fleetLabels, nil,
+	)
+	proxyUnreadyReplicasDesc = prometheus.NewDesc(
+		"shulker_proxy_unready_replicas",
+		"Number of Proxies which are not ready",
+		fleetLabels, nil,
+	)
+	proxyOnlinePlayersDesc = prometheus.NewDesc(
+		"shulker_proxy_online_players",
+		"Number of players connected to a Proxy, once it answered a ping",
+		append(fleetLabels, "name"), nil,
+	)
+	proxyDrainsDesc = prometheus.NewDesc(
+		"shulker_proxy_drains_in_progress",
+		"Number of Proxies draining their players before being deleted",
+		fleetLabels, nil,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(resourceResolutionFailuresTotal, podRestartsTotal)
+}
+
+// FleetCollector exposes the state of the MinecraftServers and
+// Proxies, computed from the cache of the manager when Prometheus
+// scrapes the metrics.
+type FleetCollector struct {
+	Reader client.Reader
+}
+
+// Describe implements prometheus.Collector.
+func (c *FleetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- minecraftServersDesc
+	ch <- minecraftServerReadyReplicasDesc
+	ch <- minecraftServerUnreadyReplicasDesc
+	ch <- minecraftServerOnlinePlayersDesc
+	ch <- proxiesDesc
+	ch <- proxyReadyReplicasDesc
+	ch <- proxyUnreadyReplicasDesc
+	ch <- proxyOnlinePlayersDesc
+	ch <- proxyDrainsDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *FleetCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), fleetCollectTimeout)
+	defer cancel()
+
+	c.collectMinecraftServers(ctx, ch)
+	c.collectProxies(ctx, ch)
+}
+
+func (c *FleetCollector) collectMinecraftServers(ctx context.Context, ch chan<- prometheus.Metric) {
+	list := shulkermciov1alpha1.MinecraftServerList{}
+	if err := c.Reader.List(ctx, &list); err != nil {
+		ch <- prometheus.NewInvalidMetric(minecraftServersDesc, err)
+		return
+	}
+
+	phases := newFleetCounter()
+	ready := newFleetCounter()
+	unready := newFleetCounter()
+	for i := range list.Items {
+		minecraftServer := &list.Items[i]
+		labels := getMinecraftServerMetricLabels(minecraftServer)
+
+		phases.add(append(labels, getPhase(minecraftServer.Status.Conditions, string(shulkermciov1alpha1.MinecraftServerPhaseCondition))))
+		if isMinecraftServerReady(minecraftServer) {
+			ready.add(labels)
+		} else {
+			unready.add(labels)
+		}
+
+		if minecraftServer.Status.LastPingTime != nil {
+			ch <- prometheus.MustNewConstMetric(minecraftServerOnlinePlayersDesc, prometheus.GaugeValue, float64(minecraftServer.Status.OnlinePlayers), append(labels, minecraftServer.Name)...)
+		}
+	}
+
+	phases.collect(ch, minecraftServersDesc)
+	ready.collect(ch, minecraftServerReadyReplicasDesc)
+	unready.collect(ch, minecraftServerUnreadyReplicasDesc)
+}
+
+func (c *FleetCollector) collectProxies(ctx context.Context, ch chan<- prometheus.Metric) {
+	list := shulkermciov1alpha1.ProxyList{}
+	if err := c.Reader.List(ctx, &list); err != nil {
+		ch <- prometheus.NewInvalidMetric(proxiesDesc, err)
+		return
+	}
+
+	phases := newFleetCounter()
+	ready := newFleetCounter()
+	unready := newFleetCounter()
+	drains := newFleetCounter()
+	for i := range list.Items {
+		proxy := &list.Items[i]
+		labels := getProxyMetricLabels(proxy)
+
+		phase := getPhase(proxy.Status.Conditions, string(shulkermciov1alpha1.ProxyPhaseCondition))
+		phases.add(append(labels, phase))
+		if isProxyReady(proxy) {
+			ready.add(labels)
+		} else {
+			unready.add(labels)
+		}
+		if isProxyDraining(proxy) {
+			drains.add(labels)
+		}
+
+		if proxy.Status.LastPingTime != nil {
+			ch <- prometheus.MustNewConstMetric(proxyOnlinePlayersDesc, prometheus.GaugeValue, float64(proxy.Status.OnlinePlayers), append(labels, proxy.Name)...)
+		}
+	}
+
+	phases.collect(ch, proxiesDesc)
+	ready.collect(ch, proxyReadyReplicasDesc)
+	unready.collect(ch, proxyUnreadyReplicasDesc)
+	drains.collect(ch, proxyDrainsDesc)
+}
+
+// Counts objects sharing the same label values.
+type fleetCounter struct {
+	counts map[string]float64
+	labels map[string][]string
+}
+
+func newFleetCounter() *fleetCounter {
+	return &fleetCounter{
+		counts: map[string]float64{},
+		labels: map[string][]string{},
+	}
+}
+
+func (c *fleetCounter) add(labelValues []string) {
+	// Names of Kubernetes objects and phases cannot contain a NUL
+	// byte, so it can separate them in the key
+	key := ""
+	for _, value := range labelValues {
+		key += value + "\x00"
+	}
+
+	c.counts[key] += 1
+	c.labels[key] = labelValues
+}
+
+func (c *fleetCounter) collect(ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+	for key, count := range c.counts {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, count, c.labels[key]...)
+	}
+}
+
+// Returns the reason of the Phase condition, the object being
+// pending until it is first reconciled.
+func getPhase(conditions []metav1.Condition, conditionType string) string {
+	condition := meta.FindStatusCondition(conditions, conditionType)
+	if condition == nil {
+		return string(shulkermciov1alpha1.PhasePending)
+	}
+	return condition.Reason
+}
+
+func getMinecraftServerMetricLabels(minecraftServer *shulkermciov1alpha1.MinecraftServer) []string {
+	return []string{minecraftServer.Namespace, minecraftServer.Spec.ClusterRef.Name, minecraftServer.Labels[minecraftServerDeploymentNameLabel]}
+}
+
+func getProxyMetricLabels(proxy *shulkermciov1alpha1.Proxy) []string {
+	return []string{proxy.Namespace, proxy.Spec.ClusterRef.Name, proxy.Labels[proxyDeploymentNameLabel]}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	common "github.com/iamblueslime/shulker/libs/resources/src"
+)
+
+var _ = Describe("Metrics", func() {
+	newMinecraftServer := func(name string, deployment string) *shulkermciov1alpha1.MinecraftServer {
+		minecraftServer := &shulkermciov1alpha1.MinecraftServer{}
+		minecraftServer.Namespace = testNamespace
+		minecraftServer.Name = name
+		minecraftServer.Spec.ClusterRef.Name = testClusterName
+		minecraftServer.Spec.Version = shulkermciov1alpha1.MinecraftServerVersionSpec{
+			Channel: shulkermciov1alpha1.MinecraftServerVersionPaper,
+			Name:    "1.20.4",
+		}
+		if deployment != "" {
+			minecraftServer.Labels = map[string]string{minecraftServerDeploymentNameLabel: deployment}
+		}
+		return minecraftServer
+	}
+
+	newProxy := func(name string, deployment string) *shulkermciov1alpha1.Proxy {
+		proxy := &shulkermciov1alpha1.Proxy{}
+		proxy.Namespace = testNamespace
+		proxy.Name = name
+		proxy.Spec.ClusterRef.Name = testClusterName
+		proxy.Labels = map[string]string{proxyDeploymentNameLabel: deployment}
+		return proxy
+	}
+
+	setReady := func(conditions *[]metav1.Condition, conditionType string, phaseConditionType string, ready bool) {
+		readyStatus := metav1.ConditionFalse
+		phase := shulkermciov1alpha1.PhaseStarting
+		if ready {
+			readyStatus = metav1.ConditionTrue
+			phase = shulkermciov1alpha1.PhaseReady
+		}
+		readyCondition := metav1.Condition{Type: conditionType, Status: readyStatus, Reason: "Test", LastTransitionTime: metav1.Now()}
+		phaseCondition := metav1.Condition{Type: phaseConditionType, Status: metav1.ConditionUnknown, Reason: string(phase), LastTransitionTime: metav1.Now()}
+		*conditions = append(*conditions, readyCondition, phaseCondition)
+	}
+
+	Describe("FleetCollector", func() {
+		It("counts the servers per phase and reports the players of the pinged ones", func() {
+			ready := newMinecraftServer("lobby-0", "lobby")
+			setReady(&ready.Status.Conditions, string(shulkermciov1alpha1.MinecraftServerReadyCondition), string(shulkermciov1alpha1.MinecraftServerPhaseCondition), true)
+			lastPingTime := metav1.Now()
+			ready.Status.LastPingTime = &lastPingTime
+			ready.Status.OnlinePlayers = 12
+
+			starting := newMinecraftServer("lobby-1", "lobby")
+			setReady(&starting.Status.Conditions, string(shulkermciov1alpha1.MinecraftServerReadyCondition), string(shulkermciov1alpha1.MinecraftServerPhaseCondition), false)
+
+			pending := newMinecraftServer("event", "")
+
+			fakeClient, _ := newFakeClient(ready, starting, pending)
+			collector := &FleetCollector{Reader: fakeClient}
+
+			Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP shulker_minecraftservers Number of MinecraftServers per phase
+# TYPE shulker_minecraftservers gauge
+shulker_minecraftservers{cluster="my-cluster",deployment="",namespace="default",phase="Pending"} 1
+shulker_minecraftservers{cluster="my-cluster",deployment="lobby",namespace="default",phase="Ready"} 1
+shulker_minecraftservers{cluster="my-cluster",deployment="lobby",namespace="default",phase="Starting"} 1
+# HELP shulker_minecraftserver_ready_replicas Number of MinecraftServers which are ready
+# TYPE shulker_minecraftserver_ready_replicas gauge
+shulker_minecraftserver_ready_replicas{cluster="my-cluster",deployment="lobby",namespace="default"} 1
+# HELP shulker_minecraftserver_unready_replicas Number of MinecraftServers which are not ready
+# TYPE shulker_minecraftserver_unready_replicas gauge
+shulker_minecraftserver_unready_replicas{cluster="my-cluster",deployment="",namespace="default"} 1
+shulker_minecraftserver_unready_replicas{cluster="my-cluster",deployment="lobby",namespace="default"} 1
+# HELP shulker_minecraftserver_online_players Number of players connected to a MinecraftServer, once it answered a ping
+# TYPE shulker_minecraftserver_online_players gauge
+shulker_minecraftserver_online_players{cluster="my-cluster",deployment="lobby",name="lobby-0",namespace="default"} 12
+`), "shulker_minecraftservers", "shulker_minecraftserver_ready_replicas", "shulker_minecraftserver_unready_replicas", "shulker_minecraftserver_online_players")).To(Succeed())
+		})
+
+		It("reports the players of the proxies which answered a ping and their drains", func() {
+			pinged := newProxy("proxy-0", "proxy")
+			setReady(&pinged.Status.Conditions, string(shulkermciov1alpha1.ProxyReadyCondition), string(shulkermciov1alpha1.ProxyPhaseCondition), true)
+			lastPingTime := metav1.Now()
+			pinged.Status.LastPingTime = &lastPingTime
+
+			draining := newProxy("proxy-1", "proxy")
+			setReady(&draining.Status.Conditions, string(shulkermciov1alpha1.ProxyReadyCondition), string(shulkermciov1alpha1.ProxyPhaseCondition), false)
+			draining.Annotations = map[string]string{shulkermciov1alpha1.ProxyDrainAnnotationName: "true"}
+			draining.Status.OnlinePlayers = 3
+
+			fakeClient, _ := newFakeClient(pinged, draining)
+			collector := &FleetCollector{Reader: fakeClient}
+
+			Expect(testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP shulker_proxy_online_players Number of players connected to a Proxy, once it answered a ping
+# TYPE shulker_proxy_online_players gauge
+shulker_proxy_online_players{cluster="my-cluster",deployment="proxy",name="proxy-0",namespace="default"} 0
+# HELP shulker_proxy_drains_in_progress Number of Proxies draining their players before being deleted
+# TYPE shulker_proxy_drains_in_progress gauge
+shulker_proxy_drains_in_progress{cluster="my-cluster",deployment="proxy",namespace="default"} 1
+`), "shulker_proxy_online_players", "shulker_proxy_drains_in_progress")).To(Succeed())
+		})
+	})
+
+	Describe("counters", func() {
+		reconcile := func(objects ...client.Object) client.Client {
+			fakeClient, fakeScheme := newFakeClient(objects...)
+			reconciler := &MinecraftServerReconciler{
+				Client:   fakeClient,
+				Scheme:   fakeScheme,
+				Recorder: record.NewFakeRecorder(16),
+				Images:   common.NewDefaultImages(),
+			}
+
+			_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: "my-server"}})
+			Expect(err).NotTo(HaveOccurred())
+			return fakeClient
+		}
+
+		newPod := func(phase corev1.PodPhase) *corev1.Pod {
+			pod := &corev1.Pod{}
+			pod.Namespace = testNamespace
+			pod.Name = "my-server"
+			pod.Status.Phase = phase
+			return pod
+		}
+
+		BeforeEach(func() {
+			resourceResolutionFailuresTotal.Reset()
+			podRestartsTotal.Reset()
+		})
+
+		It("counts the resolution failures", func() {
+			minecraftServer := newMinecraftServer("my-server", "")
+			minecraftServer.Spec.Configuration.Plugins = []shulkermciov1alpha1.ResourceRef{{}}
+			reconcile(minecraftServer)
+
+			Expect(testutil.CollectAndCompare(resourceResolutionFailuresTotal, strings.NewReader(`
+# HELP shulker_resource_resolution_failures_total Number of times the ResourceRefs of an object failed to resolve
+# TYPE shulker_resource_resolution_failures_total counter
+shulker_resource_resolution_failures_total{cluster="my-cluster",deployment="",kind="MinecraftServer",namespace="default"} 1
+`))).To(Succeed())
+		})
+
+		It("counts a restart when the Pod has completed", func() {
+			minecraftServer := newMinecraftServer("my-server", "")
+			minecraftServer.Spec.Persistence = &shulkermciov1alpha1.MinecraftServerPersistenceSpec{}
+			fakeClient := reconcile(minecraftServer, newPod(corev1.PodSucceeded))
+
+			Expect(testutil.CollectAndCompare(podRestartsTotal, strings.NewReader(`
+# HELP shulker_pod_restarts_total Number of Pods of servers and proxies which completed, to be created again
+# TYPE shulker_pod_restarts_total counter
+shulker_pod_restarts_total{cluster="my-cluster",deployment="",kind="MinecraftServer",namespace="default"} 1
+`))).To(Succeed())
+			Expect(k8serrors.IsNotFound(fakeClient.Get(context.Background(), client.ObjectKeyFromObject(newPod("")), &corev1.Pod{}))).To(BeTrue())
+		})
+
+		It("does not count a restart when the Pod is deleted", func() {
+			pod := newPod(corev1.PodRunning)
+			now := metav1.Now()
+			pod.DeletionTimestamp = &now
+			pod.Finalizers = []string{"shulkermc.io/test"}
+			reconcile(newMinecraftServer("my-server", ""), pod)
+
+			Expect(testutil.CollectAndCount(podRestartsTotal)).To(Equal(0))
+		})
+	})
+})
@@ -8,11 +8,13 @@ package controllers
 import (
 	"context"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -23,15 +25,25 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	common "github.com/iamblueslime/shulker/libs/resources/src"
 	resources "github.com/iamblueslime/shulker/libs/resources/src/minecraftcluster"
 )
 
 // MinecraftClusterReconciler reconciles a MinecraftCluster object
 type MinecraftClusterReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// Images of the created containers, defaults to the published
+	// ones.
+	Images *common.Images
 }
 
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups=shulkermc.io,resources=minecraftclusters,verbs=get;list;watch;create;update;patch;delete
 
 func (r *MinecraftClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -50,10 +62,11 @@ func (r *MinecraftClusterReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	resourceBuilder := resources.MinecraftClusterResourceBuilder{
 		Instance: cluster,
 		Scheme:   r.Scheme,
+		Images:   r.Images,
 	}
 	builders, dirtyBuilders := resourceBuilder.ResourceBuilders()
 
-	err = ReconcileWithResourceBuilders(r.Client, ctx, builders, dirtyBuilders)
+	err = ReconcileWithResourceBuilders(r.Client, ctx, builders, dirtyBuilders, r.Recorder, cluster)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -102,11 +115,21 @@ func (r *MinecraftClusterReconciler) findMinecraftClusterForProxyDeployment(obje
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MinecraftClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Images == nil {
+		r.Images = common.NewDefaultImages()
+	}
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("minecraftcluster-controller")
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&shulkermciov1alpha1.MinecraftCluster{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&rbacv1.Role{}).
 		Owns(&rbacv1.RoleBinding{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.Service{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
 		Watches(
 			&source.Kind{Type: &shulkermciov1alpha1.ProxyDeployment{}},
 			handler.EnqueueRequestsFromMapFunc(r.findMinecraftClusterForProxyDeployment),
@@ -7,27 +7,55 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	hashutil "k8s.io/kubernetes/pkg/util/hash"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	common "github.com/iamblueslime/shulker/libs/resources/src"
 	resources "github.com/iamblueslime/shulker/libs/resources/src/minecraftserver"
 )
 
 // MinecraftServerReconciler reconciles a MinecraftServer object
 type MinecraftServerReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// Images of the created containers, defaults to the published
+	// ones.
+	Images *common.Images
+
+	// Interval between two Server List Pings of a ready server,
+	// defaults to 30 seconds.
+	PingInterval time.Duration
+
+	// Pings the ready servers apart from the reconciliations, created
+	// and started with the controller when not set.
+	Pinger *Pinger
 }
 
-//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update
 //+kubebuilder:rbac:groups=shulkermc.io,resources=minecraftservers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=shulkermc.io,resources=minecraftservers/status,verbs=get;update;patch
 
@@ -41,6 +69,13 @@ func (r *MinecraftServerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, err
 	} else if k8serrors.IsNotFound(err) {
 		// No need to requeue if the resource no longer exists
+		r.Pinger.forget(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	// The MinecraftServer waits for its Pod to be garbage collected, which
+	// must not be created again
+	if minecraftServer.DeletionTimestamp != nil {
 		return ctrl.Result{}, nil
 	}
 
@@ -51,6 +86,7 @@ func (r *MinecraftServerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}, cluster)
 	if err != nil {
 		logger.Error(err, "Referenced MinecraftCluster does not exists")
+		recordClusterNotFoundEvent(r.Recorder, minecraftServer, minecraftServer.Spec.ClusterRef.Name, err)
 		return ctrl.Result{}, err
 	}
 
@@ -59,12 +95,8 @@ func (r *MinecraftServerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		Scheme:   r.Scheme,
 		Client:   r.Client,
 		Ctx:      ctx,
-	}
-	builders, dirtyBuilders := resourceBuilder.ResourceBuilders()
-
-	err = ReconcileWithResourceBuilders(r.Client, ctx, builders, dirtyBuilders)
-	if err != nil {
-		return ctrl.Result{}, err
+		Images:   r.Images,
+		Cluster:  cluster,
 	}
 
 	pod := corev1.Pod{}
@@ -75,8 +107,72 @@ func (r *MinecraftServerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	if err != nil && !k8serrors.IsNotFound(err) {
 		return ctrl.Result{}, err
 	}
+	podExists := err == nil
+
+	// A failed resolution holds back the Pod until it is created, an
+	// existing Pod is handled as usual
+	resolutionError, err := r.lockResources(&resourceBuilder, podExists)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if resolutionError != nil {
+		logger.Error(resolutionError, "Failed to resolve resources")
+		r.Recorder.Event(minecraftServer, corev1.EventTypeWarning, "ResourceResolutionFailed", resolutionError.Error())
+		resourceResolutionFailuresTotal.WithLabelValues(append([]string{"MinecraftServer"}, getMinecraftServerMetricLabels(minecraftServer)...)...).Inc()
+		minecraftServer.Status.SetCondition(shulkermciov1alpha1.MinecraftServerResourcesResolvedCondition, metav1.ConditionFalse, "ResolutionFailed", resolutionError.Error())
+		if !podExists {
+			logger.Info("Holding back the Pod until its resources are resolved")
+			if err := r.Status().Update(ctx, minecraftServer); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: resourceResolutionRetryInterval}, nil
+		}
+	} else {
+		builders, dirtyBuilders := resourceBuilder.ResourceBuilders()
+		err = ReconcileWithResourceBuilders(r.Client, ctx, builders, dirtyBuilders, r.Recorder, minecraftServer)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		minecraftServer.Status.SetCondition(shulkermciov1alpha1.MinecraftServerResourcesResolvedCondition, metav1.ConditionTrue, "Resolved", "All resources are resolved")
+		minecraftServer.Status.Resources, err = resourceBuilder.GetResolvedResources()
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	var lifecycle podLifecycle
+	if podExists {
+		lifecycle = getPodLifecycle(&pod, "minecraft-server")
+	} else {
+		lifecycle = getPodLifecycle(nil, "minecraft-server")
+	}
+	minecraftServer.Status.SetCondition(shulkermciov1alpha1.MinecraftServerPhaseCondition, metav1.ConditionUnknown, string(lifecycle.Phase), lifecycle.Message)
+	recordLastFailure(&minecraftServer.Status.LastFailure, lifecycle.Failure)
 
 	if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded {
+		// Servers with persistent data outlive their Pod, which is
+		// created again once the previous one is gone
+		if minecraftServer.Spec.Persistence != nil {
+			if pod.DeletionTimestamp == nil {
+				logger.Info("Pod has completed, deleting it to restart MinecraftServer")
+				if err := r.Delete(ctx, &pod); client.IgnoreNotFound(err) != nil {
+					return ctrl.Result{}, err
+				}
+				r.Recorder.Eventf(minecraftServer, corev1.EventTypeNormal, "Restarting", "Pod %s has completed, deleting it to restart the server on its persistent data", pod.Name)
+				podRestartsTotal.WithLabelValues(append([]string{"MinecraftServer"}, getMinecraftServerMetricLabels(minecraftServer)...)...).Inc()
+			}
+
+			clearPingStatus(&minecraftServer.Status)
+			return ctrl.Result{}, r.Status().Update(ctx, minecraftServer)
+		}
+
+		// Only a completed Pod counts as a restart, not a deleted one
+		if pod.DeletionTimestamp == nil {
+			r.Recorder.Eventf(minecraftServer, corev1.EventTypeNormal, "PodEnded", "Pod %s has ended, deleting MinecraftServer", pod.Name)
+			podRestartsTotal.WithLabelValues(append([]string{"MinecraftServer"}, getMinecraftServerMetricLabels(minecraftServer)...)...).Inc()
+		}
+
 		logger.Info("Pod is terminating, deleting MinecraftServer")
 		err = r.Delete(ctx, minecraftServer)
 		return ctrl.Result{}, err
@@ -85,26 +181,99 @@ func (r *MinecraftServerReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	minecraftServer.Status.ServerIP = pod.Status.PodIP
 
 	var readyCondition metav1.Condition
+	previousReadyReason := ""
+	if previousReadyCondition := meta.FindStatusCondition(minecraftServer.Status.Conditions, string(shulkermciov1alpha1.MinecraftServerReadyCondition)); previousReadyCondition != nil {
+		previousReadyReason = previousReadyCondition.Reason
+	}
 
-	if err == nil {
+	if podExists {
 		readyCondition = minecraftServer.Status.SetCondition(shulkermciov1alpha1.MinecraftServerReadyCondition, metav1.ConditionFalse, "PodNotReady", "Pod is not ready")
 
-		for _, condition := range pod.Status.Conditions {
-			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
-				readyCondition = minecraftServer.Status.SetCondition(shulkermciov1alpha1.MinecraftServerReadyCondition, metav1.ConditionTrue, "PodReady", "Pod is ready")
+		if failure := lifecycle.Failure; failure != nil {
+			readyCondition = minecraftServer.Status.SetCondition(shulkermciov1alpha1.MinecraftServerReadyCondition, metav1.ConditionFalse, failure.Reason, failure.Message)
+			if previousReadyReason != failure.Reason {
+				r.Recorder.Event(minecraftServer, corev1.EventTypeWarning, failure.Reason, failure.Message)
 			}
 		}
+
+		if lifecycle.Phase == shulkermciov1alpha1.PhaseReady {
+			readyCondition = minecraftServer.Status.SetCondition(shulkermciov1alpha1.MinecraftServerReadyCondition, metav1.ConditionTrue, "PodReady", "Pod is ready")
+		}
 	} else {
 		readyCondition = minecraftServer.Status.SetCondition(shulkermciov1alpha1.MinecraftServerReadyCondition, metav1.ConditionUnknown, "PodNotExists", "Pod does not exists")
 	}
 
-	if readyCondition.Status == metav1.ConditionTrue {
-		minecraftServer.Status.SetCondition(shulkermciov1alpha1.MinecraftServerPhaseCondition, metav1.ConditionUnknown, "Running", "MinecraftServer is running")
+	result := ctrl.Result{}
+	if readyCondition.Status == metav1.ConditionTrue && minecraftServer.Status.ServerIP != "" {
+		result.RequeueAfter = r.updatePingStatus(ctx, minecraftServer)
 	} else {
-		minecraftServer.Status.SetCondition(shulkermciov1alpha1.MinecraftServerPhaseCondition, metav1.ConditionUnknown, "Unknown", "MinecraftServer status is unknown")
+		clearPingStatus(&minecraftServer.Status)
+	}
+	if resolutionError != nil && (result.RequeueAfter == 0 || result.RequeueAfter > resourceResolutionRetryInterval) {
+		result.RequeueAfter = resourceResolutionRetryInterval
+	}
+
+	return result, r.Status().Update(ctx, minecraftServer)
+}
+
+// Locks the resources of the server in its status, unless the owning
+// deployment locked them already. The lock is kept as long as the Pod
+// exists, as it reports the resources the Pod was started with. A
+// failed resolution is returned apart as it must not stop the
+// reconciliation.
+func (r *MinecraftServerReconciler) lockResources(resourceBuilder *resources.MinecraftServerResourceBuilder, podExists bool) (*common.ResourceRefResolutionError, error) {
+	minecraftServer := resourceBuilder.Instance
+	if len(minecraftServer.Spec.LockedResources) > 0 {
+		return nil, nil
+	}
+	if podExists && minecraftServer.Status.ResourcesLock != nil {
+		return nil, nil
+	}
+
+	resourcesLock, err := resourceBuilder.GetResourcesLock(getMinecraftServerSpecHash(&minecraftServer.Spec))
+	if resolutionError := getResourceRefResolutionError(err); resolutionError != nil {
+		return resolutionError, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	minecraftServer.Status.ResourcesLock = resourcesLock
+	return nil, nil
+}
+
+// Pings the server at most once per interval and returns when to
+// ping it next.
+func (r *MinecraftServerReconciler) updatePingStatus(ctx context.Context, minecraftServer *shulkermciov1alpha1.MinecraftServer) time.Duration {
+	status := &minecraftServer.Status
+	pingStatus, requeueAfter := r.Pinger.pingAtInterval(ctx, minecraftServer, net.JoinHostPort(status.ServerIP, "25565"), &status.LastPingTime, r.PingInterval)
+	if pingStatus == nil {
+		return requeueAfter
 	}
 
-	return ctrl.Result{}, r.Status().Update(ctx, minecraftServer)
+	status.OnlinePlayers = pingStatus.OnlinePlayers
+	status.MaxPlayers = pingStatus.MaxPlayers
+	status.ReportedVersion = pingStatus.VersionName
+	status.ProtocolVersion = pingStatus.ProtocolVersion
+	status.Motd = pingStatus.Motd
+	status.LatencyMilliseconds = pingStatus.Latency.Milliseconds()
+	return requeueAfter
+}
+
+func clearPingStatus(status *shulkermciov1alpha1.MinecraftServerStatus) {
+	status.OnlinePlayers = 0
+	status.MaxPlayers = 0
+	status.ReportedVersion = ""
+	status.ProtocolVersion = 0
+	status.Motd = ""
+	status.LatencyMilliseconds = 0
+	status.LastPingTime = nil
+}
+
+func getMinecraftServerSpecHash(spec *shulkermciov1alpha1.MinecraftServerSpec) string {
+	hasher := fnv.New32a()
+	hashutil.DeepHashObject(hasher, *spec)
+
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
 }
 
 func (r *MinecraftServerReconciler) getMinecraftServer(ctx context.Context, namespacedName types.NamespacedName) (*shulkermciov1alpha1.MinecraftServer, error) {
@@ -115,9 +284,28 @@ func (r *MinecraftServerReconciler) getMinecraftServer(ctx context.Context, name
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MinecraftServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Images == nil {
+		r.Images = common.NewDefaultImages()
+	}
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("minecraftserver-controller")
+	}
+	if r.PingInterval <= 0 {
+		r.PingInterval = defaultPingInterval
+	}
+	if r.Pinger == nil {
+		r.Pinger = NewPinger()
+	}
+	if err := mgr.Add(r.Pinger); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&shulkermciov1alpha1.MinecraftServer{}).
 		Owns(&corev1.Pod{}).
 		Owns(&corev1.ConfigMap{}).
+		Watches(&source.Channel{Source: r.Pinger.Events}, &handler.EnqueueRequestForObject{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&corev1.Secret{}).
 		Complete(r)
 }
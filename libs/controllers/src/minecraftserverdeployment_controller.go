@@ -9,12 +9,17 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"sort"
+	"strconv"
 
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
 	hashutil "k8s.io/kubernetes/pkg/util/hash"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -29,10 +34,12 @@ import (
 // MinecraftServerDeploymentReconciler reconciles a MinecraftServerDeployment object
 type MinecraftServerDeploymentReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
-//+kubebuilder:rbac:groups=shulkermc.io,resources=minecraftservers,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=shulkermc.io,resources=minecraftservers,verbs=get;list;watch;create;update;delete
 //+kubebuilder:rbac:groups=shulkermc.io,resources=minecraftserverdeployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=shulkermc.io,resources=minecraftserverdeployments/status,verbs=get;update;patch
 
@@ -56,16 +63,19 @@ func (r *MinecraftServerDeploymentReconciler) Reconcile(ctx context.Context, req
 	}, cluster)
 	if err != nil {
 		logger.Error(err, "Referenced MinecraftCluster does not exists")
+		recordClusterNotFoundEvent(r.Recorder, minecraftServerDeployment, minecraftServerDeployment.Spec.ClusterRef.Name, err)
 		return ctrl.Result{}, err
 	}
 
 	resourceBuilder := resources.MinecraftServerDeploymentResourceBuilder{
 		Instance: minecraftServerDeployment,
 		Scheme:   r.Scheme,
+		Client:   r.Client,
+		Ctx:      ctx,
 	}
 	builders, dirtyBuilders := resourceBuilder.ResourceBuilders()
 
-	err = ReconcileWithResourceBuilders(r.Client, ctx, builders, dirtyBuilders)
+	err = ReconcileWithResourceBuilders(r.Client, ctx, builders, dirtyBuilders, r.Recorder, minecraftServerDeployment)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -76,12 +86,50 @@ func (r *MinecraftServerDeploymentReconciler) Reconcile(ctx context.Context, req
 	}
 
 	templateHash := getMinecraftServerTemplateHash(&minecraftServerDeployment.Spec.Template)
-	var matchingMinecraftServers []*shulkermciov1alpha1.MinecraftServer
+
+	// New servers are held back until the resources of the template
+	// are resolved, the existing ones are left untouched
+	resourcesLock, err := resourceBuilder.GetResourcesLock(templateHash)
+	if resolutionError := getResourceRefResolutionError(err); resolutionError != nil {
+		logger.Error(resolutionError, "Failed to resolve resources of the template, holding back new servers")
+		r.Recorder.Event(minecraftServerDeployment, corev1.EventTypeWarning, "ResourceResolutionFailed", resolutionError.Error())
+		resourceResolutionFailuresTotal.WithLabelValues("MinecraftServerDeployment", minecraftServerDeployment.Namespace, minecraftServerDeployment.Spec.ClusterRef.Name, minecraftServerDeployment.Name).Inc()
+		minecraftServerDeployment.Status.SetCondition(shulkermciov1alpha1.MinecraftServerDeploymentResourcesResolvedCondition, metav1.ConditionFalse, "ResolutionFailed", resolutionError.Error())
+		if err := r.Status().Update(ctx, minecraftServerDeployment); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: resourceResolutionRetryInterval}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	minecraftServerDeployment.Status.SetCondition(shulkermciov1alpha1.MinecraftServerDeploymentResourcesResolvedCondition, metav1.ConditionTrue, "Resolved", "All resources of the template are resolved")
+	if minecraftServerDeployment.Status.ResourcesLock != resourcesLock {
+		// The lock is saved before any minecraftServer uses it, resolving it
+		// again could give other resources
+		minecraftServerDeployment.Status.ResourcesLock = resourcesLock
+		if err := r.Status().Update(ctx, minecraftServerDeployment); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	var currentMinecraftServers, oldMinecraftServers []*shulkermciov1alpha1.MinecraftServer
+	var terminatingOldMinecraftServers int
 	var availableReplicas, unavailableReplicas uint
 
-	for _, minecraftServer := range allMinecraftServers.Items {
-		if minecraftServer.Labels[shulkermciov1alpha1.MinecraftServerDeploymentTemplateHashLabelName] == templateHash {
-			matchingMinecraftServers = append(matchingMinecraftServers, &minecraftServer)
+	for i := range allMinecraftServers.Items {
+		minecraftServer := &allMinecraftServers.Items[i]
+		isCurrent := minecraftServer.Labels[shulkermciov1alpha1.MinecraftServerDeploymentTemplateHashLabelName] == templateHash
+
+		// Servers being deleted are leaving the deployment and must not
+		// be counted as replicas anymore
+		if minecraftServer.DeletionTimestamp != nil {
+			if !isCurrent {
+				terminatingOldMinecraftServers += 1
+			}
+		} else if isCurrent {
+			currentMinecraftServers = append(currentMinecraftServers, minecraftServer)
+		} else {
+			oldMinecraftServers = append(oldMinecraftServers, minecraftServer)
 		}
 
 		for _, condition := range minecraftServer.Status.Conditions {
@@ -95,37 +143,16 @@ func (r *MinecraftServerDeploymentReconciler) Reconcile(ctx context.Context, req
 		}
 	}
 
-	if len(matchingMinecraftServers) < int(minecraftServerDeployment.Spec.Replicas) {
-		minecraftServersToCreate := int(minecraftServerDeployment.Spec.Replicas) - len(matchingMinecraftServers)
-
-		for i := 0; i < minecraftServersToCreate; i += 1 {
-			minecraftServerId := common.RandomResourceId(6)
-			minecraftServer := shulkermciov1alpha1.MinecraftServer{}
-
-			labels := r.getMinecraftServerLabels(minecraftServerDeployment)
-			for k, v := range minecraftServerDeployment.Spec.Template.Labels {
-				labels[k] = v
-			}
-			labels[shulkermciov1alpha1.MinecraftServerDeploymentTemplateHashLabelName] = templateHash
-
-			minecraftServer.Namespace = minecraftServerDeployment.Namespace
-			minecraftServer.Name = fmt.Sprintf("%s-%s-%s", minecraftServerDeployment.Name, templateHash, minecraftServerId)
-			minecraftServer.Labels = labels
-			minecraftServer.Spec = minecraftServerDeployment.Spec.Template.Spec
-			minecraftServer.Spec.ClusterRef = minecraftServerDeployment.Spec.ClusterRef
-			minecraftServer.Spec.Configuration = minecraftServerDeployment.Spec.Template.Spec.Configuration
-			minecraftServer.Spec.Configuration.ExistingConfigMapName = resourceBuilder.GetConfigMapName()
-
-			if err := controllerutil.SetControllerReference(minecraftServerDeployment, &minecraftServer, r.Scheme); err != nil {
-				err = fmt.Errorf("failed setting controller reference for MinecraftServer: %v", err)
-				return ctrl.Result{}, err
-			}
+	readyCurrentMinecraftServers := countReadyMinecraftServers(currentMinecraftServers)
+	r.setProgressingCondition(minecraftServerDeployment, len(currentMinecraftServers), readyCurrentMinecraftServers, len(oldMinecraftServers))
 
-			err = r.Create(ctx, &minecraftServer)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
-		}
+	if minecraftServerDeployment.Spec.Strategy.GetType() == shulkermciov1alpha1.DeploymentStrategyRecreate {
+		err = r.recreateMinecraftServers(ctx, minecraftServerDeployment, &resourceBuilder, templateHash, currentMinecraftServers, oldMinecraftServers, terminatingOldMinecraftServers)
+	} else {
+		err = r.rollMinecraftServers(ctx, minecraftServerDeployment, &resourceBuilder, templateHash, currentMinecraftServers, oldMinecraftServers)
+	}
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
 	selector, err := metav1.LabelSelectorAsSelector(resourceBuilder.GetPodSelector())
@@ -133,7 +160,8 @@ func (r *MinecraftServerDeploymentReconciler) Reconcile(ctx context.Context, req
 		return ctrl.Result{}, err
 	}
 
-	minecraftServerDeployment.Status.Replicas = minecraftServerDeployment.Spec.Replicas
+	minecraftServerDeployment.Status.Replicas = int32(len(currentMinecraftServers) + len(oldMinecraftServers))
+	minecraftServerDeployment.Status.UpdatedReplicas = int32(len(currentMinecraftServers))
 	minecraftServerDeployment.Status.AvailableReplicas = int32(availableReplicas)
 	minecraftServerDeployment.Status.UnavailableReplicas = int32(unavailableReplicas)
 	minecraftServerDeployment.Status.Selector = selector.String()
@@ -147,6 +175,195 @@ func (r *MinecraftServerDeploymentReconciler) Reconcile(ctx context.Context, req
 	return ctrl.Result{}, r.Status().Update(ctx, minecraftServerDeployment)
 }
 
+// Replaces the outdated MinecraftServers step by step, never going
+// above the desired replicas plus the surge, nor below the desired
+// replicas minus the unavailable budget.
+func (r *MinecraftServerDeploymentReconciler) rollMinecraftServers(ctx context.Context, deployment *shulkermciov1alpha1.MinecraftServerDeployment, resourceBuilder *resources.MinecraftServerDeploymentResourceBuilder, templateHash string, currentMinecraftServers []*shulkermciov1alpha1.MinecraftServer, oldMinecraftServers []*shulkermciov1alpha1.MinecraftServer) error {
+	desiredReplicas := int(deployment.Spec.Replicas)
+	maxSurge, maxUnavailable, err := resolveRollingUpdateBounds(&deployment.Spec.Strategy, deployment.Spec.Replicas)
+	if err != nil {
+		return err
+	}
+
+	// The replacement of a server with persistent data takes its
+	// index and its claim, so it can only be created once the
+	// outdated server is gone
+	if deployment.Spec.Template.Spec.Persistence != nil {
+		maxSurge = 0
+		if maxUnavailable == 0 {
+			maxUnavailable = 1
+		}
+	}
+
+	if len(currentMinecraftServers) < desiredReplicas {
+		minecraftServersToCreate := desiredReplicas - len(currentMinecraftServers)
+		surgeRoom := desiredReplicas + maxSurge - len(currentMinecraftServers) - len(oldMinecraftServers)
+		if surgeRoom < minecraftServersToCreate {
+			minecraftServersToCreate = surgeRoom
+		}
+
+		if err := r.createMinecraftServers(ctx, deployment, resourceBuilder, templateHash, minecraftServersToCreate); err != nil {
+			return err
+		}
+	} else if len(currentMinecraftServers) > desiredReplicas {
+		sortMinecraftServersForScaleDown(currentMinecraftServers)
+		if err := r.deleteMinecraftServers(ctx, deployment, currentMinecraftServers[:len(currentMinecraftServers)-desiredReplicas]); err != nil {
+			return err
+		}
+	}
+
+	if len(oldMinecraftServers) == 0 {
+		return nil
+	}
+
+	// Outdated servers which are not ready can be removed without
+	// hurting the availability, the ready ones are only removed when
+	// enough servers are ready to compensate
+	readyCurrentMinecraftServers := countReadyMinecraftServers(currentMinecraftServers)
+	readyOldMinecraftServers := countReadyMinecraftServers(oldMinecraftServers)
+	minAvailable := desiredReplicas - maxUnavailable
+	unavailableCurrentMinecraftServers := len(currentMinecraftServers) - readyCurrentMinecraftServers
+
+	scaleDownBudget := len(currentMinecraftServers) + len(oldMinecraftServers) - minAvailable - unavailableCurrentMinecraftServers
+	readyScaleDownBudget := readyCurrentMinecraftServers + readyOldMinecraftServers - minAvailable
+
+	sortMinecraftServersForScaleDown(oldMinecraftServers)
+	var minecraftServersToDelete []*shulkermciov1alpha1.MinecraftServer
+	for _, minecraftServer := range oldMinecraftServers {
+		if scaleDownBudget <= 0 {
+			break
+		}
+
+		if isMinecraftServerReady(minecraftServer) {
+			if readyScaleDownBudget <= 0 {
+				break
+			}
+			readyScaleDownBudget -= 1
+		}
+
+		minecraftServersToDelete = append(minecraftServersToDelete, minecraftServer)
+		scaleDownBudget -= 1
+	}
+
+	return r.deleteMinecraftServers(ctx, deployment, minecraftServersToDelete)
+}
+
+// Deletes all the outdated MinecraftServers and waits for them to be
+// gone before creating the new ones.
+func (r *MinecraftServerDeploymentReconciler) recreateMinecraftServers(ctx context.Context, deployment *shulkermciov1alpha1.MinecraftServerDeployment, resourceBuilder *resources.MinecraftServerDeploymentResourceBuilder, templateHash string, currentMinecraftServers []*shulkermciov1alpha1.MinecraftServer, oldMinecraftServers []*shulkermciov1alpha1.MinecraftServer, terminatingOldMinecraftServers int) error {
+	if len(oldMinecraftServers) > 0 {
+		return r.deleteMinecraftServers(ctx, deployment, oldMinecraftServers)
+	} else if terminatingOldMinecraftServers > 0 {
+		// We will be notified when the remaining servers are gone
+		return nil
+	}
+
+	desiredReplicas := int(deployment.Spec.Replicas)
+	if len(currentMinecraftServers) < desiredReplicas {
+		return r.createMinecraftServers(ctx, deployment, resourceBuilder, templateHash, desiredReplicas-len(currentMinecraftServers))
+	} else if len(currentMinecraftServers) > desiredReplicas {
+		sortMinecraftServersForScaleDown(currentMinecraftServers)
+		return r.deleteMinecraftServers(ctx, deployment, currentMinecraftServers[:len(currentMinecraftServers)-desiredReplicas])
+	}
+
+	return nil
+}
+
+// Servers with persistent data are named after the lowest free index
+// below the desired replicas, and keep the claim of this index from
+// one server to the next. Other servers get a random name.
+func (r *MinecraftServerDeploymentReconciler) createMinecraftServers(ctx context.Context, deployment *shulkermciov1alpha1.MinecraftServerDeployment, resourceBuilder *resources.MinecraftServerDeploymentResourceBuilder, templateHash string, count int) error {
+	var freeReplicaIndexes []int
+	persistent := deployment.Spec.Template.Spec.Persistence != nil
+	if persistent {
+		allMinecraftServers, err := r.getAllMinecraftServers(ctx, deployment)
+		if err != nil {
+			return err
+		}
+
+		// We will be notified when the servers holding the other
+		// indexes are gone
+		freeReplicaIndexes = getFreeReplicaIndexes(allMinecraftServers.Items, int(deployment.Spec.Replicas))
+		if len(freeReplicaIndexes) < count {
+			count = len(freeReplicaIndexes)
+		}
+	}
+
+	for i := 0; i < count; i += 1 {
+		minecraftServer := shulkermciov1alpha1.MinecraftServer{}
+
+		labels := r.getMinecraftServerLabels(deployment)
+		for k, v := range deployment.Spec.Template.Labels {
+			labels[k] = v
+		}
+		labels[shulkermciov1alpha1.MinecraftServerDeploymentTemplateHashLabelName] = templateHash
+
+		minecraftServer.Namespace = deployment.Namespace
+		if persistent {
+			replicaIndex := strconv.Itoa(freeReplicaIndexes[i])
+			labels[shulkermciov1alpha1.MinecraftServerDeploymentReplicaIndexLabelName] = replicaIndex
+			minecraftServer.Name = fmt.Sprintf("%s-%s", deployment.Name, replicaIndex)
+		} else {
+			minecraftServer.Name = fmt.Sprintf("%s-%s-%s", deployment.Name, templateHash, common.RandomResourceId(6))
+		}
+		minecraftServer.Labels = labels
+		minecraftServer.Spec = deployment.Spec.Template.Spec
+		minecraftServer.Spec.ClusterRef = deployment.Spec.ClusterRef
+		minecraftServer.Spec.Configuration = deployment.Spec.Template.Spec.Configuration
+		minecraftServer.Spec.Configuration.ExistingConfigMapName = resourceBuilder.GetConfigMapName()
+		minecraftServer.Spec.LockedResources = deployment.Status.ResourcesLock.Resources
+
+		if err := controllerutil.SetControllerReference(deployment, &minecraftServer, r.Scheme); err != nil {
+			return fmt.Errorf("failed setting controller reference for MinecraftServer: %v", err)
+		}
+
+		if err := r.Create(ctx, &minecraftServer); k8serrors.IsAlreadyExists(err) && persistent {
+			// The cache did not see the previous server of the index
+			// yet, it is still there
+			continue
+		} else if err != nil {
+			return err
+		}
+		r.Recorder.Eventf(deployment, corev1.EventTypeNormal, "SuccessfulCreate", "Created MinecraftServer %s", minecraftServer.Name)
+	}
+
+	return nil
+}
+
+func (r *MinecraftServerDeploymentReconciler) deleteMinecraftServers(ctx context.Context, deployment *shulkermciov1alpha1.MinecraftServerDeployment, minecraftServers []*shulkermciov1alpha1.MinecraftServer) error {
+	logger := log.FromContext(ctx)
+
+	for _, minecraftServer := range minecraftServers {
+		logger.Info("Deleting MinecraftServer", "minecraftServer", minecraftServer.Name)
+
+		// Foreground deletion keeps the MinecraftServer around until
+		// its Pod had the time to gracefully stop
+		err := r.Delete(ctx, minecraftServer, client.PropagationPolicy(metav1.DeletePropagationForeground))
+		if k8serrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		r.Recorder.Eventf(deployment, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted MinecraftServer %s", minecraftServer.Name)
+	}
+
+	return nil
+}
+
+func (r *MinecraftServerDeploymentReconciler) setProgressingCondition(deployment *shulkermciov1alpha1.MinecraftServerDeployment, currentReplicas int, readyCurrentReplicas int, oldReplicas int) {
+	desiredReplicas := int(deployment.Spec.Replicas)
+
+	if oldReplicas > 0 {
+		deployment.Status.SetCondition(shulkermciov1alpha1.MinecraftServerDeploymentProgressingCondition, metav1.ConditionTrue, "RollingOut", fmt.Sprintf("%d outdated servers are waiting to be replaced", oldReplicas))
+	} else if currentReplicas != desiredReplicas {
+		deployment.Status.SetCondition(shulkermciov1alpha1.MinecraftServerDeploymentProgressingCondition, metav1.ConditionTrue, "Scaling", fmt.Sprintf("Scaling from %d to %d servers", currentReplicas, desiredReplicas))
+	} else if readyCurrentReplicas < desiredReplicas {
+		deployment.Status.SetCondition(shulkermciov1alpha1.MinecraftServerDeploymentProgressingCondition, metav1.ConditionTrue, "WaitingForReadiness", fmt.Sprintf("%d of %d servers are ready", readyCurrentReplicas, desiredReplicas))
+	} else {
+		deployment.Status.SetCondition(shulkermciov1alpha1.MinecraftServerDeploymentProgressingCondition, metav1.ConditionFalse, "Complete", "All servers are up to date and ready")
+	}
+}
+
 func (r *MinecraftServerDeploymentReconciler) getMinecraftServerDeployment(ctx context.Context, namespacedName types.NamespacedName) (*shulkermciov1alpha1.MinecraftServerDeployment, error) {
 	minecraftServerDeployment := &shulkermciov1alpha1.MinecraftServerDeployment{}
 	err := r.Get(ctx, namespacedName, minecraftServerDeployment)
@@ -155,8 +372,8 @@ func (r *MinecraftServerDeploymentReconciler) getMinecraftServerDeployment(ctx c
 
 func (r *MinecraftServerDeploymentReconciler) getMinecraftServerLabels(deployment *shulkermciov1alpha1.MinecraftServerDeployment) map[string]string {
 	labels := map[string]string{
-		"minecraftcluster.shulkermc.io/name":          deployment.Spec.ClusterRef.Name,
-		"minecraftserverdeployment.shulkermc.io/name": deployment.Name,
+		"minecraftcluster.shulkermc.io/name": deployment.Spec.ClusterRef.Name,
+		minecraftServerDeploymentNameLabel:   deployment.Name,
 	}
 	return labels
 }
@@ -170,6 +387,10 @@ func (r *MinecraftServerDeploymentReconciler) getAllMinecraftServers(ctx context
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MinecraftServerDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("minecraftserverdeployment-controller")
+	}
+
 	err := mgr.GetFieldIndexer().IndexField(context.Background(), &shulkermciov1alpha1.MinecraftServerDeployment{}, ".spec.clusterRef.name", func(object client.Object) []string {
 		minecraftServerDeployment := object.(*shulkermciov1alpha1.MinecraftServerDeployment)
 		return []string{minecraftServerDeployment.Spec.ClusterRef.Name}
@@ -185,6 +406,61 @@ func (r *MinecraftServerDeploymentReconciler) SetupWithManager(mgr ctrl.Manager)
 		Complete(r)
 }
 
+// Sorts the MinecraftServers so the best candidates for deletion
+// come first: not ready servers, then the ones with the fewest
+// players, then the newest ones.
+func sortMinecraftServersForScaleDown(minecraftServers []*shulkermciov1alpha1.MinecraftServer) {
+	sort.SliceStable(minecraftServers, func(i, j int) bool {
+		a, b := minecraftServers[i], minecraftServers[j]
+
+		aReady, bReady := isMinecraftServerReady(a), isMinecraftServerReady(b)
+		if aReady != bReady {
+			return !aReady
+		}
+
+		if a.Status.OnlinePlayers != b.Status.OnlinePlayers {
+			return a.Status.OnlinePlayers < b.Status.OnlinePlayers
+		}
+
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	})
+}
+
+// Returns the replica indexes below the limit which are not held by
+// any of the servers, including the ones being deleted, in
+// ascending order.
+func getFreeReplicaIndexes(minecraftServers []shulkermciov1alpha1.MinecraftServer, limit int) []int {
+	usedReplicaIndexes := map[int]bool{}
+	for _, minecraftServer := range minecraftServers {
+		replicaIndex, err := strconv.Atoi(minecraftServer.Labels[shulkermciov1alpha1.MinecraftServerDeploymentReplicaIndexLabelName])
+		if err == nil {
+			usedReplicaIndexes[replicaIndex] = true
+		}
+	}
+
+	var freeReplicaIndexes []int
+	for replicaIndex := 0; replicaIndex < limit; replicaIndex += 1 {
+		if !usedReplicaIndexes[replicaIndex] {
+			freeReplicaIndexes = append(freeReplicaIndexes, replicaIndex)
+		}
+	}
+	return freeReplicaIndexes
+}
+
+func isMinecraftServerReady(minecraftServer *shulkermciov1alpha1.MinecraftServer) bool {
+	return meta.IsStatusConditionTrue(minecraftServer.Status.Conditions, string(shulkermciov1alpha1.MinecraftServerReadyCondition))
+}
+
+func countReadyMinecraftServers(minecraftServers []*shulkermciov1alpha1.MinecraftServer) int {
+	count := 0
+	for _, minecraftServer := range minecraftServers {
+		if isMinecraftServerReady(minecraftServer) {
+			count += 1
+		}
+	}
+	return count
+}
+
 func getMinecraftServerTemplateHash(template *shulkermciov1alpha1.MinecraftServerTemplate) string {
 	hasher := fnv.New32a()
 	hashutil.DeepHashObject(hasher, *template)
@@ -0,0 +1,114 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("MinecraftServerDeployment controller", func() {
+	Describe("getFreeReplicaIndexes", func() {
+		newMinecraftServer := func(replicaIndex string, deleting bool) shulkermciov1alpha1.MinecraftServer {
+			minecraftServer := shulkermciov1alpha1.MinecraftServer{}
+			minecraftServer.Labels = map[string]string{
+				shulkermciov1alpha1.MinecraftServerDeploymentReplicaIndexLabelName: replicaIndex,
+			}
+			if deleting {
+				now := metav1.Now()
+				minecraftServer.DeletionTimestamp = &now
+			}
+			return minecraftServer
+		}
+
+		It("gives the lowest free indexes below the limit", func() {
+			minecraftServers := []shulkermciov1alpha1.MinecraftServer{
+				newMinecraftServer("0", false),
+				newMinecraftServer("2", false),
+			}
+
+			Expect(getFreeReplicaIndexes(minecraftServers, 4)).To(Equal([]int{1, 3}))
+		})
+
+		It("keeps the index of a server being deleted until it is gone", func() {
+			minecraftServers := []shulkermciov1alpha1.MinecraftServer{
+				newMinecraftServer("0", false),
+				newMinecraftServer("1", true),
+			}
+
+			Expect(getFreeReplicaIndexes(minecraftServers, 2)).To(BeEmpty())
+		})
+
+		It("gives back the index of a removed server, and its claim", func() {
+			minecraftServers := []shulkermciov1alpha1.MinecraftServer{
+				newMinecraftServer("1", false),
+			}
+
+			Expect(getFreeReplicaIndexes(minecraftServers, 2)).To(Equal([]int{0}))
+		})
+	})
+
+	Describe("sortMinecraftServersForScaleDown", func() {
+		newMinecraftServer := func(name string, ready bool, onlinePlayers int32, age time.Duration) *shulkermciov1alpha1.MinecraftServer {
+			minecraftServer := &shulkermciov1alpha1.MinecraftServer{}
+			minecraftServer.Name = name
+			minecraftServer.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+			minecraftServer.Status.OnlinePlayers = onlinePlayers
+
+			readyStatus := metav1.ConditionFalse
+			if ready {
+				readyStatus = metav1.ConditionTrue
+			}
+			minecraftServer.Status.SetCondition(shulkermciov1alpha1.MinecraftServerReadyCondition, readyStatus, "Test", "")
+			return minecraftServer
+		}
+
+		DescribeTable("deletes the least valuable servers first",
+			func(minecraftServers []*shulkermciov1alpha1.MinecraftServer, expectedNames []string) {
+				sortMinecraftServersForScaleDown(minecraftServers)
+
+				names := make([]string, len(minecraftServers))
+				for i, minecraftServer := range minecraftServers {
+					names[i] = minecraftServer.Name
+				}
+				Expect(names).To(Equal(expectedNames))
+			},
+			Entry("not ready servers first",
+				[]*shulkermciov1alpha1.MinecraftServer{
+					newMinecraftServer("ready", true, 0, time.Hour),
+					newMinecraftServer("not-ready", false, 10, time.Hour),
+				},
+				[]string{"not-ready", "ready"}),
+			Entry("servers with the fewest players first",
+				[]*shulkermciov1alpha1.MinecraftServer{
+					newMinecraftServer("crowded", true, 50, time.Hour),
+					newMinecraftServer("empty", true, 0, time.Hour),
+					newMinecraftServer("quiet", true, 3, time.Hour),
+				},
+				[]string{"empty", "quiet", "crowded"}),
+			Entry("newest servers first when they have as many players",
+				[]*shulkermciov1alpha1.MinecraftServer{
+					newMinecraftServer("old", true, 5, 2*time.Hour),
+					newMinecraftServer("new", true, 5, time.Minute),
+				},
+				[]string{"new", "old"}),
+			Entry("readiness before players and players before age",
+				[]*shulkermciov1alpha1.MinecraftServer{
+					newMinecraftServer("ready-empty-old", true, 0, 2*time.Hour),
+					newMinecraftServer("ready-crowded-new", true, 20, time.Minute),
+					newMinecraftServer("not-ready-crowded", false, 20, time.Hour),
+					newMinecraftServer("ready-empty-new", true, 0, time.Minute),
+				},
+				[]string{"not-ready-crowded", "ready-empty-new", "ready-empty-old", "ready-crowded-new"}),
+		)
+	})
+})
@@ -0,0 +1,180 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	serverlistping "github.com/iamblueslime/shulker/libs/serverlistping/src"
+)
+
+const (
+	defaultPingInterval = 30 * time.Second
+	pingTimeout         = 5 * time.Second
+
+	// Number of pings running at once, per controller.
+	defaultPingWorkers = 16
+)
+
+// Pinger pings servers and proxies with the Server List Ping protocol
+// from a pool of workers, apart from the reconciliations. Pinging
+// within a reconciliation would hold one of the few reconcile workers
+// for the whole timeout whenever a server is unreachable, a handful
+// of them stalling every other object of the controller. The price is
+// a ping result reaching the status one reconciliation later: the
+// object is enqueued again through Events once its ping is done, and
+// takes the result then.
+type Pinger struct {
+	// Number of pings running at once, defaults to 16.
+	Workers int
+
+	// Timeout of a ping, defaults to 5 seconds.
+	Timeout time.Duration
+
+	// Receives the objects whose ping is done, to be watched by
+	// their controller.
+	Events chan event.GenericEvent
+
+	mutex    sync.Mutex
+	requests chan pingRequest
+	pending  map[types.NamespacedName]bool
+	results  map[types.NamespacedName]pingResult
+}
+
+type pingRequest struct {
+	object  client.Object
+	address string
+}
+
+type pingResult struct {
+	status *serverlistping.Status
+	err    error
+	time   metav1.Time
+}
+
+func NewPinger() *Pinger {
+	return &Pinger{
+		Workers:  defaultPingWorkers,
+		Timeout:  pingTimeout,
+		Events:   make(chan event.GenericEvent),
+		requests: make(chan pingRequest, 1024),
+		pending:  map[types.NamespacedName]bool{},
+		results:  map[types.NamespacedName]pingResult{},
+	}
+}
+
+// Start implements manager.Runnable, pinging until the context is
+// done.
+func (p *Pinger) Start(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for i := 0; i < p.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+
+	wg.Wait()
+	return nil
+}
+
+func (p *Pinger) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case request := <-p.requests:
+			pingCtx, cancel := context.WithTimeout(ctx, p.Timeout)
+			status, err := serverlistping.Ping(pingCtx, request.address)
+			cancel()
+
+			key := client.ObjectKeyFromObject(request.object)
+			p.mutex.Lock()
+			p.results[key] = pingResult{status: status, err: err, time: metav1.Now()}
+			delete(p.pending, key)
+			p.mutex.Unlock()
+
+			select {
+			case p.Events <- event.GenericEvent{Object: request.object}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Pings the server or proxy listening at the given address at most
+// once per interval, recording the time of the ping. Returns its
+// status once the ping is done, nil when it was not pinged yet or
+// did not answer, and when to reconcile it next. Pinging on every
+// reconciliation would loop, as each ping updates the status which
+// triggers a new one.
+func (p *Pinger) pingAtInterval(ctx context.Context, object client.Object, address string, lastPingTime **metav1.Time, interval time.Duration) (*serverlistping.Status, time.Duration) {
+	key := client.ObjectKeyFromObject(object)
+
+	p.mutex.Lock()
+	result, done := p.results[key]
+	delete(p.results, key)
+	p.mutex.Unlock()
+
+	if done {
+		*lastPingTime = &result.time
+		if result.err != nil {
+			// The previous values are kept, a server can miss a
+			// ping while busy
+			log.FromContext(ctx).Info("Failed to ping", "address", address, "error", result.err.Error())
+			return nil, interval
+		}
+		return result.status, interval
+	}
+
+	if *lastPingTime != nil {
+		if elapsed := time.Since((*lastPingTime).Time); elapsed < interval {
+			return nil, interval - elapsed
+		}
+	}
+
+	p.request(object, address)
+
+	// The object is enqueued again once pinged, this is only a
+	// fallback in case the request was dropped
+	return nil, p.Timeout + interval
+}
+
+// Queues a ping of the object, unless one is already queued. Requests
+// are dropped when the queue is full, to be made again on a later
+// reconciliation.
+func (p *Pinger) request(object client.Object, address string) {
+	key := client.ObjectKeyFromObject(object)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.pending[key] {
+		return
+	}
+
+	select {
+	case p.requests <- pingRequest{object: object.DeepCopyObject().(client.Object), address: address}:
+		p.pending[key] = true
+	default:
+	}
+}
+
+// Drops the ping result of an object which no longer exists.
+func (p *Pinger) forget(key types.NamespacedName) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.results, key)
+}
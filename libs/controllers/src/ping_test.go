@@ -0,0 +1,88 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("Ping", func() {
+	newProxy := func() *shulkermciov1alpha1.Proxy {
+		return &shulkermciov1alpha1.Proxy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "my-proxy"},
+		}
+	}
+
+	It("does not ping again before the interval elapsed", func() {
+		pinger := NewPinger()
+		lastPing := metav1.NewTime(time.Now().Add(-10 * time.Second))
+		lastPingTime := &lastPing
+
+		status, requeueAfter := pinger.pingAtInterval(context.Background(), newProxy(), "127.0.0.1:1", &lastPingTime, 30*time.Second)
+		Expect(status).To(BeNil())
+		Expect(requeueAfter).To(BeNumerically("~", 20*time.Second, time.Second))
+		Expect(lastPingTime).To(Equal(&lastPing))
+		Expect(pinger.requests).To(BeEmpty())
+	})
+
+	It("queues a ping once without waiting for it", func() {
+		pinger := NewPinger()
+		var lastPingTime *metav1.Time
+
+		status, _ := pinger.pingAtInterval(context.Background(), newProxy(), "127.0.0.1:1", &lastPingTime, 30*time.Second)
+		Expect(status).To(BeNil())
+		Expect(lastPingTime).To(BeNil())
+
+		pinger.pingAtInterval(context.Background(), newProxy(), "127.0.0.1:1", &lastPingTime, 30*time.Second)
+		Expect(pinger.requests).To(HaveLen(1))
+	})
+
+	It("records the time of a ping which failed and enqueues the object again", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		address := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		pinger := NewPinger()
+		go func() {
+			defer GinkgoRecover()
+			Expect(pinger.Start(ctx)).To(Succeed())
+		}()
+
+		var lastPingTime *metav1.Time
+		pinger.pingAtInterval(ctx, newProxy(), address, &lastPingTime, 30*time.Second)
+
+		var pinged event.GenericEvent
+		Eventually(pinger.Events).Should(Receive(&pinged))
+		Expect(pinged.Object.GetName()).To(Equal("my-proxy"))
+
+		status, requeueAfter := pinger.pingAtInterval(ctx, newProxy(), address, &lastPingTime, 30*time.Second)
+		Expect(status).To(BeNil())
+		Expect(requeueAfter).To(Equal(30 * time.Second))
+		Expect(lastPingTime).NotTo(BeNil())
+	})
+
+	It("forgets the result of a deleted object", func() {
+		pinger := NewPinger()
+		pinger.results[types.NamespacedName{Namespace: testNamespace, Name: "my-proxy"}] = pingResult{time: metav1.Now()}
+
+		pinger.forget(types.NamespacedName{Namespace: testNamespace, Name: "my-proxy"})
+		Expect(pinger.results).To(BeEmpty())
+	})
+})
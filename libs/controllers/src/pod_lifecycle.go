@@ -0,0 +1,215 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	initfs "github.com/iamblueslime/shulker/libs/initfs/src"
+)
+
+// Reasons for which a container waits which will not go away by
+// themselves.
+var failedContainerWaitingReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// Phase of a Pod of a MinecraftServer or Proxy, with the failure
+// which led to it, if any.
+type podLifecycle struct {
+	Phase   shulkermciov1alpha1.Phase
+	Message string
+	Failure *shulkermciov1alpha1.LifecycleFailure
+}
+
+// Computes the phase of a Pod from the statuses of its containers,
+// the main one running the server or proxy. The Pod is nil when it
+// does not exist.
+func getPodLifecycle(pod *corev1.Pod, mainContainerName string) podLifecycle {
+	if pod == nil {
+		return podLifecycle{Phase: shulkermciov1alpha1.PhasePending, Message: "Pod is not created yet"}
+	}
+
+	if pod.DeletionTimestamp != nil {
+		return podLifecycle{Phase: shulkermciov1alpha1.PhaseStopping, Message: "Pod is being deleted"}
+	}
+
+	if failure := getPodFailure(pod, mainContainerName); failure != nil {
+		return podLifecycle{Phase: shulkermciov1alpha1.PhaseFailed, Message: failure.Message, Failure: failure}
+	}
+
+	if pod.Status.Phase == corev1.PodSucceeded {
+		return podLifecycle{Phase: shulkermciov1alpha1.PhaseTerminated, Message: "Pod has completed"}
+	}
+
+	// The Pod only completes once its sidecars stopped too
+	if status := getContainerStatus(pod, mainContainerName); status != nil && status.State.Terminated != nil {
+		return podLifecycle{Phase: shulkermciov1alpha1.PhaseStopping, Message: fmt.Sprintf("Container %s has exited, waiting for the other containers to stop", mainContainerName)}
+	}
+
+	if scheduled := getPodCondition(pod, corev1.PodScheduled); scheduled == nil || scheduled.Status != corev1.ConditionTrue {
+		message := "Pod is waiting for a node"
+		if scheduled != nil && scheduled.Message != "" {
+			message = scheduled.Message
+		}
+		return podLifecycle{Phase: shulkermciov1alpha1.PhaseScheduling, Message: message}
+	}
+
+	if len(pod.Status.InitContainerStatuses) < len(pod.Spec.InitContainers) {
+		return podLifecycle{Phase: shulkermciov1alpha1.PhaseDownloadingResources, Message: "Init containers are not started yet"}
+	}
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.State.Terminated == nil {
+			return podLifecycle{Phase: shulkermciov1alpha1.PhaseDownloadingResources, Message: fmt.Sprintf("Init container %s is preparing the filesystem", status.Name)}
+		}
+	}
+
+	if ready := getPodCondition(pod, corev1.PodReady); ready != nil && ready.Status == corev1.ConditionTrue {
+		return podLifecycle{Phase: shulkermciov1alpha1.PhaseReady, Message: "Pod is ready"}
+	}
+
+	return podLifecycle{Phase: shulkermciov1alpha1.PhaseStarting, Message: fmt.Sprintf("Container %s is starting", mainContainerName)}
+}
+
+func getPodCondition(pod *corev1.Pod, conditionType corev1.PodConditionType) *corev1.PodCondition {
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type == conditionType {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func getContainerStatus(pod *corev1.Pod, containerName string) *corev1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == containerName {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return nil
+}
+
+// Returns why the Pod failed, or nil when it did not. The sidecars
+// only count when they cannot start, the Pod is kept running when
+// they exit.
+func getPodFailure(pod *corev1.Pod, mainContainerName string) *shulkermciov1alpha1.LifecycleFailure {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if failure := getInitContainerFailure(&status); failure != nil {
+			return failure
+		}
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if waiting := status.State.Waiting; waiting != nil && failedContainerWaitingReasons[waiting.Reason] {
+			return &shulkermciov1alpha1.LifecycleFailure{
+				Reason:    waiting.Reason,
+				Container: status.Name,
+				Message:   fmt.Sprintf("Container %s cannot start: %s", status.Name, waiting.Message),
+			}
+		}
+
+		if status.Name != mainContainerName {
+			continue
+		}
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			reason := "ContainerFailed"
+			if terminated.Reason == "OOMKilled" {
+				reason = "OOMKilled"
+			}
+			return &shulkermciov1alpha1.LifecycleFailure{
+				Reason:    reason,
+				Container: status.Name,
+				ExitCode:  terminated.ExitCode,
+				Message:   withLogTail(fmt.Sprintf("Container %s exited with code %d", status.Name, terminated.ExitCode), terminated.Message),
+				Time:      terminated.FinishedAt,
+			}
+		}
+	}
+
+	if pod.Status.Phase == corev1.PodFailed {
+		reason := pod.Status.Reason
+		if reason == "" {
+			reason = "PodFailed"
+		}
+		return &shulkermciov1alpha1.LifecycleFailure{
+			Reason:  reason,
+			Message: fmt.Sprintf("Pod has failed: %s", pod.Status.Message),
+		}
+	}
+
+	return nil
+}
+
+// The init container writes its result as termination message, which
+// tells why it failed. When it could not, the kubelet falls back to
+// the tail of its log.
+func getInitContainerFailure(status *corev1.ContainerStatus) *shulkermciov1alpha1.LifecycleFailure {
+	if waiting := status.State.Waiting; waiting != nil && failedContainerWaitingReasons[waiting.Reason] {
+		return &shulkermciov1alpha1.LifecycleFailure{
+			Reason:    waiting.Reason,
+			Container: status.Name,
+			Message:   fmt.Sprintf("Init container %s cannot start: %s", status.Name, waiting.Message),
+		}
+	}
+
+	terminated := status.State.Terminated
+	if terminated == nil || terminated.ExitCode == 0 {
+		return nil
+	}
+
+	failure := &shulkermciov1alpha1.LifecycleFailure{
+		Reason:    "InitFailed",
+		Container: status.Name,
+		ExitCode:  terminated.ExitCode,
+		Time:      terminated.FinishedAt,
+	}
+
+	result, err := initfs.ParseResult(terminated.Message)
+	if err != nil || result.Error == "" {
+		failure.Message = withLogTail(fmt.Sprintf("Init container %s exited with code %d", status.Name, terminated.ExitCode), terminated.Message)
+		return failure
+	}
+
+	if failedResource := result.GetFailedResource(); failedResource != nil && failedResource.Reason == initfs.ChecksumMismatchResourceFailureReason {
+		failure.Reason = "ChecksumMismatch"
+	}
+	failure.Message = result.Error
+	return failure
+}
+
+func withLogTail(message string, logTail string) string {
+	logTail = strings.TrimSpace(logTail)
+	if logTail == "" {
+		return message
+	}
+	return fmt.Sprintf("%s, last logs:\n%s", message, logTail)
+}
+
+// Keeps the failure already recorded when it is the same, for the
+// status not to change on every reconciliation.
+func recordLastFailure(lastFailure **shulkermciov1alpha1.LifecycleFailure, failure *shulkermciov1alpha1.LifecycleFailure) {
+	if failure == nil {
+		return
+	}
+
+	if previous := *lastFailure; previous != nil && previous.Reason == failure.Reason && previous.Container == failure.Container && previous.Message == failure.Message {
+		return
+	}
+
+	if failure.Time.IsZero() {
+		failure.Time = metav1.Now()
+	}
+	*lastFailure = failure
+}
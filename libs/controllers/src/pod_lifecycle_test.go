@@ -0,0 +1,76 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("Pod lifecycle", func() {
+	newRunningPod := func(containerStatuses ...corev1.ContainerStatus) *corev1.Pod {
+		return &corev1.Pod{
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				},
+				ContainerStatuses: containerStatuses,
+			},
+		}
+	}
+
+	running := corev1.ContainerStatus{
+		Name:  "probe",
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}
+
+	It("is pending without a Pod", func() {
+		Expect(getPodLifecycle(nil, "minecraft-server").Phase).To(Equal(shulkermciov1alpha1.PhasePending))
+	})
+
+	It("is starting while the main container runs without being ready", func() {
+		pod := newRunningPod(corev1.ContainerStatus{
+			Name:  "minecraft-server",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}, running)
+
+		Expect(getPodLifecycle(pod, "minecraft-server").Phase).To(Equal(shulkermciov1alpha1.PhaseStarting))
+	})
+
+	It("is stopping once the main container exited while sidecars still run", func() {
+		pod := newRunningPod(corev1.ContainerStatus{
+			Name:  "proxy",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}},
+		}, running)
+
+		lifecycle := getPodLifecycle(pod, "proxy")
+		Expect(lifecycle.Phase).To(Equal(shulkermciov1alpha1.PhaseStopping))
+		Expect(lifecycle.Failure).To(BeNil())
+	})
+
+	It("is failed when the main container exited with an error", func() {
+		pod := newRunningPod(corev1.ContainerStatus{
+			Name:  "minecraft-server",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}},
+		}, running)
+
+		lifecycle := getPodLifecycle(pod, "minecraft-server")
+		Expect(lifecycle.Phase).To(Equal(shulkermciov1alpha1.PhaseFailed))
+		Expect(lifecycle.Failure.Reason).To(Equal("OOMKilled"))
+	})
+
+	It("is terminated once all the containers exited", func() {
+		pod := newRunningPod()
+		pod.Status.Phase = corev1.PodSucceeded
+
+		Expect(getPodLifecycle(pod, "minecraft-server").Phase).To(Equal(shulkermciov1alpha1.PhaseTerminated))
+	})
+})
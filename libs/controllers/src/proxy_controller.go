@@ -7,27 +7,54 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	hashutil "k8s.io/kubernetes/pkg/util/hash"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	common "github.com/iamblueslime/shulker/libs/resources/src"
 	resources "github.com/iamblueslime/shulker/libs/resources/src/proxy"
 )
 
 // ProxyReconciler reconciles a Proxy object
 type ProxyReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// Images of the created containers, defaults to the published
+	// ones.
+	Images *common.Images
+
+	// Interval between two Server List Pings of a running proxy,
+	// defaults to 30 seconds.
+	PingInterval time.Duration
+
+	// Pings the ready proxies apart from the reconciliations, created
+	// and started with the controller when not set.
+	Pinger *Pinger
 }
 
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 //+kubebuilder:rbac:groups=shulkermc.io,resources=proxies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=shulkermc.io,resources=proxies/status,verbs=get;update;patch
 
@@ -41,6 +68,13 @@ func (r *ProxyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, err
 	} else if k8serrors.IsNotFound(err) {
 		// No need to requeue if the resource no longer exists
+		r.Pinger.forget(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	// The Proxy waits for its Pod to be garbage collected, which
+	// must not be created again
+	if proxy.DeletionTimestamp != nil {
 		return ctrl.Result{}, nil
 	}
 
@@ -51,6 +85,7 @@ func (r *ProxyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	}, cluster)
 	if err != nil {
 		logger.Error(err, "Referenced MinecraftCluster does not exists")
+		recordClusterNotFoundEvent(r.Recorder, proxy, proxy.Spec.ClusterRef.Name, err)
 		return ctrl.Result{}, err
 	}
 
@@ -59,12 +94,8 @@ func (r *ProxyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		Scheme:   r.Scheme,
 		Client:   r.Client,
 		Ctx:      ctx,
-	}
-	builders, dirtyBuilders := resourceBuilder.ResourceBuilders()
-
-	err = ReconcileWithResourceBuilders(r.Client, ctx, builders, dirtyBuilders)
-	if err != nil {
-		return ctrl.Result{}, err
+		Images:   r.Images,
+		Cluster:  cluster,
 	}
 
 	pod := corev1.Pod{}
@@ -75,39 +106,147 @@ func (r *ProxyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	if err != nil && !k8serrors.IsNotFound(err) {
 		return ctrl.Result{}, err
 	}
+	podExists := err == nil
+
+	// A failed resolution holds back the Pod until it is created, an
+	// existing Pod is handled as usual
+	resolutionError, err := r.lockResources(&resourceBuilder, podExists)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if resolutionError != nil {
+		logger.Error(resolutionError, "Failed to resolve resources")
+		r.Recorder.Event(proxy, corev1.EventTypeWarning, "ResourceResolutionFailed", resolutionError.Error())
+		resourceResolutionFailuresTotal.WithLabelValues(append([]string{"Proxy"}, getProxyMetricLabels(proxy)...)...).Inc()
+		proxy.Status.SetCondition(shulkermciov1alpha1.ProxyResourcesResolvedCondition, metav1.ConditionFalse, "ResolutionFailed", resolutionError.Error())
+		if !podExists {
+			logger.Info("Holding back the Pod until its resources are resolved")
+			if err := r.Status().Update(ctx, proxy); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: resourceResolutionRetryInterval}, nil
+		}
+	} else {
+		builders, dirtyBuilders := resourceBuilder.ResourceBuilders()
+		err = ReconcileWithResourceBuilders(r.Client, ctx, builders, dirtyBuilders, r.Recorder, proxy)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		proxy.Status.SetCondition(shulkermciov1alpha1.ProxyResourcesResolvedCondition, metav1.ConditionTrue, "Resolved", "All resources are resolved")
+		proxy.Status.Resources, err = resourceBuilder.GetResolvedResources()
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	var lifecycle podLifecycle
+	if podExists {
+		lifecycle = getPodLifecycle(&pod, "proxy")
+	} else {
+		lifecycle = getPodLifecycle(nil, "proxy")
+	}
+	recordLastFailure(&proxy.Status.LastFailure, lifecycle.Failure)
+
+	// A draining proxy fails its readiness probe on purpose
+	if proxy.Annotations[shulkermciov1alpha1.ProxyDrainAnnotationName] == "true" && (lifecycle.Phase == shulkermciov1alpha1.PhaseReady || lifecycle.Phase == shulkermciov1alpha1.PhaseStarting) {
+		lifecycle.Phase = shulkermciov1alpha1.PhaseDraining
+		lifecycle.Message = "Proxy is draining and does not accept players"
+	}
+	proxy.Status.SetCondition(shulkermciov1alpha1.ProxyPhaseCondition, metav1.ConditionUnknown, string(lifecycle.Phase), lifecycle.Message)
 
 	if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded {
+		// Only a completed Pod counts as a restart, not a deleted one
+		if pod.DeletionTimestamp == nil {
+			r.Recorder.Eventf(proxy, corev1.EventTypeNormal, "PodEnded", "Pod %s has ended, deleting Proxy", pod.Name)
+			podRestartsTotal.WithLabelValues(append([]string{"Proxy"}, getProxyMetricLabels(proxy)...)...).Inc()
+		}
+
 		logger.Info("Pod is terminating, deleting Proxy")
 		err = r.Delete(ctx, proxy)
 		return ctrl.Result{}, err
 	}
 
-	var readyCondition metav1.Condition
+	previousReadyReason := ""
+	if previousReadyCondition := meta.FindStatusCondition(proxy.Status.Conditions, string(shulkermciov1alpha1.ProxyReadyCondition)); previousReadyCondition != nil {
+		previousReadyReason = previousReadyCondition.Reason
+	}
 
-	if err == nil {
-		readyCondition = proxy.Status.SetCondition(shulkermciov1alpha1.ProxyReadyCondition, metav1.ConditionFalse, "PodNotReady", "Pod is not ready")
+	if podExists {
+		proxy.Status.SetCondition(shulkermciov1alpha1.ProxyReadyCondition, metav1.ConditionFalse, "PodNotReady", "Pod is not ready")
 
-		for _, condition := range pod.Status.Conditions {
-			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
-				readyCondition = proxy.Status.SetCondition(shulkermciov1alpha1.ProxyReadyCondition, metav1.ConditionTrue, "PodReady", "Pod is ready")
+		if failure := lifecycle.Failure; failure != nil {
+			proxy.Status.SetCondition(shulkermciov1alpha1.ProxyReadyCondition, metav1.ConditionFalse, failure.Reason, failure.Message)
+			if previousReadyReason != failure.Reason {
+				r.Recorder.Event(proxy, corev1.EventTypeWarning, failure.Reason, failure.Message)
 			}
 		}
+
+		if lifecycle.Phase == shulkermciov1alpha1.PhaseReady {
+			proxy.Status.SetCondition(shulkermciov1alpha1.ProxyReadyCondition, metav1.ConditionTrue, "PodReady", "Pod is ready")
+		} else if lifecycle.Phase == shulkermciov1alpha1.PhaseDraining {
+			proxy.Status.SetCondition(shulkermciov1alpha1.ProxyReadyCondition, metav1.ConditionFalse, "Draining", lifecycle.Message)
+		}
 	} else {
-		readyCondition = proxy.Status.SetCondition(shulkermciov1alpha1.ProxyReadyCondition, metav1.ConditionUnknown, "PodNotExists", "Pod does not exists")
+		proxy.Status.SetCondition(shulkermciov1alpha1.ProxyReadyCondition, metav1.ConditionUnknown, "PodNotExists", "Pod does not exists")
 	}
 
-	if readyCondition.Status == metav1.ConditionTrue {
-		if proxy.Annotations[shulkermciov1alpha1.ProxyDrainAnnotationName] == "true" {
-			proxy.Status.SetCondition(shulkermciov1alpha1.ProxyPhaseCondition, metav1.ConditionUnknown, "Draining", "Proxy is drining and do not accept players")
-			proxy.Status.SetCondition(shulkermciov1alpha1.ProxyReadyCondition, metav1.ConditionFalse, "Draining", "Proxy is drining and do not accept players")
-		} else {
-			proxy.Status.SetCondition(shulkermciov1alpha1.ProxyPhaseCondition, metav1.ConditionUnknown, "Running", "Proxy is running")
-		}
+	// Draining proxies are still pinged, their players are the ones
+	// waiting to leave
+	result := ctrl.Result{}
+	if (lifecycle.Phase == shulkermciov1alpha1.PhaseReady || lifecycle.Phase == shulkermciov1alpha1.PhaseDraining) && pod.Status.PodIP != "" {
+		result.RequeueAfter = r.updatePingStatus(ctx, proxy, pod.Status.PodIP)
 	} else {
-		proxy.Status.SetCondition(shulkermciov1alpha1.ProxyPhaseCondition, metav1.ConditionUnknown, "Unknown", "Proxy status is unknown")
+		proxy.Status.OnlinePlayers = 0
+		proxy.Status.LastPingTime = nil
+	}
+	if resolutionError != nil && (result.RequeueAfter == 0 || result.RequeueAfter > resourceResolutionRetryInterval) {
+		result.RequeueAfter = resourceResolutionRetryInterval
+	}
+
+	return result, r.Status().Update(ctx, proxy)
+}
+
+// Locks the resources of the proxy in its status, unless the owning
+// deployment locked them already. The lock is kept as long as the Pod
+// exists, as it reports the resources the Pod was started with. A
+// failed resolution is returned apart as it must not stop the
+// reconciliation.
+func (r *ProxyReconciler) lockResources(resourceBuilder *resources.ProxyResourceBuilder, podExists bool) (*common.ResourceRefResolutionError, error) {
+	proxy := resourceBuilder.Instance
+	if len(proxy.Spec.LockedResources) > 0 {
+		return nil, nil
+	}
+	if podExists && proxy.Status.ResourcesLock != nil {
+		return nil, nil
+	}
+
+	resourcesLock, err := resourceBuilder.GetResourcesLock(getProxySpecHash(&proxy.Spec))
+	if resolutionError := getResourceRefResolutionError(err); resolutionError != nil {
+		return resolutionError, nil
+	} else if err != nil {
+		return nil, err
 	}
 
-	return ctrl.Result{}, r.Status().Update(ctx, proxy)
+	proxy.Status.ResourcesLock = resourcesLock
+	return nil, nil
+}
+
+// Pings the proxy at most once per interval and returns when to ping
+// it next.
+func (r *ProxyReconciler) updatePingStatus(ctx context.Context, proxy *shulkermciov1alpha1.Proxy, podIP string) time.Duration {
+	pingStatus, requeueAfter := r.Pinger.pingAtInterval(ctx, proxy, net.JoinHostPort(podIP, "25577"), &proxy.Status.LastPingTime, r.PingInterval)
+	if pingStatus != nil {
+		proxy.Status.OnlinePlayers = pingStatus.OnlinePlayers
+	}
+	return requeueAfter
+}
+
+func getProxySpecHash(spec *shulkermciov1alpha1.ProxySpec) string {
+	hasher := fnv.New32a()
+	hashutil.DeepHashObject(hasher, *spec)
+
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
 }
 
 func (r *ProxyReconciler) getProxy(ctx context.Context, namespacedName types.NamespacedName) (*shulkermciov1alpha1.Proxy, error) {
@@ -118,9 +257,26 @@ func (r *ProxyReconciler) getProxy(ctx context.Context, namespacedName types.Nam
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ProxyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Images == nil {
+		r.Images = common.NewDefaultImages()
+	}
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("proxy-controller")
+	}
+	if r.PingInterval <= 0 {
+		r.PingInterval = defaultPingInterval
+	}
+	if r.Pinger == nil {
+		r.Pinger = NewPinger()
+	}
+	if err := mgr.Add(r.Pinger); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&shulkermciov1alpha1.Proxy{}).
 		Owns(&corev1.Pod{}).
 		Owns(&corev1.ConfigMap{}).
+		Watches(&source.Channel{Source: r.Pinger.Events}, &handler.EnqueueRequestForObject{}).
 		Complete(r)
 }
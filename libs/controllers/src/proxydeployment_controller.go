@@ -9,13 +9,16 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"sort"
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
 	hashutil "k8s.io/kubernetes/pkg/util/hash"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,9 +33,11 @@ import (
 // ProxyDeploymentReconciler reconciles a ProxyDeployment object
 type ProxyDeploymentReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=shulkermc.io,resources=proxies,verbs=get;list;watch;create;update
 //+kubebuilder:rbac:groups=shulkermc.io,resources=proxydeployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=shulkermc.io,resources=proxydeployments/status,verbs=get;update;patch
@@ -57,16 +62,19 @@ func (r *ProxyDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}, cluster)
 	if err != nil {
 		logger.Error(err, "Referenced MinecraftCluster does not exists")
+		recordClusterNotFoundEvent(r.Recorder, proxyDeployment, proxyDeployment.Spec.ClusterRef.Name, err)
 		return ctrl.Result{}, err
 	}
 
 	resourceBuilder := resources.ProxyDeploymentResourceBuilder{
 		Instance: proxyDeployment,
 		Scheme:   r.Scheme,
+		Client:   r.Client,
+		Ctx:      ctx,
 	}
 	builders, dirtyBuilders := resourceBuilder.ResourceBuilders()
 
-	err = ReconcileWithResourceBuilders(r.Client, ctx, builders, dirtyBuilders)
+	err = ReconcileWithResourceBuilders(r.Client, ctx, builders, dirtyBuilders, r.Recorder, proxyDeployment)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -77,14 +85,49 @@ func (r *ProxyDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}
 
 	templateHash := getProxyTemplateHash(&proxyDeployment.Spec.Template)
-	var oldProxies, matchingProxies []*shulkermciov1alpha1.Proxy
+
+	// New proxies are held back until the resources of the template
+	// are resolved, the existing ones are left untouched
+	resourcesLock, err := resourceBuilder.GetResourcesLock(templateHash)
+	if resolutionError := getResourceRefResolutionError(err); resolutionError != nil {
+		logger.Error(resolutionError, "Failed to resolve resources of the template, holding back new proxies")
+		r.Recorder.Event(proxyDeployment, corev1.EventTypeWarning, "ResourceResolutionFailed", resolutionError.Error())
+		resourceResolutionFailuresTotal.WithLabelValues("ProxyDeployment", proxyDeployment.Namespace, proxyDeployment.Spec.ClusterRef.Name, proxyDeployment.Name).Inc()
+		proxyDeployment.Status.SetCondition(shulkermciov1alpha1.ProxyDeploymentResourcesResolvedCondition, metav1.ConditionFalse, "ResolutionFailed", resolutionError.Error())
+		if err := r.Status().Update(ctx, proxyDeployment); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: resourceResolutionRetryInterval}, nil
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	proxyDeployment.Status.SetCondition(shulkermciov1alpha1.ProxyDeploymentResourcesResolvedCondition, metav1.ConditionTrue, "Resolved", "All resources of the template are resolved")
+	if proxyDeployment.Status.ResourcesLock != resourcesLock {
+		// The lock is saved before any proxy uses it, resolving it
+		// again could give other resources
+		proxyDeployment.Status.ResourcesLock = resourcesLock
+		if err := r.Status().Update(ctx, proxyDeployment); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	var currentProxies, oldProxies []*shulkermciov1alpha1.Proxy
+	var drainingReplicas int
 	var availableReplicas, unavailableReplicas uint
 
-	for _, proxy := range allProxies.Items {
-		if proxy.Labels[shulkermciov1alpha1.ProxyDeploymentTemplateHashLabelName] == templateHash {
-			matchingProxies = append(matchingProxies, &proxy)
-		} else {
-			oldProxies = append(oldProxies, &proxy)
+	for i := range allProxies.Items {
+		proxy := &allProxies.Items[i]
+
+		// Draining proxies are leaving the deployment and must not be
+		// counted as replicas anymore
+		if proxy.DeletionTimestamp == nil {
+			if isProxyDraining(proxy) {
+				drainingReplicas += 1
+			} else if proxy.Labels[shulkermciov1alpha1.ProxyDeploymentTemplateHashLabelName] == templateHash {
+				currentProxies = append(currentProxies, proxy)
+			} else {
+				oldProxies = append(oldProxies, proxy)
+			}
 		}
 
 		for _, condition := range proxy.Status.Conditions {
@@ -98,53 +141,16 @@ func (r *ProxyDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		}
 	}
 
-	if len(matchingProxies) < int(proxyDeployment.Spec.Replicas) {
-		proxiesToCreate := int(proxyDeployment.Spec.Replicas) - len(matchingProxies)
-
-		for i := 0; i < proxiesToCreate; i += 1 {
-			proxyId := common.RandomResourceId(6)
-			proxy := shulkermciov1alpha1.Proxy{}
-
-			labels := r.getProxyLabels(proxyDeployment)
-			for k, v := range proxyDeployment.Spec.Template.Labels {
-				labels[k] = v
-			}
-			labels[shulkermciov1alpha1.ProxyDeploymentTemplateHashLabelName] = templateHash
-
-			proxy.Namespace = proxyDeployment.Namespace
-			proxy.Name = fmt.Sprintf("%s-%s-%s", proxyDeployment.Name, templateHash, proxyId)
-			proxy.Labels = labels
-			proxy.Spec = proxyDeployment.Spec.Template.Spec
-			proxy.Spec.ClusterRef = proxyDeployment.Spec.ClusterRef
-			proxy.Spec.Configuration = proxyDeployment.Spec.Template.Spec.Configuration
-			proxy.Spec.Configuration.ExistingConfigMapName = resourceBuilder.GetConfigMapName()
-
-			if err := controllerutil.SetControllerReference(proxyDeployment, &proxy, r.Scheme); err != nil {
-				err = fmt.Errorf("failed setting controller reference for Proxy: %v", err)
-				return ctrl.Result{}, err
-			}
+	readyCurrentProxies := countReadyProxies(currentProxies)
+	r.setProgressingCondition(proxyDeployment, len(currentProxies), readyCurrentProxies, len(oldProxies))
 
-			err = r.Create(ctx, &proxy)
-			if err != nil {
-				return ctrl.Result{}, err
-			}
-		}
+	if proxyDeployment.Spec.Strategy.GetType() == shulkermciov1alpha1.DeploymentStrategyRecreate {
+		err = r.recreateProxies(ctx, proxyDeployment, &resourceBuilder, templateHash, currentProxies, oldProxies)
+	} else {
+		err = r.rollProxies(ctx, proxyDeployment, &resourceBuilder, templateHash, currentProxies, oldProxies)
 	}
-
-	if len(oldProxies) > 0 {
-		for _, proxy := range oldProxies {
-			if proxy.Annotations == nil {
-				proxy.Annotations = make(map[string]string)
-			}
-
-			if proxy.Annotations[shulkermciov1alpha1.ProxyDrainAnnotationName] != "true" {
-				proxy.Annotations[shulkermciov1alpha1.ProxyDrainAnnotationName] = "true"
-				err = r.Update(ctx, proxy)
-				if err != nil {
-					return ctrl.Result{}, err
-				}
-			}
-		}
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
 	selector, err := metav1.LabelSelectorAsSelector(resourceBuilder.GetPodSelector())
@@ -152,7 +158,9 @@ func (r *ProxyDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, err
 	}
 
-	proxyDeployment.Status.Replicas = proxyDeployment.Spec.Replicas
+	proxyDeployment.Status.Replicas = int32(len(currentProxies) + len(oldProxies))
+	proxyDeployment.Status.UpdatedReplicas = int32(len(currentProxies))
+	proxyDeployment.Status.DrainingReplicas = int32(drainingReplicas)
 	proxyDeployment.Status.AvailableReplicas = int32(availableReplicas)
 	proxyDeployment.Status.UnavailableReplicas = int32(unavailableReplicas)
 	proxyDeployment.Status.Selector = selector.String()
@@ -166,6 +174,140 @@ func (r *ProxyDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	return ctrl.Result{}, r.Status().Update(ctx, proxyDeployment)
 }
 
+// Replaces the outdated Proxies step by step. An outdated Proxy is
+// only drained once a new Proxy became ready to take its place, and
+// never when it would bring the deployment below the desired replicas
+// minus the unavailable budget.
+func (r *ProxyDeploymentReconciler) rollProxies(ctx context.Context, deployment *shulkermciov1alpha1.ProxyDeployment, resourceBuilder *resources.ProxyDeploymentResourceBuilder, templateHash string, currentProxies []*shulkermciov1alpha1.Proxy, oldProxies []*shulkermciov1alpha1.Proxy) error {
+	desiredReplicas := int(deployment.Spec.Replicas)
+	maxSurge, maxUnavailable, err := resolveRollingUpdateBounds(&deployment.Spec.Strategy, deployment.Spec.Replicas)
+	if err != nil {
+		return err
+	}
+
+	// Ready outdated Proxies are only drained once new ones are
+	// ready, which could never happen without creating one first
+	if maxSurge == 0 {
+		maxSurge = 1
+	}
+
+	if len(currentProxies) < desiredReplicas {
+		proxiesToCreate := desiredReplicas - len(currentProxies)
+		surgeRoom := desiredReplicas + maxSurge - len(currentProxies) - len(oldProxies)
+		if surgeRoom < proxiesToCreate {
+			proxiesToCreate = surgeRoom
+		}
+
+		if err := r.createProxies(ctx, deployment, resourceBuilder, templateHash, proxiesToCreate); err != nil {
+			return err
+		}
+	} else if err := r.scaleDownProxies(ctx, deployment, currentProxies); err != nil {
+		return err
+	}
+
+	if len(oldProxies) == 0 {
+		return nil
+	}
+
+	proxiesToDrain := getOutdatedProxiesToDrain(desiredReplicas, maxUnavailable, currentProxies, oldProxies)
+	return r.drainProxies(ctx, deployment, proxiesToDrain, "its template is outdated")
+}
+
+// Drains all the outdated Proxies at once and creates the new ones.
+func (r *ProxyDeploymentReconciler) recreateProxies(ctx context.Context, deployment *shulkermciov1alpha1.ProxyDeployment, resourceBuilder *resources.ProxyDeploymentResourceBuilder, templateHash string, currentProxies []*shulkermciov1alpha1.Proxy, oldProxies []*shulkermciov1alpha1.Proxy) error {
+	if err := r.drainProxies(ctx, deployment, oldProxies, "its template is outdated"); err != nil {
+		return err
+	}
+
+	if len(currentProxies) < int(deployment.Spec.Replicas) {
+		return r.createProxies(ctx, deployment, resourceBuilder, templateHash, int(deployment.Spec.Replicas)-len(currentProxies))
+	}
+
+	return r.scaleDownProxies(ctx, deployment, currentProxies)
+}
+
+// Drains the surplus Proxies when there are more than the desired
+// replicas. Draining Proxies are not counted as replicas, so they can
+// finish serving their players while the deployment has settled.
+func (r *ProxyDeploymentReconciler) scaleDownProxies(ctx context.Context, deployment *shulkermciov1alpha1.ProxyDeployment, currentProxies []*shulkermciov1alpha1.Proxy) error {
+	surplus := len(currentProxies) - int(deployment.Spec.Replicas)
+	if surplus <= 0 {
+		return nil
+	}
+
+	sortProxiesForDrain(currentProxies)
+	return r.drainProxies(ctx, deployment, currentProxies[:surplus], "the deployment is scaling down")
+}
+
+func (r *ProxyDeploymentReconciler) createProxies(ctx context.Context, deployment *shulkermciov1alpha1.ProxyDeployment, resourceBuilder *resources.ProxyDeploymentResourceBuilder, templateHash string, count int) error {
+	for i := 0; i < count; i += 1 {
+		proxyId := common.RandomResourceId(6)
+		proxy := shulkermciov1alpha1.Proxy{}
+
+		labels := r.getProxyLabels(deployment)
+		for k, v := range deployment.Spec.Template.Labels {
+			labels[k] = v
+		}
+		labels[shulkermciov1alpha1.ProxyDeploymentTemplateHashLabelName] = templateHash
+
+		proxy.Namespace = deployment.Namespace
+		proxy.Name = fmt.Sprintf("%s-%s-%s", deployment.Name, templateHash, proxyId)
+		proxy.Labels = labels
+		proxy.Spec = deployment.Spec.Template.Spec
+		proxy.Spec.ClusterRef = deployment.Spec.ClusterRef
+		proxy.Spec.Configuration = deployment.Spec.Template.Spec.Configuration
+		proxy.Spec.Configuration.ExistingConfigMapName = resourceBuilder.GetConfigMapName()
+		proxy.Spec.LockedResources = deployment.Status.ResourcesLock.Resources
+
+		if err := controllerutil.SetControllerReference(deployment, &proxy, r.Scheme); err != nil {
+			return fmt.Errorf("failed setting controller reference for Proxy: %v", err)
+		}
+
+		if err := r.Create(ctx, &proxy); err != nil {
+			return err
+		}
+		r.Recorder.Eventf(deployment, corev1.EventTypeNormal, "SuccessfulCreate", "Created Proxy %s", proxy.Name)
+	}
+
+	return nil
+}
+
+func (r *ProxyDeploymentReconciler) drainProxies(ctx context.Context, deployment *shulkermciov1alpha1.ProxyDeployment, proxies []*shulkermciov1alpha1.Proxy, reason string) error {
+	logger := log.FromContext(ctx)
+
+	for _, proxy := range proxies {
+		if proxy.Annotations == nil {
+			proxy.Annotations = make(map[string]string)
+		}
+
+		if proxy.Annotations[shulkermciov1alpha1.ProxyDrainAnnotationName] != "true" {
+			logger.Info("Draining Proxy", "proxy", proxy.Name)
+
+			proxy.Annotations[shulkermciov1alpha1.ProxyDrainAnnotationName] = "true"
+			if err := r.Update(ctx, proxy); err != nil {
+				return err
+			}
+			r.Recorder.Eventf(deployment, corev1.EventTypeNormal, "Draining", "Draining Proxy %s as %s", proxy.Name, reason)
+		}
+	}
+
+	return nil
+}
+
+func (r *ProxyDeploymentReconciler) setProgressingCondition(deployment *shulkermciov1alpha1.ProxyDeployment, currentReplicas int, readyCurrentReplicas int, oldReplicas int) {
+	desiredReplicas := int(deployment.Spec.Replicas)
+
+	if oldReplicas > 0 {
+		deployment.Status.SetCondition(shulkermciov1alpha1.ProxyDeploymentProgressingCondition, metav1.ConditionTrue, "RollingOut", fmt.Sprintf("%d outdated proxies are waiting to be drained", oldReplicas))
+	} else if currentReplicas != desiredReplicas {
+		deployment.Status.SetCondition(shulkermciov1alpha1.ProxyDeploymentProgressingCondition, metav1.ConditionTrue, "Scaling", fmt.Sprintf("Scaling from %d to %d proxies", currentReplicas, desiredReplicas))
+	} else if readyCurrentReplicas < desiredReplicas {
+		deployment.Status.SetCondition(shulkermciov1alpha1.ProxyDeploymentProgressingCondition, metav1.ConditionTrue, "WaitingForReadiness", fmt.Sprintf("%d of %d proxies are ready", readyCurrentReplicas, desiredReplicas))
+	} else {
+		deployment.Status.SetCondition(shulkermciov1alpha1.ProxyDeploymentProgressingCondition, metav1.ConditionFalse, "Complete", "All proxies are up to date and ready")
+	}
+}
+
 func (r *ProxyDeploymentReconciler) getProxyDeployment(ctx context.Context, namespacedName types.NamespacedName) (*shulkermciov1alpha1.ProxyDeployment, error) {
 	proxyDeployment := &shulkermciov1alpha1.ProxyDeployment{}
 	err := r.Get(ctx, namespacedName, proxyDeployment)
@@ -175,7 +317,7 @@ func (r *ProxyDeploymentReconciler) getProxyDeployment(ctx context.Context, name
 func (r *ProxyDeploymentReconciler) getProxyLabels(deployment *shulkermciov1alpha1.ProxyDeployment) map[string]string {
 	labels := map[string]string{
 		"minecraftcluster.shulkermc.io/name": deployment.Spec.ClusterRef.Name,
-		"proxydeployment.shulkermc.io/name":  deployment.Name,
+		proxyDeploymentNameLabel:             deployment.Name,
 	}
 	return labels
 }
@@ -189,6 +331,10 @@ func (r *ProxyDeploymentReconciler) getAllProxies(ctx context.Context, deploymen
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ProxyDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("proxydeployment-controller")
+	}
+
 	err := mgr.GetFieldIndexer().IndexField(context.Background(), &shulkermciov1alpha1.ProxyDeployment{}, ".spec.clusterRef.name", func(object client.Object) []string {
 		proxyDeployment := object.(*shulkermciov1alpha1.ProxyDeployment)
 		return []string{proxyDeployment.Spec.ClusterRef.Name}
@@ -205,6 +351,81 @@ func (r *ProxyDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
+// Returns the outdated Proxies which can be drained without going
+// below the available replicas allowed by the strategy. The ones
+// which are not ready can be drained right away, the ready ones only
+// once as many new Proxies are ready to take their players.
+func getOutdatedProxiesToDrain(desiredReplicas int, maxUnavailable int, currentProxies []*shulkermciov1alpha1.Proxy, oldProxies []*shulkermciov1alpha1.Proxy) []*shulkermciov1alpha1.Proxy {
+	readyCurrentProxies := countReadyProxies(currentProxies)
+	minAvailable := desiredReplicas - maxUnavailable
+
+	drainBudget := readyCurrentProxies + len(oldProxies) - minAvailable
+	readyDrainBudget := readyCurrentProxies + countReadyProxies(oldProxies) - minAvailable
+
+	// Every new ready Proxy which is not already filling the place of
+	// a previously drained one allows to drain a ready outdated Proxy
+	if replacingBudget := readyCurrentProxies + len(oldProxies) - desiredReplicas; replacingBudget < readyDrainBudget {
+		readyDrainBudget = replacingBudget
+	}
+
+	sortProxiesForDrain(oldProxies)
+	var proxiesToDrain []*shulkermciov1alpha1.Proxy
+	for _, proxy := range oldProxies {
+		if drainBudget <= 0 {
+			break
+		}
+
+		if isProxyReady(proxy) {
+			if readyDrainBudget <= 0 {
+				break
+			}
+			readyDrainBudget -= 1
+		}
+
+		proxiesToDrain = append(proxiesToDrain, proxy)
+		drainBudget -= 1
+	}
+	return proxiesToDrain
+}
+
+// Sorts the Proxies so the best candidates for draining come first:
+// not ready proxies, then the ones with the fewest players, then the
+// newest ones.
+func sortProxiesForDrain(proxies []*shulkermciov1alpha1.Proxy) {
+	sort.SliceStable(proxies, func(i, j int) bool {
+		a, b := proxies[i], proxies[j]
+
+		aReady, bReady := isProxyReady(a), isProxyReady(b)
+		if aReady != bReady {
+			return !aReady
+		}
+
+		if a.Status.OnlinePlayers != b.Status.OnlinePlayers {
+			return a.Status.OnlinePlayers < b.Status.OnlinePlayers
+		}
+
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	})
+}
+
+func isProxyReady(proxy *shulkermciov1alpha1.Proxy) bool {
+	return meta.IsStatusConditionTrue(proxy.Status.Conditions, string(shulkermciov1alpha1.ProxyReadyCondition))
+}
+
+func isProxyDraining(proxy *shulkermciov1alpha1.Proxy) bool {
+	return proxy.Annotations[shulkermciov1alpha1.ProxyDrainAnnotationName] == "true"
+}
+
+func countReadyProxies(proxies []*shulkermciov1alpha1.Proxy) int {
+	count := 0
+	for _, proxy := range proxies {
+		if isProxyReady(proxy) {
+			count += 1
+		}
+	}
+	return count
+}
+
 func getProxyTemplateHash(template *shulkermciov1alpha1.ProxyTemplate) string {
 	hasher := fnv.New32a()
 	hashutil.DeepHashObject(hasher, *template)
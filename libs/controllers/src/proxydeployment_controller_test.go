@@ -0,0 +1,107 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("ProxyDeployment controller", func() {
+	newProxy := func(name string, ready bool, onlinePlayers int32, age time.Duration) *shulkermciov1alpha1.Proxy {
+		proxy := &shulkermciov1alpha1.Proxy{}
+		proxy.Name = name
+		proxy.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+		proxy.Status.OnlinePlayers = onlinePlayers
+
+		readyStatus := metav1.ConditionFalse
+		if ready {
+			readyStatus = metav1.ConditionTrue
+		}
+		proxy.Status.SetCondition(shulkermciov1alpha1.ProxyReadyCondition, readyStatus, "Test", "")
+		return proxy
+	}
+
+	getNames := func(proxies []*shulkermciov1alpha1.Proxy) []string {
+		names := make([]string, len(proxies))
+		for i, proxy := range proxies {
+			names[i] = proxy.Name
+		}
+		return names
+	}
+
+	Describe("sortProxiesForDrain", func() {
+		It("drains the not ready proxies first", func() {
+			proxies := []*shulkermciov1alpha1.Proxy{
+				newProxy("ready", true, 0, time.Hour),
+				newProxy("not-ready", false, 10, time.Hour),
+			}
+
+			sortProxiesForDrain(proxies)
+			Expect(getNames(proxies)).To(Equal([]string{"not-ready", "ready"}))
+		})
+
+		It("drains the proxies with the fewest players first", func() {
+			proxies := []*shulkermciov1alpha1.Proxy{
+				newProxy("crowded", true, 50, time.Hour),
+				newProxy("empty", true, 0, time.Hour),
+				newProxy("quiet", true, 3, time.Hour),
+			}
+
+			sortProxiesForDrain(proxies)
+			Expect(getNames(proxies)).To(Equal([]string{"empty", "quiet", "crowded"}))
+		})
+
+		It("drains the newest proxies first when they have as many players", func() {
+			proxies := []*shulkermciov1alpha1.Proxy{
+				newProxy("old", true, 5, 2*time.Hour),
+				newProxy("new", true, 5, time.Minute),
+			}
+
+			sortProxiesForDrain(proxies)
+			Expect(getNames(proxies)).To(Equal([]string{"new", "old"}))
+		})
+	})
+
+	Describe("getOutdatedProxiesToDrain", func() {
+		newProxies := func(prefix string, ready int, notReady int) []*shulkermciov1alpha1.Proxy {
+			var proxies []*shulkermciov1alpha1.Proxy
+			for i := 0; i < ready; i++ {
+				proxies = append(proxies, newProxy(fmt.Sprintf("%s-ready-%d", prefix, i), true, int32(i), time.Hour))
+			}
+			for i := 0; i < notReady; i++ {
+				proxies = append(proxies, newProxy(fmt.Sprintf("%s-not-ready-%d", prefix, i), false, 0, time.Hour))
+			}
+			return proxies
+		}
+
+		DescribeTable("drains outdated proxies without going below the available replicas",
+			func(desiredReplicas int, maxUnavailable int, readyCurrent int, notReadyCurrent int, readyOld int, notReadyOld int, expected []string) {
+				currentProxies := newProxies("current", readyCurrent, notReadyCurrent)
+				oldProxies := newProxies("old", readyOld, notReadyOld)
+
+				proxiesToDrain := getOutdatedProxiesToDrain(desiredReplicas, maxUnavailable, currentProxies, oldProxies)
+				Expect(getNames(proxiesToDrain)).To(Equal(expected))
+			},
+			Entry("waits for new ready proxies when none can be unavailable", 3, 0, 0, 1, 3, 0, []string{}),
+			Entry("drains as many proxies as new ones are ready", 3, 0, 2, 0, 3, 0, []string{"old-ready-0", "old-ready-1"}),
+			Entry("waits for new ready proxies even when some can be unavailable", 4, 1, 0, 0, 4, 0, []string{}),
+			Entry("drains not ready proxies before new ones are ready", 4, 1, 0, 0, 3, 1, []string{"old-not-ready-0"}),
+			Entry("drains no more ready proxies than new ones replace", 4, 1, 2, 0, 3, 0, []string{"old-ready-0"}),
+			Entry("drains the not ready proxies first", 3, 1, 1, 0, 2, 1, []string{"old-not-ready-0", "old-ready-0"}),
+			Entry("drains not ready proxies even when no ready one can be", 3, 0, 1, 0, 2, 1, []string{"old-not-ready-0"}),
+			Entry("never drains more proxies than outdated ones", 2, 1, 2, 0, 1, 0, []string{"old-ready-0"}),
+			Entry("never drains when every proxy is already needed", 3, 1, 0, 1, 2, 0, []string{}),
+		)
+	})
+})
@@ -7,16 +7,23 @@ package controllers
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	resources "github.com/iamblueslime/shulker/libs/resources/src"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	clientretry "k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-func ReconcileWithResourceBuilders(client client.Client, ctx context.Context, builders []resources.ResourceBuilder, dirtyBuilders []resources.ResourceBuilder) error {
+// Creates or updates the resources of the builders and deletes the
+// ones of the dirty builders, recording an Event on the owner for
+// each resource created or deleted.
+func ReconcileWithResourceBuilders(client client.Client, ctx context.Context, builders []resources.ResourceBuilder, dirtyBuilders []resources.ResourceBuilder, recorder record.EventRecorder, owner runtime.Object) error {
 	for _, builder := range builders {
 		resource, err := builder.Build()
 		if err != nil {
@@ -27,9 +34,13 @@ func ReconcileWithResourceBuilders(client client.Client, ctx context.Context, bu
 			var apiError error
 
 			if builder.CanBeUpdated() {
-				_, apiError = controllerutil.CreateOrUpdate(ctx, client, resource, func() error {
+				var result controllerutil.OperationResult
+				result, apiError = controllerutil.CreateOrUpdate(ctx, client, resource, func() error {
 					return builder.Update(resource)
 				})
+				if result == controllerutil.OperationResultCreated {
+					recordResourceEvent(recorder, owner, "SuccessfulCreate", "Created", resource)
+				}
 			} else {
 				existingResource := resource
 				apiError = client.Get(ctx, types.NamespacedName{
@@ -43,7 +54,10 @@ func ReconcileWithResourceBuilders(client client.Client, ctx context.Context, bu
 						return apiError
 					}
 
-					return client.Create(ctx, resource)
+					if apiError = client.Create(ctx, resource); apiError == nil {
+						recordResourceEvent(recorder, owner, "SuccessfulCreate", "Created", resource)
+					}
+					return apiError
 				}
 			}
 
@@ -68,7 +82,9 @@ func ReconcileWithResourceBuilders(client client.Client, ctx context.Context, bu
 		}, existingResource)
 
 		if apiError == nil {
-			apiError = client.Delete(ctx, existingResource)
+			if apiError = client.Delete(ctx, existingResource); apiError == nil {
+				recordResourceEvent(recorder, owner, "SuccessfulDelete", "Deleted", existingResource)
+			}
 			return apiError
 		} else if !k8serrors.IsNotFound(apiError) {
 			return apiError
@@ -77,3 +93,17 @@ func ReconcileWithResourceBuilders(client client.Client, ctx context.Context, bu
 
 	return nil
 }
+
+// Time to wait before trying to resolve the resources of a Pod
+// again. The referenced Secrets are not watched.
+const resourceResolutionRetryInterval = 30 * time.Second
+
+// Returns the ResourceRef resolution error wrapped in the given
+// error, if any.
+func getResourceRefResolutionError(err error) *resources.ResourceRefResolutionError {
+	var resolutionError *resources.ResourceRefResolutionError
+	if errors.As(err, &resolutionError) {
+		return resolutionError
+	}
+	return nil
+}
@@ -6,15 +6,19 @@ SPDX-License-Identifier: GPL-3.0-or-later
 package controllers
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -39,6 +43,12 @@ func TestAPIs(t *testing.T) {
 var _ = BeforeSuite(func() {
 	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
 
+	// Most specs do not need an API server, the ones which do are
+	// skipped when the envtest binaries are not installed
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		return
+	}
+
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths:     []string{filepath.Join("..", "config", "crd", "bases")},
@@ -63,7 +73,32 @@ var _ = BeforeSuite(func() {
 })
 
 var _ = AfterSuite(func() {
+	if testEnv == nil {
+		return
+	}
+
 	By("tearing down the test environment")
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
 })
+
+const testNamespace = "default"
+const testClusterName = "my-cluster"
+
+// Returns a client knowing the given objects and the cluster they
+// are all part of, for the specs which do not need an API server.
+func newFakeClient(objects ...client.Object) (client.Client, *runtime.Scheme) {
+	fakeScheme := runtime.NewScheme()
+	Expect(scheme.AddToScheme(fakeScheme)).To(Succeed())
+	Expect(shulkermciov1alpha1.AddToScheme(fakeScheme)).To(Succeed())
+
+	cluster := &shulkermciov1alpha1.MinecraftCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testClusterName},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(fakeScheme).
+		WithObjects(append(objects, cluster)...).
+		Build()
+	return fakeClient, fakeScheme
+}
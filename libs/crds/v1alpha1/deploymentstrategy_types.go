@@ -0,0 +1,65 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +kubebuilder:validation:Enum=RollingUpdate;Recreate
+type DeploymentStrategyType string
+
+const (
+	// Replace the outdated replicas progressively with new ones.
+	DeploymentStrategyRollingUpdate DeploymentStrategyType = "RollingUpdate"
+
+	// Remove all the outdated replicas before creating new ones.
+	DeploymentStrategyRecreate DeploymentStrategyType = "Recreate"
+)
+
+var DefaultRollingUpdateMaxSurge = intstr.FromString("25%")
+var DefaultRollingUpdateMaxUnavailable = intstr.FromString("25%")
+
+// Describes how to replace existing replicas with new ones when
+// the template changes.
+type DeploymentStrategy struct {
+	// Type of strategy to use. Can be "RollingUpdate" or "Recreate".
+	// Defaults to RollingUpdate.
+	//+optional
+	//+kubebuilder:default=RollingUpdate
+	Type DeploymentStrategyType `json:"type,omitempty"`
+
+	// Parameters of the rolling update. Only used when type is
+	// RollingUpdate.
+	//+optional
+	RollingUpdate *RollingUpdateDeploymentStrategy `json:"rollingUpdate,omitempty"`
+}
+
+type RollingUpdateDeploymentStrategy struct {
+	// Maximum number of replicas that can be unavailable during the
+	// update. Can be an absolute number or a percentage of the
+	// desired replicas, rounded down. Defaults to 25%.
+	//+optional
+	//+kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// Maximum number of replicas that can be created above the
+	// desired replicas during the update. Can be an absolute number
+	// or a percentage of the desired replicas, rounded up. Defaults
+	// to 25%.
+	//+optional
+	//+kubebuilder:validation:XIntOrString
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+}
+
+// Returns the type of the strategy, falling back to RollingUpdate
+// when not set.
+func (s *DeploymentStrategy) GetType() DeploymentStrategyType {
+	if s.Type == "" {
+		return DeploymentStrategyRollingUpdate
+	}
+	return s.Type
+}
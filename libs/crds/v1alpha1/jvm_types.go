@@ -0,0 +1,95 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// +kubebuilder:validation:Enum=Percentage;Fixed
+type JvmHeapPolicy string
+
+const (
+	// Give the heap a share of the memory of the container.
+	JvmHeapPercentage JvmHeapPolicy = "Percentage"
+
+	// Give the heap a fixed size.
+	JvmHeapFixed JvmHeapPolicy = "Fixed"
+)
+
+// +kubebuilder:validation:Enum=None;Aikar;Velocity
+type JvmFlagsPreset string
+
+const (
+	// No flag other than the heap sizing ones.
+	JvmFlagsNone JvmFlagsPreset = "None"
+
+	// Aikar's G1 flags, tuned for Paper and its forks.
+	JvmFlagsAikar JvmFlagsPreset = "Aikar"
+
+	// The flags recommended by Velocity for proxies.
+	JvmFlagsVelocity JvmFlagsPreset = "Velocity"
+)
+
+// Defines how the JVM running the server or proxy is tuned.
+type JvmSpec struct {
+	// How the size of the heap is chosen. With Percentage, the
+	// heap takes heapPercentage of the memory limit of the container,
+	// or of its memory request when it has no limit. With Fixed, it
+	// takes heapSize. Defaults to Percentage.
+	//+optional
+	//+kubebuilder:default=Percentage
+	HeapPolicy JvmHeapPolicy `json:"heapPolicy,omitempty"`
+
+	// Share of the memory of the container given to the heap, the
+	// rest being left to the JVM itself. Defaults to 75.
+	//+optional
+	//+kubebuilder:default=75
+	//+kubebuilder:validation:Minimum=10
+	//+kubebuilder:validation:Maximum=95
+	HeapPercentage int32 `json:"heapPercentage,omitempty"`
+
+	// Size of the heap when heapPolicy is Fixed. Must be lower than
+	// the memory limit of the container.
+	//+optional
+	HeapSize *resource.Quantity `json:"heapSize,omitempty"`
+
+	// Named set of garbage collection flags to run the JVM with.
+	// Defaults to Aikar for servers and Velocity for proxies.
+	//+optional
+	FlagsPreset JvmFlagsPreset `json:"flagsPreset,omitempty"`
+
+	// Extra arguments to give to the JVM, after the ones computed by
+	// Shulker.
+	//+optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+
+	// Major version of Java to run, which selects the tag of the
+	// image. Defaults to the one required by the Minecraft version
+	// for servers using the default image, and to the one of the
+	// image otherwise.
+	//+optional
+	//+kubebuilder:validation:Pattern=`^[0-9]+$`
+	JavaVersion string `json:"javaVersion,omitempty"`
+}
+
+// Returns the heap policy, falling back to Percentage when not set.
+func (s *JvmSpec) GetHeapPolicy() JvmHeapPolicy {
+	if s == nil || s.HeapPolicy == "" {
+		return JvmHeapPercentage
+	}
+	return s.HeapPolicy
+}
+
+const DefaultJvmHeapPercentage = 75
+
+// Returns the heap percentage, falling back to 75 when not set.
+func (s *JvmSpec) GetHeapPercentage() int32 {
+	if s == nil || s.HeapPercentage == 0 {
+		return DefaultJvmHeapPercentage
+	}
+	return s.HeapPercentage
+}
@@ -8,7 +8,9 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // MinecraftServerSpec defines the desired state of MinecraftServer
@@ -33,6 +35,31 @@ type MinecraftServerSpec struct {
 	// Overrides for values to be injected in the created Pod
 	// of this MinecraftServer.
 	PodOverrides *MinecraftServerPodOverridesSpec `json:"podOverrides,omitempty"`
+
+	// Tuning of the JVM running the server, its heap being sized from
+	// the memory resources of the Pod.
+	//+optional
+	Jvm *JvmSpec `json:"jvm,omitempty"`
+
+	// Probes checking the health of the server.
+	//+optional
+	Probes *ProbesSpec `json:"probes,omitempty"`
+
+	// Persistent storage of the server data. When not set, the data
+	// is lost when the Pod goes away.
+	//+optional
+	Persistence *MinecraftServerPersistenceSpec `json:"persistence,omitempty"`
+
+	// Backups of the worlds of the server to an S3-compatible
+	// storage.
+	//+optional
+	Backup *MinecraftServerBackupSpec `json:"backup,omitempty"`
+
+	// Resources locked by the owning deployment, used instead of
+	// resolving the ResourceRefs of the configuration. Set by the
+	// operator, must not be set in deployment templates.
+	//+optional
+	LockedResources []LockedResourceRef `json:"lockedResources,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=Paper;Bukkit;Spigot;Pufferfish;Forge;Fabric;Quilt
@@ -75,7 +102,7 @@ type MinecraftServerConfigurationSpec struct {
 	ExistingConfigMapName string `json:"existingConfigMapName,omitempty"`
 
 	// Reference to a world to download and extract. Gzipped tarball
-	// only.
+	// or zip archive.
 	//+optional
 	World *ResourceRef `json:"world,omitempty"`
 
@@ -83,8 +110,14 @@ type MinecraftServerConfigurationSpec struct {
 	//+optional
 	Plugins []ResourceRef `json:"plugins,omitempty"`
 
+	// List of references to mods to download. Only supported by
+	// the Forge, Fabric and Quilt channels.
+	//+optional
+	Mods []ResourceRef `json:"mods,omitempty"`
+
 	// List of optional references to patch archives to download
-	// and extract at the root of the server. Gzippied tarballs only.
+	// and extract at the root of the server. Gzipped tarballs or
+	// zip archives.
 	//+optional
 	Patches []ResourceRef `json:"patches,omitempty"`
 
@@ -108,11 +141,142 @@ type MinecraftServerConfigurationSpec struct {
 	ServerProperties map[string]string `json:"serverProperties,omitempty"`
 
 	// Type of forwarding the proxies are using between themselves and
-	// this MinecraftServer.
-	//+kubebuilder:default=Velocity
+	// this MinecraftServer. Defaults to the mode required by the
+	// proxies of the cluster, or Velocity if there is none.
+	//+optional
 	ProxyForwardingMode MincraftServerConfigurationProxyForwardingMode `json:"proxyForwardingMode,omitempty"`
 }
 
+const DefaultMinecraftServerMaxPlayers = 20
+
+// Returns the maximum number of players, falling back to the default
+// when not set.
+func (s *MinecraftServerConfigurationSpec) GetMaxPlayers() int {
+	if s.MaxPlayers == nil {
+		return DefaultMinecraftServerMaxPlayers
+	}
+	return *s.MaxPlayers
+}
+
+// Returns the proxy forwarding mode, falling back to Velocity when
+// not set.
+func (s *MinecraftServerConfigurationSpec) GetProxyForwardingMode() MincraftServerConfigurationProxyForwardingMode {
+	if s.ProxyForwardingMode == "" {
+		return MincraftServerConfigurationProxyForwardingModeVelocity
+	}
+	return s.ProxyForwardingMode
+}
+
+// +kubebuilder:validation:Enum=Retain;Delete
+type PersistentVolumeClaimRetentionPolicyType string
+
+const (
+	// Keep the PersistentVolumeClaim.
+	RetainPersistentVolumeClaimRetentionPolicyType PersistentVolumeClaimRetentionPolicyType = "Retain"
+
+	// Delete the PersistentVolumeClaim.
+	DeletePersistentVolumeClaimRetentionPolicyType PersistentVolumeClaimRetentionPolicyType = "Delete"
+)
+
+// Describes the PersistentVolumeClaim storing the data of the server,
+// mounted at /data in the created Pod.
+type MinecraftServerPersistenceSpec struct {
+	// Name of the StorageClass to request for the volume. Defaults
+	// to the default StorageClass of the Kubernetes cluster.
+	//+optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Size of the volume.
+	//+kubebuilder:validation:Required
+	Size resource.Quantity `json:"size"`
+
+	// Describes the lifecycle of the PersistentVolumeClaim. Changes
+	// only apply to the claims created afterwards.
+	//+optional
+	RetentionPolicy MinecraftServerPersistenceRetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
+type MinecraftServerPersistenceRetentionPolicy struct {
+	// What happens to the PersistentVolumeClaim when the
+	// MinecraftServer, or the MinecraftServerDeployment owning it,
+	// is deleted. Defaults to Retain.
+	//+optional
+	//+kubebuilder:default=Retain
+	WhenDeleted PersistentVolumeClaimRetentionPolicyType `json:"whenDeleted,omitempty"`
+
+	// What happens to the PersistentVolumeClaim when a
+	// MinecraftServerDeployment removes the MinecraftServer, because
+	// of a scale down or an update. Like the ones of a StatefulSet,
+	// the replicas of a MinecraftServerDeployment are named after
+	// their index and a retained claim is given to the next replica
+	// taking the same index. Has no effect on MinecraftServers which
+	// are not part of a MinecraftServerDeployment. Defaults to
+	// Retain.
+	//+optional
+	//+kubebuilder:default=Retain
+	WhenScaled PersistentVolumeClaimRetentionPolicyType `json:"whenScaled,omitempty"`
+}
+
+// Describes how the worlds of the server are backed up. Backups are
+// gzipped tarballs with the world directories at their root, which
+// can be given back as the world of a MinecraftServer.
+type MinecraftServerBackupSpec struct {
+	// Cron expression of the backups, e.g. "0 */6 * * *". When not
+	// set, no periodic backup is taken.
+	//+optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Number of backups to keep in the bucket, the oldest ones being
+	// deleted first. Keeps all of them when not set.
+	//+optional
+	//+kubebuilder:validation:Minimum=0
+	Retention int32 `json:"retention,omitempty"`
+
+	// Whether to take a last backup when the server stops.
+	//+optional
+	OnShutdown bool `json:"onShutdown,omitempty"`
+
+	// Bucket where the backups are stored.
+	//+kubebuilder:validation:Required
+	Bucket MinecraftServerBackupBucketSpec `json:"bucket"`
+}
+
+type MinecraftServerBackupBucketSpec struct {
+	// URL of the S3-compatible storage, e.g.
+	// https://s3.eu-west-3.amazonaws.com or http://minio:9000.
+	//+kubebuilder:validation:Required
+	Endpoint string `json:"endpoint"`
+
+	// Region of the bucket. Defaults to us-east-1.
+	//+optional
+	Region string `json:"region,omitempty"`
+
+	// Name of the bucket.
+	//+kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Prefix of the keys of the backups. Defaults to
+	// <namespace>/<name of the MinecraftServer>.
+	//+optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Name of a Secret containing the credentials to access the
+	// bucket, in its accessKeyId and secretAccessKey keys.
+	//+kubebuilder:validation:Required
+	CredentialsSecretName string `json:"credentialsSecretName"`
+}
+
+const DefaultMinecraftServerBackupBucketRegion = "us-east-1"
+
+// Returns the region of the bucket, falling back to the default when
+// not set.
+func (s *MinecraftServerBackupBucketSpec) GetRegion() string {
+	if s.Region == "" {
+		return DefaultMinecraftServerBackupBucketRegion
+	}
+	return s.Region
+}
+
 // Overrides for the created Pod of the server.
 type MinecraftServerPodOverridesSpec struct {
 	// Extra environment variables to add to the crated Pod.
@@ -124,27 +288,126 @@ type MinecraftServerPodOverridesSpec struct {
 	// Affinity scheduling rules to be applied on created Pod.
 	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 
-	// Name of the ServiceAccount to use.
+	// Image of the server container, e.g. to pin a digest or to run an
+	// image with baked-in plugins. Defaults to the image configured
+	// in the operator.
+	Image string `json:"image,omitempty"`
+
+	// Pull policy of the images of the created Pod. Defaults to the
+	// pull policy configured in the operator.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// References to Secrets to pull the images of the created Pod
+	// with, in addition to the ones configured in the operator.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Name of the ServiceAccount to use. Defaults to the one of
+	// the cluster.
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Extra labels to add to the created Pod. The labels set by
+	// Shulker cannot be overridden.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Extra annotations to add to the created Pod.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels of the nodes the created Pod can be scheduled on.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the created Pod.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// How the created Pods are spread across the topology domains.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Name of the PriorityClass of the created Pod.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Extra volumes to add to the created Pod.
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// Extra volume mounts to add to the server container.
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// Sidecar containers to add to the created Pod.
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+
+	// Strategic merge patch applied last to the created Pod, with
+	// metadata and spec fields, to set the fields Shulker does not
+	// model.
+	//+kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *runtime.RawExtension `json:"podTemplate,omitempty"`
 }
 
 type MinecraftServerStatusCondition string
 
 const (
-	MinecraftServerReadyCondition MinecraftServerStatusCondition = "Ready"
-	MinecraftServerPhaseCondition MinecraftServerStatusCondition = "Phase"
+	MinecraftServerReadyCondition             MinecraftServerStatusCondition = "Ready"
+	MinecraftServerPhaseCondition             MinecraftServerStatusCondition = "Phase"
+	MinecraftServerResourcesResolvedCondition MinecraftServerStatusCondition = "ResourcesResolved"
 )
 
 // MinecraftServerStatus defines the observed state of MinecraftServer
 type MinecraftServerStatus struct {
 	// Conditions represent the latest available observations of a
 	// MinecraftServer object.
-	// Known .status.conditions.type are: "Ready", "Phase".
+	// Known .status.conditions.type are: "Ready", "Phase",
+	// "ResourcesResolved".
 	//+kubebuilder:validation:Required
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 
 	// IP address of the Pod.
 	ServerIP string `json:"serverIP"`
+
+	// Number of players connected to the MinecraftServer, when
+	// known.
+	OnlinePlayers int32 `json:"onlinePlayers,omitempty"`
+
+	// Maximum number of players reported by the MinecraftServer.
+	//+optional
+	MaxPlayers int32 `json:"maxPlayers,omitempty"`
+
+	// Name of the version reported by the MinecraftServer, e.g.
+	// "Paper 1.20.4".
+	//+optional
+	ReportedVersion string `json:"reportedVersion,omitempty"`
+
+	// Number of the protocol spoken by the MinecraftServer.
+	//+optional
+	ProtocolVersion int32 `json:"protocolVersion,omitempty"`
+
+	// Message of the day of the MinecraftServer, without its
+	// formatting.
+	//+optional
+	Motd string `json:"motd,omitempty"`
+
+	// Round-trip time in milliseconds of the last ping of the
+	// MinecraftServer by the operator.
+	//+optional
+	LatencyMilliseconds int64 `json:"latencyMilliseconds,omitempty"`
+
+	// Last time the operator pinged the MinecraftServer, whether it
+	// answered or not.
+	//+optional
+	LastPingTime *metav1.Time `json:"lastPingTime,omitempty"`
+
+	// Resources of the MinecraftServer as they were last resolved.
+	//+optional
+	Resources []ResolvedResourceRefStatus `json:"resources,omitempty"`
+
+	// Resources of the MinecraftServer, resolved once per spec so their
+	// sources are not queried on every reconciliation, and kept as
+	// long as its Pod exists. Unused when the resources are locked
+	// by the owning deployment.
+	//+optional
+	ResourcesLock *ResourceRefLock `json:"resourcesLock,omitempty"`
+
+	// Last failure of the Pod of the MinecraftServer, kept after the Pod
+	// is replaced.
+	//+optional
+	LastFailure *LifecycleFailure `json:"lastFailure,omitempty"`
 }
 
 func (s *MinecraftServerStatus) SetCondition(condition MinecraftServerStatusCondition, status metav1.ConditionStatus, reason string, message string) metav1.Condition {
@@ -163,6 +426,11 @@ func (s *MinecraftServerStatus) SetCondition(condition MinecraftServerStatusCond
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 //+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.conditions[?(@.type==\"Phase\")].reason"
+//+kubebuilder:printcolumn:name="Players",type="integer",JSONPath=".status.onlinePlayers"
+//+kubebuilder:printcolumn:name="Max Players",type="integer",JSONPath=".status.maxPlayers"
+//+kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.reportedVersion",priority=1
+//+kubebuilder:printcolumn:name="Latency",type="integer",JSONPath=".status.latencyMilliseconds",priority=1
+//+kubebuilder:printcolumn:name="MOTD",type="string",JSONPath=".status.motd",priority=1
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 //+kubebuilder:resource:shortName={"skrms"},categories=all
 
@@ -6,13 +6,45 @@ SPDX-License-Identifier: GPL-3.0-or-later
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // MinecraftClusterSpec defines the desired state of MinecraftCluster
 type MinecraftClusterSpec struct {
+	// Cache of the resources downloaded by the servers and proxies of
+	// the cluster. When set, resources are fetched once from their
+	// source, verified, and served to every Pod by the cache.
+	//+optional
+	Cache *MinecraftClusterCacheSpec `json:"cache,omitempty"`
 }
 
+// Only the resources with a concrete version or a checksum, and
+// without credentials, go through the cache. The others may change
+// over time or must not be shared, they are still downloaded by each
+// Pod.
+type MinecraftClusterCacheSpec struct {
+	// Name of the StorageClass to request for the volume of the
+	// cache. Defaults to the default StorageClass of the Kubernetes
+	// cluster.
+	//+optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Size of the volume of the cache. The least recently used
+	// resources are evicted when it is nearly full.
+	//+kubebuilder:validation:Required
+	Size resource.Quantity `json:"size"`
+
+	// The desired compute resource requirements of the cache Pod.
+	// Defaults to requests of 100m of CPU and 128Mi of memory.
+	//+optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+const DefaultMinecraftClusterCacheCpuRequest = "100m"
+const DefaultMinecraftClusterCacheMemoryRequest = "128Mi"
+
 // MinecraftClusterStatus defines the observed state of MinecraftCluster
 type MinecraftClusterStatus struct {
 	// Number of proxies.
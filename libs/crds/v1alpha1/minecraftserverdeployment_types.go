@@ -12,6 +12,10 @@ import (
 
 const MinecraftServerDeploymentTemplateHashLabelName = "minecraftserverdeployment.shulkermc.io/template-hash"
 
+// Label holding the index of a replica of a MinecraftServerDeployment
+// with persistent data, which gives it the claim of the index.
+const MinecraftServerDeploymentReplicaIndexLabelName = "minecraftserverdeployment.shulkermc.io/replica-index"
+
 // MinecraftServerDeploymentSpec defines the desired state of MinecraftServerDeployment
 type MinecraftServerDeploymentSpec struct {
 	// Reference to a MinecraftCluster. Adding this will enroll
@@ -23,6 +27,11 @@ type MinecraftServerDeploymentSpec struct {
 	//+kubebuilder:validation:Required
 	Replicas int32 `json:"replicas,omitempty"`
 
+	// Strategy used to replace the existing MinecraftServers with
+	// new ones when the template changes.
+	//+optional
+	Strategy DeploymentStrategy `json:"strategy,omitempty"`
+
 	// Template defining the content of the created MinecraftServers.
 	//+kubebuilder:validation:Required
 	Template MinecraftServerTemplate `json:"template,omitempty"`
@@ -31,20 +40,27 @@ type MinecraftServerDeploymentSpec struct {
 type MinecraftServerDeploymentStatusCondition string
 
 const (
-	MinecraftServerDeploymentAvailableCondition MinecraftServerDeploymentStatusCondition = "Available"
+	MinecraftServerDeploymentAvailableCondition         MinecraftServerDeploymentStatusCondition = "Available"
+	MinecraftServerDeploymentProgressingCondition       MinecraftServerDeploymentStatusCondition = "Progressing"
+	MinecraftServerDeploymentResourcesResolvedCondition MinecraftServerDeploymentStatusCondition = "ResourcesResolved"
 )
 
 // MinecraftServerDeploymentStatus defines the observed state of MinecraftServerDeployment
 type MinecraftServerDeploymentStatus struct {
 	// Conditions represent the latest available observations of a
 	// MinecraftServerDeployment object.
-	// Known .status.conditions.type are: "Available".
+	// Known .status.conditions.type are: "Available", "Progressing",
+	// "ResourcesResolved".
 	//+kubebuilder:validation:Required
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 
 	// Number of total replicas in this MinecraftServerDeployment.
 	Replicas int32 `json:"replicas"`
 
+	// Number of replicas in this MinecraftServerDeployment matching
+	// the current template.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+
 	// Number of available replicas in this MinecraftServerDeployment.
 	AvailableReplicas int32 `json:"availableReplicas"`
 
@@ -53,6 +69,11 @@ type MinecraftServerDeploymentStatus struct {
 
 	// Pod label selector.
 	Selector string `json:"selector"`
+
+	// Resources of the current template, resolved once and given
+	// as is to every MinecraftServer created from it.
+	//+optional
+	ResourcesLock *ResourceRefLock `json:"resourcesLock,omitempty"`
 }
 
 func (s *MinecraftServerDeploymentStatus) SetCondition(condition MinecraftServerDeploymentStatusCondition, status metav1.ConditionStatus, reason string, message string) metav1.Condition {
@@ -71,6 +92,7 @@ func (s *MinecraftServerDeploymentStatus) SetCondition(condition MinecraftServer
 //+kubebuilder:subresource:status
 //+kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 //+kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.replicas"
+//+kubebuilder:printcolumn:name="Up-to-date Replicas",type="integer",JSONPath=".status.updatedReplicas"
 //+kubebuilder:printcolumn:name="Available Replicas",type="integer",JSONPath=".status.availableReplicas"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 //+kubebuilder:resource:shortName={"skrmsd"},categories=all
@@ -0,0 +1,67 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Phase of the lifecycle of a MinecraftServer or Proxy, reported as
+// the reason of their Phase condition.
+type Phase string
+
+const (
+	// The Pod is not created yet.
+	PhasePending Phase = "Pending"
+
+	// The Pod waits for a node to run on.
+	PhaseScheduling Phase = "Scheduling"
+
+	// The init container downloads the resources.
+	PhaseDownloadingResources Phase = "DownloadingResources"
+
+	// The server or proxy is starting and is not ready yet.
+	PhaseStarting Phase = "Starting"
+
+	// The server or proxy accepts players.
+	PhaseReady Phase = "Ready"
+
+	// The proxy is running but does not accept new players.
+	PhaseDraining Phase = "Draining"
+
+	// The Pod is being deleted.
+	PhaseStopping Phase = "Stopping"
+
+	// The server or proxy exited successfully.
+	PhaseTerminated Phase = "Terminated"
+
+	// A container of the Pod failed, see the last failure.
+	PhaseFailed Phase = "Failed"
+)
+
+// Describes why the Pod of a MinecraftServer or Proxy failed.
+type LifecycleFailure struct {
+	// Machine-readable reason of the failure, e.g. InitFailed.
+	Reason string `json:"reason"`
+
+	// Name of the container which failed, if any.
+	//+optional
+	Container string `json:"container,omitempty"`
+
+	// Exit code of the container which failed, if it exited.
+	//+optional
+	ExitCode int32 `json:"exitCode,omitempty"`
+
+	// Human-readable message of the failure, which ends with the
+	// tail of the log of the container when it did not tell why it
+	// failed.
+	//+optional
+	Message string `json:"message,omitempty"`
+
+	// Time at which the failure happened.
+	//+optional
+	Time metav1.Time `json:"time,omitempty"`
+}
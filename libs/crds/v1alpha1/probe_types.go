@@ -0,0 +1,76 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package v1alpha1
+
+// +kubebuilder:validation:Enum=Exec;ServerListPing
+type ProbeMode string
+
+const (
+	// Run the health script shipped in the image.
+	ProbeModeExec ProbeMode = "Exec"
+
+	// Ping the server using the Server List Ping protocol, from a
+	// sidecar added to the Pod. Does not depend on the image.
+	ProbeModeServerListPing ProbeMode = "ServerListPing"
+)
+
+// Defines how the health of the server or proxy is probed.
+type ProbesSpec struct {
+	// How the server or proxy is probed. Can be "Exec" or
+	// "ServerListPing". Defaults to Exec.
+	//+optional
+	//+kubebuilder:default=Exec
+	Mode ProbeMode `json:"mode,omitempty"`
+
+	// Thresholds of the probe run until the server or proxy has
+	// started, the liveness and readiness probes only run after it
+	// succeeded. Defaults to a probe every 10 seconds for 5 minutes,
+	// or 10 minutes for modded servers.
+	//+optional
+	Startup *ProbeThresholdsSpec `json:"startup,omitempty"`
+
+	// Thresholds of the probe restarting the server or proxy when it
+	// fails.
+	//+optional
+	Liveness *ProbeThresholdsSpec `json:"liveness,omitempty"`
+
+	// Thresholds of the probe removing the server or proxy from
+	// the ready ones when it fails.
+	//+optional
+	Readiness *ProbeThresholdsSpec `json:"readiness,omitempty"`
+}
+
+// Thresholds of a probe, the ones not set keep their default.
+type ProbeThresholdsSpec struct {
+	// Number of seconds to wait before the first probe.
+	//+optional
+	//+kubebuilder:validation:Minimum=0
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// Number of seconds between two probes.
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// Number of seconds after which a probe times out.
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// Number of consecutive failures after which the probe is
+	// considered failed.
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// Returns the mode of the probes, falling back to Exec when not set.
+func (s *ProbesSpec) GetMode() ProbeMode {
+	if s == nil || s.Mode == "" {
+		return ProbeModeExec
+	}
+	return s.Mode
+}
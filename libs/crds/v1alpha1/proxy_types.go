@@ -9,6 +9,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 const ProxyDrainAnnotationName = "proxy.shulkermc.io/drain"
@@ -32,6 +33,21 @@ type ProxySpec struct {
 	// Overrides for values to be injected in the created Pod
 	// of this Proxy.
 	PodOverrides *ProxyPodOverridesSpec `json:"podOverrides,omitempty"`
+
+	// Tuning of the JVM running the proxy, its heap being sized from
+	// the memory resources of the Pod.
+	//+optional
+	Jvm *JvmSpec `json:"jvm,omitempty"`
+
+	// Probes checking the health of the proxy.
+	//+optional
+	Probes *ProbesSpec `json:"probes,omitempty"`
+
+	// Resources locked by the owning deployment, used instead of
+	// resolving the ResourceRefs of the configuration. Set by the
+	// operator, must not be set in deployment templates.
+	//+optional
+	LockedResources []LockedResourceRef `json:"lockedResources,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=BungeeCord;Waterfall;Velocity
@@ -66,7 +82,8 @@ type ProxyConfigurationSpec struct {
 	Plugins []ResourceRef `json:"plugins,omitempty"`
 
 	// List of optional references to patch archives to download
-	// and extract at the root of the proxy. Gzippied tarballs only.
+	// and extract at the root of the proxy. Gzipped tarballs or zip
+	// archives.
 	//+optional
 	Patches []ResourceRef `json:"patches,omitempty"`
 
@@ -104,24 +121,100 @@ type ProxyPodOverridesSpec struct {
 	// Affinity scheduling rules to be applied on created Pod.
 	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 
-	// Name of the ServiceAccount to use.
+	// Image of the proxy container, e.g. to pin a digest or to run an
+	// image with baked-in plugins. Defaults to the image configured
+	// in the operator.
+	Image string `json:"image,omitempty"`
+
+	// Pull policy of the images of the created Pod. Defaults to the
+	// pull policy configured in the operator.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// References to Secrets to pull the images of the created Pod
+	// with, in addition to the ones configured in the operator.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Name of the ServiceAccount to use. Defaults to the one of
+	// the cluster.
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Extra labels to add to the created Pod. The labels set by
+	// Shulker cannot be overridden.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Extra annotations to add to the created Pod.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Labels of the nodes the created Pod can be scheduled on.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the created Pod.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// How the created Pods are spread across the topology domains.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Name of the PriorityClass of the created Pod.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Extra volumes to add to the created Pod.
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// Extra volume mounts to add to the proxy container.
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// Sidecar containers to add to the created Pod.
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+
+	// Strategic merge patch applied last to the created Pod, with
+	// metadata and spec fields, to set the fields Shulker does not
+	// model.
+	//+kubebuilder:pruning:PreserveUnknownFields
+	PodTemplate *runtime.RawExtension `json:"podTemplate,omitempty"`
 }
 
 type ProxyStatusCondition string
 
 const (
-	ProxyReadyCondition ProxyStatusCondition = "Ready"
-	ProxyPhaseCondition ProxyStatusCondition = "Phase"
+	ProxyReadyCondition             ProxyStatusCondition = "Ready"
+	ProxyPhaseCondition             ProxyStatusCondition = "Phase"
+	ProxyResourcesResolvedCondition ProxyStatusCondition = "ResourcesResolved"
 )
 
 // ProxyStatus defines the observed state of Proxy
 type ProxyStatus struct {
 	// Conditions represent the latest available observations of a
 	// Proxy object.
-	// Known .status.conditions.type are: "Ready", "Phase".
+	// Known .status.conditions.type are: "Ready", "Phase",
+	// "ResourcesResolved".
 	//+kubebuilder:validation:Required
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// Number of players connected to the Proxy, as reported by the
+	// last ping of the operator.
+	OnlinePlayers int32 `json:"onlinePlayers,omitempty"`
+
+	// Last time the operator pinged the Proxy, whether it answered
+	// or not.
+	//+optional
+	LastPingTime *metav1.Time `json:"lastPingTime,omitempty"`
+
+	// Resources of the Proxy as they were last resolved.
+	//+optional
+	Resources []ResolvedResourceRefStatus `json:"resources,omitempty"`
+
+	// Resources of the Proxy, resolved once per spec so their
+	// sources are not queried on every reconciliation, and kept as
+	// long as its Pod exists. Unused when the resources are locked
+	// by the owning deployment.
+	//+optional
+	ResourcesLock *ResourceRefLock `json:"resourcesLock,omitempty"`
+
+	// Last failure of the Pod of the Proxy, kept after the Pod
+	// is replaced.
+	//+optional
+	LastFailure *LifecycleFailure `json:"lastFailure,omitempty"`
 }
 
 func (s *ProxyStatus) SetCondition(condition ProxyStatusCondition, status metav1.ConditionStatus, reason string, message string) metav1.Condition {
@@ -140,6 +233,7 @@ func (s *ProxyStatus) SetCondition(condition ProxyStatusCondition, status metav1
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 //+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.conditions[?(@.type==\"Phase\")].reason"
+//+kubebuilder:printcolumn:name="Players",type="integer",JSONPath=".status.onlinePlayers"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 //+kubebuilder:resource:shortName={"skrp"},categories=all
 
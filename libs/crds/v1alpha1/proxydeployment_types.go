@@ -24,6 +24,13 @@ type ProxyDeploymentSpec struct {
 	//+kubebuilder:validation:Required
 	Replicas int32 `json:"replicas,omitempty"`
 
+	// Strategy used to replace the existing Proxies with new ones
+	// when the template changes. Outdated Proxies are drained, the
+	// ready ones only once as many new Proxies are ready, so a
+	// rolling update always surges by at least one Proxy.
+	//+optional
+	Strategy DeploymentStrategy `json:"strategy,omitempty"`
+
 	// The desired state of the Kubernetes Service to create for the
 	// Proxy Deployment.
 	//+kubebuilder:validation:Required
@@ -53,20 +60,30 @@ type ProxyDeploymentServiceSpec struct {
 type ProxyDeploymentStatusCondition string
 
 const (
-	ProxyDeploymentAvailableCondition ProxyDeploymentStatusCondition = "Available"
+	ProxyDeploymentAvailableCondition         ProxyDeploymentStatusCondition = "Available"
+	ProxyDeploymentProgressingCondition       ProxyDeploymentStatusCondition = "Progressing"
+	ProxyDeploymentResourcesResolvedCondition ProxyDeploymentStatusCondition = "ResourcesResolved"
 )
 
 // ProxyDeploymentStatus defines the observed state of ProxyDeployment
 type ProxyDeploymentStatus struct {
 	// Conditions represent the latest available observations of a
 	// ProxyDeployment object.
-	// Known .status.conditions.type are: "Available".
+	// Known .status.conditions.type are: "Available", "Progressing",
+	// "ResourcesResolved".
 	//+kubebuilder:validation:Required
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 
 	// Number of total replicas in this ProxyDeployment.
 	Replicas int32 `json:"replicas"`
 
+	// Number of replicas in this ProxyDeployment matching the
+	// current template.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+
+	// Number of replicas in this ProxyDeployment being drained.
+	DrainingReplicas int32 `json:"drainingReplicas"`
+
 	// Number of available replicas in this ProxyDeployment.
 	AvailableReplicas int32 `json:"availableReplicas"`
 
@@ -75,6 +92,11 @@ type ProxyDeploymentStatus struct {
 
 	// Pod label selector.
 	Selector string `json:"selector"`
+
+	// Resources of the current template, resolved once and given
+	// as is to every Proxy created from it.
+	//+optional
+	ResourcesLock *ResourceRefLock `json:"resourcesLock,omitempty"`
 }
 
 func (s *ProxyDeploymentStatus) SetCondition(condition ProxyDeploymentStatusCondition, status metav1.ConditionStatus, reason string, message string) metav1.Condition {
@@ -93,7 +115,9 @@ func (s *ProxyDeploymentStatus) SetCondition(condition ProxyDeploymentStatusCond
 //+kubebuilder:subresource:status
 //+kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 //+kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.replicas"
+//+kubebuilder:printcolumn:name="Up-to-date Replicas",type="integer",JSONPath=".status.updatedReplicas"
 //+kubebuilder:printcolumn:name="Available Replicas",type="integer",JSONPath=".status.availableReplicas"
+//+kubebuilder:printcolumn:name="Draining Replicas",type="integer",JSONPath=".status.drainingReplicas"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 //+kubebuilder:resource:shortName={"skrpd"},categories=all
 
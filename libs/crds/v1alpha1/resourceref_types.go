@@ -9,14 +9,67 @@ type ResourceRef struct {
 	// empty.
 	// +optional
 	UrlFrom *ResourceRefSource `json:"urlFrom,omitempty"`
+
+	// Expected checksums of the resource. The server or proxy does
+	// not start when the downloaded resource does not match them.
+	// +optional
+	Checksum *ResourceRefChecksum `json:"checksum,omitempty"`
 }
 
+type ResourceRefChecksum struct {
+	// Hex-encoded SHA-256 checksum of the resource.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{64}$`
+	Sha256 string `json:"sha256,omitempty"`
+
+	// Hex-encoded SHA-512 checksum of the resource.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{128}$`
+	Sha512 string `json:"sha512,omitempty"`
+}
+
+// Only one source can be set.
 type ResourceRefSource struct {
 	// Reference to a Maven artiact to use as source.
 	// +optional
 	MavenRef *ResourceRefMavenSelector `json:"mavenRef,omitempty"`
+
+	// Reference to a project version published on Modrinth to use
+	// as source.
+	// +optional
+	ModrinthRef *ResourceRefModrinthSelector `json:"modrinthRef,omitempty"`
+
+	// Reference to a project version published on Hangar to use as
+	// source.
+	// +optional
+	HangarRef *ResourceRefHangarSelector `json:"hangarRef,omitempty"`
+
+	// Reference to an asset of a GitHub Release to use as source.
+	// +optional
+	GitHubReleaseRef *ResourceRefGitHubReleaseSelector `json:"gitHubReleaseRef,omitempty"`
+
+	// Reference to a project file published on CurseForge to use as
+	// source.
+	// +optional
+	CurseForgeRef *ResourceRefCurseForgeSelector `json:"curseForgeRef,omitempty"`
+
+	// Reference to a key of a ConfigMap, in the same namespace, to
+	// use as source. Binary data keys are supported.
+	// +optional
+	ConfigMapRef *ResourceRefKeySelector `json:"configMapRef,omitempty"`
+
+	// Reference to a key of a Secret, in the same namespace, to use
+	// as source.
+	// +optional
+	SecretRef *ResourceRefKeySelector `json:"secretRef,omitempty"`
+
+	// Reference to an artifact of an OCI registry to use as source.
+	// +optional
+	OCIRef *ResourceRefOCISelector `json:"ociRef,omitempty"`
 }
 
+// Artifacts are verified against the .sha256 or .sha1 checksum files
+// published next to them in the repository, when available.
 type ResourceRefMavenSelector struct {
 	// URL to the Maven repository to download the artifact from.
 	//+kubebuilder:validation:Required
@@ -30,13 +83,290 @@ type ResourceRefMavenSelector struct {
 	//+kubebuilder:validation:Required
 	ArtifactId string `json:"artifactId,omitempty"`
 
-	// Version of the Maven artifact to download.
+	// Version of the Maven artifact to download. Can also be
+	// LATEST or RELEASE to use the latest version published in the
+	// repository. SNAPSHOT versions are resolved to their latest
+	// timestamped build.
 	//+kubebuilder:validation:Required
 	Version string `json:"version,omitempty"`
 
+	// Classifier of the Maven artifact to download, e.g. all for
+	// shaded jars.
+	//+optional
+	Classifier string `json:"classifier,omitempty"`
+
+	// Extension of the Maven artifact to download.
+	//+kubebuilder:default=jar
+	//+optional
+	Extension string `json:"extension,omitempty"`
+
 	// Name of the Kubernetes Secret containing the repository
 	// credentials. The secret must contains a username
 	// and password keys.
 	//+optional
 	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
 }
+
+const DefaultResourceRefMavenExtension = "jar"
+
+// Returns the extension of the artifact, falling back to the default
+// when not set.
+func (s *ResourceRefMavenSelector) GetExtension() string {
+	if s.Extension == "" {
+		return DefaultResourceRefMavenExtension
+	}
+	return s.Extension
+}
+
+// The primary file of the most recent version matching the selector
+// is used.
+type ResourceRefModrinthSelector struct {
+	// Slug or ID of the Modrinth project.
+	//+kubebuilder:validation:Required
+	Project string `json:"project,omitempty"`
+
+	// Version number, or range of version numbers, e.g.
+	// ">=1.2.0 <2.0.0", of the project. Defaults to the most recent
+	// version.
+	//+optional
+	Version string `json:"version,omitempty"`
+
+	// Loader the version must support, e.g. paper, velocity or
+	// fabric.
+	//+optional
+	Loader string `json:"loader,omitempty"`
+
+	// Minecraft version the version must support.
+	//+optional
+	GameVersion string `json:"gameVersion,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=PAPER;WATERFALL;VELOCITY
+type ResourceRefHangarPlatform string
+
+const (
+	ResourceRefHangarPaperPlatform     ResourceRefHangarPlatform = "PAPER"
+	ResourceRefHangarWaterfallPlatform ResourceRefHangarPlatform = "WATERFALL"
+	ResourceRefHangarVelocityPlatform  ResourceRefHangarPlatform = "VELOCITY"
+)
+
+// The most recent version matching the selector is used.
+type ResourceRefHangarSelector struct {
+	// Slug of the Hangar project.
+	//+kubebuilder:validation:Required
+	Project string `json:"project,omitempty"`
+
+	// Version name, or range of version names, e.g. ">=1.2.0
+	// <2.0.0", of the project. Defaults to the most recent version.
+	//+optional
+	Version string `json:"version,omitempty"`
+
+	// Platform to download the version for.
+	//+kubebuilder:validation:Required
+	Platform ResourceRefHangarPlatform `json:"platform,omitempty"`
+
+	// Minecraft version the version must support.
+	//+optional
+	GameVersion string `json:"gameVersion,omitempty"`
+}
+
+// The most recent release matching the selector is used. Drafts and
+// pre-releases are ignored.
+type ResourceRefGitHubReleaseSelector struct {
+	// Repository the release is published in, in the owner/name
+	// form.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:Pattern=`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`
+	Repository string `json:"repository,omitempty"`
+
+	// Tag, or range of tags, e.g. ">=1.2.0 <2.0.0", of the release.
+	// A leading v in tags is ignored when comparing them with a
+	// range. Defaults to the latest release.
+	//+optional
+	Tag string `json:"tag,omitempty"`
+
+	// Name, or glob pattern, of the release asset to download, e.g.
+	// "*-all.jar". Defaults to the only jar asset of the release.
+	//+optional
+	AssetName string `json:"assetName,omitempty"`
+
+	// Name of the Kubernetes Secret containing the credentials to
+	// call the GitHub API with. The secret must contains a username
+	// and password keys, the password being a personal access
+	// token.
+	//+optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Forge;Fabric;Quilt;NeoForge
+type ResourceRefCurseForgeModLoader string
+
+const (
+	ResourceRefCurseForgeForgeModLoader    ResourceRefCurseForgeModLoader = "Forge"
+	ResourceRefCurseForgeFabricModLoader   ResourceRefCurseForgeModLoader = "Fabric"
+	ResourceRefCurseForgeQuiltModLoader    ResourceRefCurseForgeModLoader = "Quilt"
+	ResourceRefCurseForgeNeoForgeModLoader ResourceRefCurseForgeModLoader = "NeoForge"
+)
+
+// The most recent file matching the selector is used. Projects whose
+// authors disabled third-party distribution cannot be downloaded.
+type ResourceRefCurseForgeSelector struct {
+	// ID of the CurseForge project.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:Minimum=1
+	ProjectId int32 `json:"projectId,omitempty"`
+
+	// ID of the file to download. Defaults to the most recent file.
+	//+optional
+	//+kubebuilder:validation:Minimum=1
+	FileId int32 `json:"fileId,omitempty"`
+
+	// Mod loader the file must support.
+	//+optional
+	ModLoader ResourceRefCurseForgeModLoader `json:"modLoader,omitempty"`
+
+	// Minecraft version the file must support.
+	//+optional
+	GameVersion string `json:"gameVersion,omitempty"`
+
+	// Name of the Kubernetes Secret containing the key to call the
+	// CurseForge API with, in a apiKey key.
+	//+kubebuilder:validation:Required
+	ApiKeySecretName string `json:"apiKeySecretName,omitempty"`
+}
+
+// The key is mounted in the init container, its name is used as file
+// name.
+type ResourceRefKeySelector struct {
+	// Name of the ConfigMap or the Secret.
+	//+kubebuilder:validation:Required
+	Name string `json:"name,omitempty"`
+
+	// Key containing the resource.
+	//+kubebuilder:validation:Required
+	Key string `json:"key,omitempty"`
+}
+
+// The artifact must contain a single layer, unless the file name is
+// given. Artifacts pushed with tools like ORAS carry the names of
+// their files in their layers.
+type ResourceRefOCISelector struct {
+	// Repository of the artifact, including the registry, e.g.
+	// ghcr.io/owner/plugin.
+	//+kubebuilder:validation:Required
+	Repository string `json:"repository,omitempty"`
+
+	// Tag of the artifact. Cannot be used if digest is set.
+	//+optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest of the artifact manifest, e.g. sha256:<hex>.
+	//+optional
+	//+kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	Digest string `json:"digest,omitempty"`
+
+	// Name of the file to use, matched against the
+	// org.opencontainers.image.title annotation of the layers.
+	//+optional
+	FileName string `json:"fileName,omitempty"`
+
+	// Name of the Kubernetes Secret containing the registry
+	// credentials. The secret must contains a username and password
+	// keys.
+	//+optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+// Resource as it was resolved by the operator.
+type ResolvedResourceRefStatus struct {
+	// Path of the ResourceRef in the spec, e.g.
+	// spec.config.plugins[0].
+	Path string `json:"path"`
+
+	// URL the resource is downloaded from, or the ConfigMap or
+	// Secret key it is read from.
+	Url string `json:"url"`
+
+	// Concrete version of the Maven artifact, when the resource
+	// comes from a Maven repository.
+	//+optional
+	Version string `json:"version,omitempty"`
+
+	// Hex-encoded SHA-256 checksum the resource is verified against,
+	// if any.
+	//+optional
+	Sha256 string `json:"sha256,omitempty"`
+
+	// Hex-encoded SHA-512 checksum the resource is verified against,
+	// if any.
+	//+optional
+	Sha512 string `json:"sha512,omitempty"`
+}
+
+// Resource as it was resolved once, by a deployment for all its
+// replicas to use the very same one, or by a server or proxy for its
+// own Pod.
+type LockedResourceRef struct {
+	// Path of the ResourceRef in the spec of the replicas, e.g.
+	// spec.config.plugins[0].
+	Path string `json:"path"`
+
+	// URL the resource is downloaded from.
+	//+optional
+	Url string `json:"url,omitempty"`
+
+	// Concrete version of the resource, when its source has one.
+	//+optional
+	Version string `json:"version,omitempty"`
+
+	// Name of the Kubernetes Secret containing the credentials to
+	// download the resource with, if any.
+	//+optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+
+	// Hex-encoded SHA-256 checksum of the resource, if known.
+	//+optional
+	Sha256 string `json:"sha256,omitempty"`
+
+	// Hex-encoded SHA-512 checksum of the resource, if known.
+	//+optional
+	Sha512 string `json:"sha512,omitempty"`
+
+	// Whether the checksums files published next to the resource
+	// are used to verify it. Resources without any are listed as
+	// unverified in the termination message of the init container.
+	//+optional
+	VerifyPublishedChecksums bool `json:"verifyPublishedChecksums,omitempty"`
+
+	// Name of the ConfigMap containing the resource, when it is not
+	// downloaded.
+	//+optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// Name of the Secret containing the resource, when it is not
+	// downloaded.
+	//+optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Key of the ConfigMap or Secret containing the resource.
+	//+optional
+	Key string `json:"key,omitempty"`
+
+	// Name of the file of the resource, when it cannot be guessed
+	// from its URL.
+	//+optional
+	FileName string `json:"fileName,omitempty"`
+}
+
+// Resources of a deployment template, resolved once per version of
+// the template.
+type ResourceRefLock struct {
+	// Hash of the template, or spec, the resources were resolved
+	// from.
+	TemplateHash string `json:"templateHash"`
+
+	// Resources of the template, as given to every replica created
+	// from it, or of the spec.
+	//+optional
+	Resources []LockedResourceRef `json:"resources,omitempty"`
+}
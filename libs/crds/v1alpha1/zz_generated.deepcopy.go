@@ -13,15 +13,92 @@ package v1alpha1
 import (
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentStrategy) DeepCopyInto(out *DeploymentStrategy) {
+	*out = *in
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdateDeploymentStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentStrategy.
+func (in *DeploymentStrategy) DeepCopy() *DeploymentStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JvmSpec) DeepCopyInto(out *JvmSpec) {
+	*out = *in
+	if in.HeapSize != nil {
+		in, out := &in.HeapSize, &out.HeapSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JvmSpec.
+func (in *JvmSpec) DeepCopy() *JvmSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JvmSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LifecycleFailure) DeepCopyInto(out *LifecycleFailure) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleFailure.
+func (in *LifecycleFailure) DeepCopy() *LifecycleFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(LifecycleFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LockedResourceRef) DeepCopyInto(out *LockedResourceRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LockedResourceRef.
+func (in *LockedResourceRef) DeepCopy() *LockedResourceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(LockedResourceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MinecraftCluster) DeepCopyInto(out *MinecraftCluster) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -43,6 +120,32 @@ func (in *MinecraftCluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftClusterCacheSpec) DeepCopyInto(out *MinecraftClusterCacheSpec) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	out.Size = in.Size.DeepCopy()
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftClusterCacheSpec.
+func (in *MinecraftClusterCacheSpec) DeepCopy() *MinecraftClusterCacheSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftClusterCacheSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MinecraftClusterList) DeepCopyInto(out *MinecraftClusterList) {
 	*out = *in
@@ -93,6 +196,11 @@ func (in *MinecraftClusterRef) DeepCopy() *MinecraftClusterRef {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MinecraftClusterSpec) DeepCopyInto(out *MinecraftClusterSpec) {
 	*out = *in
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(MinecraftClusterCacheSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftClusterSpec.
@@ -147,6 +255,37 @@ func (in *MinecraftServer) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftServerBackupBucketSpec) DeepCopyInto(out *MinecraftServerBackupBucketSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftServerBackupBucketSpec.
+func (in *MinecraftServerBackupBucketSpec) DeepCopy() *MinecraftServerBackupBucketSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftServerBackupBucketSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftServerBackupSpec) DeepCopyInto(out *MinecraftServerBackupSpec) {
+	*out = *in
+	out.Bucket = in.Bucket
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftServerBackupSpec.
+func (in *MinecraftServerBackupSpec) DeepCopy() *MinecraftServerBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftServerBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MinecraftServerConfigurationSpec) DeepCopyInto(out *MinecraftServerConfigurationSpec) {
 	*out = *in
@@ -162,6 +301,13 @@ func (in *MinecraftServerConfigurationSpec) DeepCopyInto(out *MinecraftServerCon
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Mods != nil {
+		in, out := &in.Mods, &out.Mods
+		*out = make([]ResourceRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Patches != nil {
 		in, out := &in.Patches, &out.Patches
 		*out = make([]ResourceRef, len(*in))
@@ -256,6 +402,7 @@ func (in *MinecraftServerDeploymentList) DeepCopyObject() runtime.Object {
 func (in *MinecraftServerDeploymentSpec) DeepCopyInto(out *MinecraftServerDeploymentSpec) {
 	*out = *in
 	out.ClusterRef = in.ClusterRef
+	in.Strategy.DeepCopyInto(&out.Strategy)
 	in.Template.DeepCopyInto(&out.Template)
 }
 
@@ -279,6 +426,11 @@ func (in *MinecraftServerDeploymentStatus) DeepCopyInto(out *MinecraftServerDepl
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ResourcesLock != nil {
+		in, out := &in.ResourcesLock, &out.ResourcesLock
+		*out = new(ResourceRefLock)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftServerDeploymentStatus.
@@ -323,6 +475,43 @@ func (in *MinecraftServerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftServerPersistenceRetentionPolicy) DeepCopyInto(out *MinecraftServerPersistenceRetentionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftServerPersistenceRetentionPolicy.
+func (in *MinecraftServerPersistenceRetentionPolicy) DeepCopy() *MinecraftServerPersistenceRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftServerPersistenceRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MinecraftServerPersistenceSpec) DeepCopyInto(out *MinecraftServerPersistenceSpec) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	out.Size = in.Size.DeepCopy()
+	out.RetentionPolicy = in.RetentionPolicy
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftServerPersistenceSpec.
+func (in *MinecraftServerPersistenceSpec) DeepCopy() *MinecraftServerPersistenceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MinecraftServerPersistenceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MinecraftServerPodOverridesSpec) DeepCopyInto(out *MinecraftServerPodOverridesSpec) {
 	*out = *in
@@ -343,6 +532,72 @@ func (in *MinecraftServerPodOverridesSpec) DeepCopyInto(out *MinecraftServerPodO
 		*out = new(v1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftServerPodOverridesSpec.
@@ -371,6 +626,31 @@ func (in *MinecraftServerSpec) DeepCopyInto(out *MinecraftServerSpec) {
 		*out = new(MinecraftServerPodOverridesSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Jvm != nil {
+		in, out := &in.Jvm, &out.Jvm
+		*out = new(JvmSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(ProbesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Persistence != nil {
+		in, out := &in.Persistence, &out.Persistence
+		*out = new(MinecraftServerPersistenceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(MinecraftServerBackupSpec)
+		**out = **in
+	}
+	if in.LockedResources != nil {
+		in, out := &in.LockedResources, &out.LockedResources
+		*out = make([]LockedResourceRef, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftServerSpec.
@@ -393,6 +673,25 @@ func (in *MinecraftServerStatus) DeepCopyInto(out *MinecraftServerStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastPingTime != nil {
+		in, out := &in.LastPingTime, &out.LastPingTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResolvedResourceRefStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourcesLock != nil {
+		in, out := &in.ResourcesLock, &out.ResourcesLock
+		*out = new(ResourceRefLock)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastFailure != nil {
+		in, out := &in.LastFailure, &out.LastFailure
+		*out = new(LifecycleFailure)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MinecraftServerStatus.
@@ -437,6 +736,71 @@ func (in *MinecraftServerVersionSpec) DeepCopy() *MinecraftServerVersionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeThresholdsSpec) DeepCopyInto(out *ProbeThresholdsSpec) {
+	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeThresholdsSpec.
+func (in *ProbeThresholdsSpec) DeepCopy() *ProbeThresholdsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeThresholdsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbesSpec) DeepCopyInto(out *ProbesSpec) {
+	*out = *in
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(ProbeThresholdsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(ProbeThresholdsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(ProbeThresholdsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbesSpec.
+func (in *ProbesSpec) DeepCopy() *ProbesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Proxy) DeepCopyInto(out *Proxy) {
 	*out = *in
@@ -578,6 +942,7 @@ func (in *ProxyDeploymentServiceSpec) DeepCopy() *ProxyDeploymentServiceSpec {
 func (in *ProxyDeploymentSpec) DeepCopyInto(out *ProxyDeploymentSpec) {
 	*out = *in
 	out.ClusterRef = in.ClusterRef
+	in.Strategy.DeepCopyInto(&out.Strategy)
 	in.Service.DeepCopyInto(&out.Service)
 	in.Template.DeepCopyInto(&out.Template)
 }
@@ -602,6 +967,11 @@ func (in *ProxyDeploymentStatus) DeepCopyInto(out *ProxyDeploymentStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ResourcesLock != nil {
+		in, out := &in.ResourcesLock, &out.ResourcesLock
+		*out = new(ResourceRefLock)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyDeploymentStatus.
@@ -666,6 +1036,72 @@ func (in *ProxyPodOverridesSpec) DeepCopyInto(out *ProxyPodOverridesSpec) {
 		*out = new(v1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyPodOverridesSpec.
@@ -689,6 +1125,21 @@ func (in *ProxySpec) DeepCopyInto(out *ProxySpec) {
 		*out = new(ProxyPodOverridesSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Jvm != nil {
+		in, out := &in.Jvm, &out.Jvm
+		*out = new(JvmSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(ProbesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LockedResources != nil {
+		in, out := &in.LockedResources, &out.LockedResources
+		*out = make([]LockedResourceRef, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxySpec.
@@ -711,6 +1162,25 @@ func (in *ProxyStatus) DeepCopyInto(out *ProxyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastPingTime != nil {
+		in, out := &in.LastPingTime, &out.LastPingTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResolvedResourceRefStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourcesLock != nil {
+		in, out := &in.ResourcesLock, &out.ResourcesLock
+		*out = new(ResourceRefLock)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastFailure != nil {
+		in, out := &in.LastFailure, &out.LastFailure
+		*out = new(LifecycleFailure)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyStatus.
@@ -755,6 +1225,21 @@ func (in *ProxyVersionSpec) DeepCopy() *ProxyVersionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedResourceRefStatus) DeepCopyInto(out *ResolvedResourceRefStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedResourceRefStatus.
+func (in *ResolvedResourceRefStatus) DeepCopy() *ResolvedResourceRefStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedResourceRefStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceRef) DeepCopyInto(out *ResourceRef) {
 	*out = *in
@@ -763,6 +1248,11 @@ func (in *ResourceRef) DeepCopyInto(out *ResourceRef) {
 		*out = new(ResourceRefSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Checksum != nil {
+		in, out := &in.Checksum, &out.Checksum
+		*out = new(ResourceRefChecksum)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRef.
@@ -775,6 +1265,101 @@ func (in *ResourceRef) DeepCopy() *ResourceRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRefChecksum) DeepCopyInto(out *ResourceRefChecksum) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRefChecksum.
+func (in *ResourceRefChecksum) DeepCopy() *ResourceRefChecksum {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRefChecksum)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRefCurseForgeSelector) DeepCopyInto(out *ResourceRefCurseForgeSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRefCurseForgeSelector.
+func (in *ResourceRefCurseForgeSelector) DeepCopy() *ResourceRefCurseForgeSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRefCurseForgeSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRefGitHubReleaseSelector) DeepCopyInto(out *ResourceRefGitHubReleaseSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRefGitHubReleaseSelector.
+func (in *ResourceRefGitHubReleaseSelector) DeepCopy() *ResourceRefGitHubReleaseSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRefGitHubReleaseSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRefHangarSelector) DeepCopyInto(out *ResourceRefHangarSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRefHangarSelector.
+func (in *ResourceRefHangarSelector) DeepCopy() *ResourceRefHangarSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRefHangarSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRefKeySelector) DeepCopyInto(out *ResourceRefKeySelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRefKeySelector.
+func (in *ResourceRefKeySelector) DeepCopy() *ResourceRefKeySelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRefKeySelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRefLock) DeepCopyInto(out *ResourceRefLock) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]LockedResourceRef, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRefLock.
+func (in *ResourceRefLock) DeepCopy() *ResourceRefLock {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRefLock)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceRefMavenSelector) DeepCopyInto(out *ResourceRefMavenSelector) {
 	*out = *in
@@ -790,6 +1375,36 @@ func (in *ResourceRefMavenSelector) DeepCopy() *ResourceRefMavenSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRefModrinthSelector) DeepCopyInto(out *ResourceRefModrinthSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRefModrinthSelector.
+func (in *ResourceRefModrinthSelector) DeepCopy() *ResourceRefModrinthSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRefModrinthSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRefOCISelector) DeepCopyInto(out *ResourceRefOCISelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRefOCISelector.
+func (in *ResourceRefOCISelector) DeepCopy() *ResourceRefOCISelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRefOCISelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceRefSource) DeepCopyInto(out *ResourceRefSource) {
 	*out = *in
@@ -798,6 +1413,41 @@ func (in *ResourceRefSource) DeepCopyInto(out *ResourceRefSource) {
 		*out = new(ResourceRefMavenSelector)
 		**out = **in
 	}
+	if in.ModrinthRef != nil {
+		in, out := &in.ModrinthRef, &out.ModrinthRef
+		*out = new(ResourceRefModrinthSelector)
+		**out = **in
+	}
+	if in.HangarRef != nil {
+		in, out := &in.HangarRef, &out.HangarRef
+		*out = new(ResourceRefHangarSelector)
+		**out = **in
+	}
+	if in.GitHubReleaseRef != nil {
+		in, out := &in.GitHubReleaseRef, &out.GitHubReleaseRef
+		*out = new(ResourceRefGitHubReleaseSelector)
+		**out = **in
+	}
+	if in.CurseForgeRef != nil {
+		in, out := &in.CurseForgeRef, &out.CurseForgeRef
+		*out = new(ResourceRefCurseForgeSelector)
+		**out = **in
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(ResourceRefKeySelector)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(ResourceRefKeySelector)
+		**out = **in
+	}
+	if in.OCIRef != nil {
+		in, out := &in.OCIRef, &out.OCIRef
+		*out = new(ResourceRefOCISelector)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRefSource.
@@ -809,3 +1459,28 @@ func (in *ResourceRefSource) DeepCopy() *ResourceRefSource {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollingUpdateDeploymentStrategy) DeepCopyInto(out *RollingUpdateDeploymentStrategy) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateDeploymentStrategy.
+func (in *RollingUpdateDeploymentStrategy) DeepCopy() *RollingUpdateDeploymentStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RollingUpdateDeploymentStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
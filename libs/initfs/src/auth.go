@@ -0,0 +1,105 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package initfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Returns whether the response asks to authenticate with a token, as
+// done by OCI registries.
+func IsBearerChallenge(res *http.Response) bool {
+	return res.StatusCode == http.StatusUnauthorized &&
+		strings.HasPrefix(strings.ToLower(res.Header.Get("WWW-Authenticate")), "bearer ")
+}
+
+// Gets a token answering the Bearer challenge of a response, using the
+// given credentials when not empty. Registries also give tokens to
+// anonymous clients for public content.
+func GetBearerToken(ctx context.Context, httpClient *http.Client, res *http.Response, username string, password string) (string, error) {
+	params := parseBearerChallenge(res.Header.Get("WWW-Authenticate"))
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("missing realm in Bearer challenge")
+	}
+
+	tokenUrl, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid realm in Bearer challenge: %v", err)
+	}
+	query := tokenUrl.Query()
+	for _, name := range []string{"service", "scope"} {
+		if params[name] != "" {
+			query.Set(name, params[name])
+		}
+	}
+	tokenUrl.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenUrl.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	tokenRes, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token: %v", err)
+	}
+	defer tokenRes.Body.Close()
+
+	if tokenRes.StatusCode < 200 || tokenRes.StatusCode > 299 {
+		return "", fmt.Errorf("failed to get token: unexpected status %d", tokenRes.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(tokenRes.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	// Some registries only fill one of the fields
+	tokenResponse := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.Unmarshal(content, &tokenResponse); err != nil {
+		return "", fmt.Errorf("invalid token response: %v", err)
+	}
+
+	if tokenResponse.Token != "" {
+		return tokenResponse.Token, nil
+	} else if tokenResponse.AccessToken != "" {
+		return tokenResponse.AccessToken, nil
+	}
+	return "", fmt.Errorf("empty token response")
+}
+
+// Parses the parameters of a challenge like:
+// Bearer realm="https://auth.example.com/token",service="registry",scope="repository:name:pull"
+func parseBearerChallenge(challenge string) map[string]string {
+	params := map[string]string{}
+
+	_, rawParams, _ := strings.Cut(challenge, " ")
+	for rawParams != "" {
+		var name, value string
+		name, rawParams, _ = strings.Cut(strings.TrimLeft(rawParams, " ,"), "=")
+		if strings.HasPrefix(rawParams, `"`) {
+			value, rawParams, _ = strings.Cut(rawParams[1:], `"`)
+		} else {
+			value, rawParams, _ = strings.Cut(rawParams, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(name))] = value
+	}
+
+	return params
+}
@@ -0,0 +1,302 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package initfs
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Downloader downloads resources, retrying on failures.
+type Downloader struct {
+	HTTPClient *http.Client
+	Logger     logr.Logger
+
+	// Number of retries after the first attempt.
+	Retries int
+
+	// Delay before the first retry, doubled for each retry.
+	RetryDelay time.Duration
+}
+
+// Errors which would not be fixed by retrying.
+type permanentDownloadError struct {
+	err error
+}
+
+func (e *permanentDownloadError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentDownloadError) Unwrap() error {
+	return e.err
+}
+
+type unexpectedStatusError struct {
+	statusCode int
+}
+
+func (e *unexpectedStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.statusCode)
+}
+
+// ChecksumMismatchError is returned when a downloaded resource does
+// not match its expected checksum.
+type ChecksumMismatchError struct {
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch, expected %s, got %s", e.Algorithm, e.Expected, e.Actual)
+}
+
+// Outcome of the download of a resource.
+type DownloadResult struct {
+	// Hex-encoded SHA-256 checksum of the resource.
+	Sha256 string
+
+	// Number of download attempts.
+	Attempts int
+
+	// Whether the published checksums were asked to be verified but
+	// none was published, leaving the resource unverified.
+	Unverified bool
+}
+
+// Downloads a resource to the given path and verifies its checksums.
+func (d *Downloader) Download(ctx context.Context, resource *ManifestResource, path string) (DownloadResult, error) {
+	delay := d.RetryDelay
+	result := DownloadResult{}
+
+	for {
+		result.Attempts++
+		err := d.downloadOnce(ctx, resource, path, &result)
+		if err == nil {
+			return result, nil
+		}
+
+		if IsPermanentDownloadError(err) || result.Attempts > d.Retries {
+			return result, err
+		}
+
+		d.Logger.Info("Download failed, retrying", "resource", resource.Name, "attempt", result.Attempts, "delay", delay.String(), "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (d *Downloader) downloadOnce(ctx context.Context, resource *ManifestResource, path string, result *DownloadResult) error {
+	body, err := d.open(ctx, resource)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return &permanentDownloadError{err}
+	}
+	defer file.Close()
+
+	sha1Hash := sha1.New()
+	sha256Hash := sha256.New()
+	sha512Hash := sha512.New()
+	if _, err := io.Copy(io.MultiWriter(file, sha1Hash, sha256Hash, sha512Hash), body); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	sha1Checksum := hex.EncodeToString(sha1Hash.Sum(nil))
+	sha256Checksum := hex.EncodeToString(sha256Hash.Sum(nil))
+	sha512Checksum := hex.EncodeToString(sha512Hash.Sum(nil))
+
+	if err := verifyChecksum("SHA-256", resource.Sha256, sha256Checksum); err != nil {
+		return err
+	}
+	if err := verifyChecksum("SHA-512", resource.Sha512, sha512Checksum); err != nil {
+		return err
+	}
+
+	if resource.VerifyPublishedChecksums && resource.Path == "" {
+		verified, err := d.verifyPublishedChecksums(ctx, resource, sha1Checksum, sha256Checksum)
+		if err != nil {
+			return err
+		}
+		result.Unverified = !verified
+	}
+
+	result.Sha256 = sha256Checksum
+	return nil
+}
+
+// Verifies the resource against the strongest checksum file published
+// next to it. Returns false when none is published.
+func (d *Downloader) verifyPublishedChecksums(ctx context.Context, resource *ManifestResource, sha1Checksum string, sha256Checksum string) (bool, error) {
+	publishedSha256, err := d.getPublishedChecksum(ctx, resource, ".sha256")
+	if err != nil {
+		return false, err
+	}
+	if publishedSha256 != "" {
+		return true, verifyChecksum("Published SHA-256", publishedSha256, sha256Checksum)
+	}
+
+	publishedSha1, err := d.getPublishedChecksum(ctx, resource, ".sha1")
+	if err != nil {
+		return false, err
+	}
+	if publishedSha1 != "" {
+		return true, verifyChecksum("Published SHA-1", publishedSha1, sha1Checksum)
+	}
+
+	d.Logger.Info("No checksum published for resource, it is left unverified", "resource", resource.Name)
+	return false, nil
+}
+
+// Returns the checksum published next to the resource with the given
+// suffix, or an empty string when there is none.
+func (d *Downloader) getPublishedChecksum(ctx context.Context, resource *ManifestResource, suffix string) (string, error) {
+	res, err := d.get(ctx, resource, resource.Url+suffix)
+	var statusError *unexpectedStatusError
+	if errors.As(err, &statusError) && statusError.statusCode == http.StatusNotFound {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get %s checksum: %v", suffix, err)
+	}
+	defer res.Body.Close()
+
+	content, err := io.ReadAll(io.LimitReader(res.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+
+	// Checksum files may be followed by the name of the file
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return "", nil
+	}
+	return fields[0], nil
+}
+
+// Opens the content of the resource, either a local file or the body
+// of the response to its URL.
+func (d *Downloader) open(ctx context.Context, resource *ManifestResource) (io.ReadCloser, error) {
+	if resource.Path != "" {
+		file, err := os.Open(resource.Path)
+		if err != nil {
+			return nil, &permanentDownloadError{err}
+		}
+		return file, nil
+	}
+
+	res, err := d.get(ctx, resource, resource.Url)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (d *Downloader) get(ctx context.Context, resource *ManifestResource, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, &permanentDownloadError{err}
+	}
+
+	var username, password string
+	if resource.CredentialsDir != "" {
+		username, password, err = readCredentials(resource.CredentialsDir)
+		if err != nil {
+			return nil, &permanentDownloadError{err}
+		}
+		req.SetBasicAuth(username, password)
+	}
+
+	httpClient := d.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// OCI registries ask for a token, even for public content
+	if IsBearerChallenge(res) {
+		token, err := GetBearerToken(ctx, httpClient, res, username, password)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+		res, err = httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		res.Body.Close()
+		err := &unexpectedStatusError{statusCode: res.StatusCode}
+		if res.StatusCode >= 400 && res.StatusCode < 500 && res.StatusCode != http.StatusRequestTimeout && res.StatusCode != http.StatusTooManyRequests {
+			return nil, &permanentDownloadError{err}
+		}
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// IsPermanentDownloadError returns whether the error would not be
+// fixed by downloading the resource again, as the content served
+// will not change.
+func IsPermanentDownloadError(err error) bool {
+	var permanentError *permanentDownloadError
+	var checksumError *ChecksumMismatchError
+	return errors.As(err, &permanentError) || errors.As(err, &checksumError)
+}
+
+func verifyChecksum(algorithm string, expected string, actual string) error {
+	if expected != "" && !strings.EqualFold(expected, actual) {
+		return &ChecksumMismatchError{Algorithm: algorithm, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+func readCredentials(credentialsDir string) (string, string, error) {
+	username, err := os.ReadFile(filepath.Join(credentialsDir, "username"))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read credentials: %v", err)
+	}
+
+	password, err := os.ReadFile(filepath.Join(credentialsDir, "password"))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read credentials: %v", err)
+	}
+
+	return strings.TrimSpace(string(username)), strings.TrimSpace(string(password)), nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package initfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+var zipMagic = []byte{'P', 'K', 0x03, 0x04}
+
+// Extracts a gzipped tarball or a zip archive in the destination
+// directory. The format is detected from the content of the archive.
+func ExtractArchive(archivePath string, destination string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	magic, err := reader.Peek(len(zipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return extractTarGz(reader, destination)
+	case bytes.HasPrefix(magic, zipMagic):
+		info, err := file.Stat()
+		if err != nil {
+			return err
+		}
+		return extractZip(file, info.Size(), destination)
+	}
+
+	return errors.New("unsupported archive format, expected a gzipped tarball or a zip archive")
+}
+
+func extractTarGz(r io.Reader, destination string) error {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		path, err := getExtractedPath(destination, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeExtractedFile(path, tarReader, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		}
+
+		// Links and special files are not needed by servers, and
+		// links could point outside of the destination
+	}
+}
+
+func extractZip(r io.ReaderAt, size int64, destination string) error {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	for _, zipFile := range zipReader.File {
+		path, err := getExtractedPath(destination, zipFile.Name)
+		if err != nil {
+			return err
+		}
+
+		if zipFile.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if !zipFile.Mode().IsRegular() {
+			continue
+		}
+
+		content, err := zipFile.Open()
+		if err != nil {
+			return err
+		}
+		err = writeExtractedFile(path, content, zipFile.Mode())
+		content.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns the path where an archive entry should be extracted,
+// refusing entries escaping the destination directory.
+func getExtractedPath(destination string, name string) (string, error) {
+	path := filepath.Join(destination, name)
+	if path != filepath.Clean(destination) && !strings.HasPrefix(path, filepath.Clean(destination)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %s is outside of the destination", name)
+	}
+	return path, nil
+}
+
+func writeExtractedFile(path string, content io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,99 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package initfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func createTarGz(files map[string]string) []byte {
+	buffer := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for name, content := range files {
+		Expect(tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		})).To(Succeed())
+		_, err := tarWriter.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	Expect(tarWriter.Close()).To(Succeed())
+	Expect(gzipWriter.Close()).To(Succeed())
+	return buffer.Bytes()
+}
+
+func createZip(files map[string]string) []byte {
+	buffer := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(buffer)
+
+	for name, content := range files {
+		writer, err := zipWriter.Create(name)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = writer.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	Expect(zipWriter.Close()).To(Succeed())
+	return buffer.Bytes()
+}
+
+var _ = Describe("ExtractArchive", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		tmpDir = GinkgoT().TempDir()
+	})
+
+	extract := func(archive []byte) error {
+		archivePath := filepath.Join(tmpDir, "archive")
+		Expect(os.WriteFile(archivePath, archive, 0644)).To(Succeed())
+		return ExtractArchive(archivePath, filepath.Join(tmpDir, "out"))
+	}
+
+	It("extracts gzipped tarballs", func() {
+		Expect(extract(createTarGz(map[string]string{
+			"world/level.dat":        "level",
+			"world/region/r.0.0.mca": "region",
+		}))).To(Succeed())
+
+		Expect(os.ReadFile(filepath.Join(tmpDir, "out", "world", "level.dat"))).To(BeEquivalentTo("level"))
+		Expect(os.ReadFile(filepath.Join(tmpDir, "out", "world", "region", "r.0.0.mca"))).To(BeEquivalentTo("region"))
+	})
+
+	It("extracts zip archives", func() {
+		Expect(extract(createZip(map[string]string{
+			"config/plugin.yml": "enabled: true",
+		}))).To(Succeed())
+
+		Expect(os.ReadFile(filepath.Join(tmpDir, "out", "config", "plugin.yml"))).To(BeEquivalentTo("enabled: true"))
+	})
+
+	It("refuses entries outside of the destination", func() {
+		err := extract(createZip(map[string]string{
+			"../escaped": "content",
+		}))
+
+		Expect(err).To(MatchError(ContainSubstring("outside of the destination")))
+		Expect(filepath.Join(tmpDir, "escaped")).NotTo(BeAnExistingFile())
+	})
+
+	It("refuses unknown formats", func() {
+		Expect(extract([]byte("not an archive"))).To(MatchError(ContainSubstring("unsupported archive format")))
+	})
+})
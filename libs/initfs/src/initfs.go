@@ -0,0 +1,184 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package initfs
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+)
+
+// Initializer prepares a filesystem following a Manifest.
+type Initializer struct {
+	Downloader *Downloader
+	Logger     logr.Logger
+}
+
+// Writes the files and sets up the resources of the manifest. Stops
+// at the first failure, which is reported in the result.
+func (i *Initializer) Run(ctx context.Context, manifest *Manifest) *Result {
+	result := &Result{}
+
+	for _, file := range manifest.Files {
+		if err := writeManifestFile(&file); err != nil {
+			result.Error = fmt.Sprintf("failed to write %s: %v", file.Destination, err)
+			return result
+		}
+		i.Logger.Info("Wrote file", "destination", file.Destination)
+	}
+
+	for _, resource := range manifest.Resources {
+		resourceResult := i.setUpResource(ctx, &resource)
+		result.Resources = append(result.Resources, resourceResult)
+
+		if resourceResult.Error != "" {
+			result.Error = fmt.Sprintf("failed to set up %s: %s", resource.Name, resourceResult.Error)
+			result.FailedResource = &resourceResult
+			return result
+		}
+
+		result.SetUpResources += 1
+		if resourceResult.Skipped {
+			result.SkippedResources += 1
+		}
+		if resourceResult.Unverified {
+			result.UnverifiedResources = append(result.UnverifiedResources, resource.Name)
+		}
+	}
+
+	result.Success = true
+	return result
+}
+
+func (i *Initializer) setUpResource(ctx context.Context, resource *ManifestResource) ResourceResult {
+	resourceResult := ResourceResult{Name: resource.Name}
+
+	if resource.OnceMarker != "" {
+		if _, err := os.Stat(resource.OnceMarker); err == nil {
+			i.Logger.Info("Resource already set up, skipping", "resource", resource.Name)
+			resourceResult.Skipped = true
+			return resourceResult
+		}
+	}
+
+	if err := os.MkdirAll(resource.Destination, 0755); err != nil {
+		return failedResourceResult(resourceResult, err.Error())
+	}
+
+	// Downloads go to a temporary file first so a failure never
+	// leaves a partial resource behind
+	tmpFile, err := os.CreateTemp(resource.Destination, ".shulker-download-*")
+	if err != nil {
+		return failedResourceResult(resourceResult, err.Error())
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	if resource.Path != "" {
+		i.Logger.Info("Copying resource", "resource", resource.Name, "path", resource.Path)
+	} else {
+		i.Logger.Info("Downloading resource", "resource", resource.Name, "url", resource.Url)
+	}
+	download, err := i.Downloader.Download(ctx, resource, tmpFile.Name())
+	resourceResult.Sha256 = download.Sha256
+	resourceResult.Attempts = download.Attempts
+	resourceResult.Unverified = download.Unverified
+	if err != nil {
+		var checksumError *ChecksumMismatchError
+		if errors.As(err, &checksumError) {
+			resourceResult.Reason = ChecksumMismatchResourceFailureReason
+		} else {
+			resourceResult.Reason = DownloadFailedResourceFailureReason
+		}
+		resourceResult.Error = err.Error()
+		return resourceResult
+	}
+
+	switch resource.Action {
+	case DownloadManifestResourceAction:
+		fileName, err := getResourceFileName(resource)
+		if err == nil {
+			err = os.Rename(tmpFile.Name(), filepath.Join(resource.Destination, fileName))
+		}
+		if err != nil {
+			return failedResourceResult(resourceResult, err.Error())
+		}
+
+	case ExtractManifestResourceAction:
+		if err := ExtractArchive(tmpFile.Name(), resource.Destination); err != nil {
+			return failedResourceResult(resourceResult, fmt.Sprintf("failed to extract: %v", err))
+		}
+
+	default:
+		return failedResourceResult(resourceResult, fmt.Sprintf("unknown action %q", resource.Action))
+	}
+
+	if resource.OnceMarker != "" {
+		if err := os.WriteFile(resource.OnceMarker, []byte{}, 0644); err != nil {
+			return failedResourceResult(resourceResult, err.Error())
+		}
+	}
+
+	i.Logger.Info("Resource set up", "resource", resource.Name, "sha256", download.Sha256)
+	return resourceResult
+}
+
+func failedResourceResult(resourceResult ResourceResult, message string) ResourceResult {
+	resourceResult.Reason = SetUpFailedResourceFailureReason
+	resourceResult.Error = message
+	return resourceResult
+}
+
+func getResourceFileName(resource *ManifestResource) (string, error) {
+	if resource.FileName != "" {
+		return filepath.Base(resource.FileName), nil
+	}
+	if resource.Path != "" {
+		return filepath.Base(resource.Path), nil
+	}
+
+	parsedUrl, err := url.Parse(resource.Url)
+	if err != nil {
+		return "", err
+	}
+
+	fileName := path.Base(parsedUrl.Path)
+	if fileName == "/" || fileName == "." {
+		return "", fmt.Errorf("cannot guess the file name of %s", resource.Url)
+	}
+	return fileName, nil
+}
+
+func writeManifestFile(file *ManifestFile) error {
+	content := []byte(file.Content)
+	if file.Source != "" && file.Content == "" {
+		var err error
+		content, err = os.ReadFile(file.Source)
+		if err != nil {
+			return err
+		}
+	}
+
+	if file.Base64 {
+		decoded, err := base64.StdEncoding.DecodeString(string(content))
+		if err != nil {
+			return err
+		}
+		content = decoded
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file.Destination), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file.Destination, content, 0644)
+}
@@ -0,0 +1,19 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package initfs
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestInitFs(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "InitFs Suite")
+}
@@ -0,0 +1,350 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package initfs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// HTTP server serving fixed content per path, failing the first
+// requests of each path when asked to.
+type fileServerStandIn struct {
+	server   *httptest.Server
+	mutex    sync.Mutex
+	files    map[string][]byte
+	failures map[string]int
+	requests map[string]int
+	auth     map[string]string
+}
+
+func newFileServerStandIn() *fileServerStandIn {
+	standIn := &fileServerStandIn{
+		files:    map[string][]byte{},
+		failures: map[string]int{},
+		requests: map[string]int{},
+		auth:     map[string]string{},
+	}
+
+	standIn.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		standIn.mutex.Lock()
+		defer standIn.mutex.Unlock()
+
+		standIn.requests[r.URL.Path]++
+		if standIn.failures[r.URL.Path] > 0 {
+			standIn.failures[r.URL.Path]--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if password, ok := standIn.auth[r.URL.Path]; ok {
+			_, givenPassword, _ := r.BasicAuth()
+			if givenPassword != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		content, ok := standIn.files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+
+	return standIn
+}
+
+func (s *fileServerStandIn) getRequests(path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests[path]
+}
+
+var _ = Describe("Initializer", func() {
+	var standIn *fileServerStandIn
+	var initializer *Initializer
+	var tmpDir string
+
+	BeforeEach(func() {
+		standIn = newFileServerStandIn()
+		initializer = &Initializer{
+			Downloader: &Downloader{
+				HTTPClient: &http.Client{Timeout: 5 * time.Second},
+				Logger:     logr.Discard(),
+				Retries:    2,
+				RetryDelay: time.Millisecond,
+			},
+			Logger: logr.Discard(),
+		}
+		tmpDir = GinkgoT().TempDir()
+	})
+
+	AfterEach(func() {
+		standIn.server.Close()
+	})
+
+	It("writes files and sets up resources", func() {
+		standIn.files["/plugins/plugin-1.0.jar"] = []byte("plugin")
+		standIn.files["/world.tar.gz"] = createTarGz(map[string]string{"world/level.dat": "level"})
+		Expect(os.WriteFile(filepath.Join(tmpDir, "source.properties"), []byte("motd=hello"), 0644)).To(Succeed())
+
+		result := initializer.Run(context.Background(), &Manifest{
+			Files: []ManifestFile{
+				{Source: filepath.Join(tmpDir, "source.properties"), Destination: filepath.Join(tmpDir, "config", "server.properties")},
+				{Content: "aGVsbG8=", Base64: true, Destination: filepath.Join(tmpDir, "config", "decoded")},
+			},
+			Resources: []ManifestResource{
+				{Name: "plugin", Url: standIn.server.URL + "/plugins/plugin-1.0.jar", Action: DownloadManifestResourceAction, Destination: filepath.Join(tmpDir, "config", "plugins")},
+				{Name: "world", Url: standIn.server.URL + "/world.tar.gz", Action: ExtractManifestResourceAction, Destination: filepath.Join(tmpDir, "data")},
+			},
+		})
+
+		Expect(result.Success).To(BeTrue(), result.Error)
+		Expect(os.ReadFile(filepath.Join(tmpDir, "config", "server.properties"))).To(BeEquivalentTo("motd=hello"))
+		Expect(os.ReadFile(filepath.Join(tmpDir, "config", "decoded"))).To(BeEquivalentTo("hello"))
+		Expect(os.ReadFile(filepath.Join(tmpDir, "config", "plugins", "plugin-1.0.jar"))).To(BeEquivalentTo("plugin"))
+		Expect(os.ReadFile(filepath.Join(tmpDir, "data", "world", "level.dat"))).To(BeEquivalentTo("level"))
+
+		pluginChecksum := sha256.Sum256([]byte("plugin"))
+		Expect(result.Resources[0].Sha256).To(Equal(hex.EncodeToString(pluginChecksum[:])))
+
+		// No temporary file is left behind
+		entries, err := os.ReadDir(filepath.Join(tmpDir, "data"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+
+	It("retries failed downloads", func() {
+		standIn.files["/plugin.jar"] = []byte("plugin")
+		standIn.failures["/plugin.jar"] = 2
+
+		result := initializer.Run(context.Background(), &Manifest{
+			Resources: []ManifestResource{
+				{Name: "plugin", Url: standIn.server.URL + "/plugin.jar", Action: DownloadManifestResourceAction, Destination: tmpDir},
+			},
+		})
+
+		Expect(result.Success).To(BeTrue(), result.Error)
+		Expect(result.Resources[0].Attempts).To(Equal(3))
+	})
+
+	It("does not retry missing resources", func() {
+		result := initializer.Run(context.Background(), &Manifest{
+			Resources: []ManifestResource{
+				{Name: "spec.config.plugins[0]", Url: standIn.server.URL + "/missing.jar", Action: DownloadManifestResourceAction, Destination: tmpDir},
+			},
+		})
+
+		Expect(result.Success).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("spec.config.plugins[0]"))
+		Expect(result.GetFailedResource().Name).To(Equal("spec.config.plugins[0]"))
+		Expect(standIn.getRequests("/missing.jar")).To(Equal(1))
+	})
+
+	It("fails on checksum mismatches", func() {
+		standIn.files["/plugin.jar"] = []byte("tampered")
+
+		result := initializer.Run(context.Background(), &Manifest{
+			Resources: []ManifestResource{
+				{Name: "plugin", Url: standIn.server.URL + "/plugin.jar", Sha256: "0000", Action: DownloadManifestResourceAction, Destination: tmpDir},
+			},
+		})
+
+		Expect(result.Success).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("checksum mismatch"))
+		Expect(result.GetFailedResource().Reason).To(Equal(ChecksumMismatchResourceFailureReason))
+		Expect(standIn.getRequests("/plugin.jar")).To(Equal(1))
+		Expect(filepath.Join(tmpDir, "plugin.jar")).NotTo(BeAnExistingFile())
+	})
+
+	It("verifies the published checksums", func() {
+		pluginChecksum := sha256.Sum256([]byte("plugin"))
+		standIn.files["/plugin.jar"] = []byte("plugin")
+		standIn.files["/plugin.jar.sha256"] = []byte(hex.EncodeToString(pluginChecksum[:]) + "  plugin.jar\n")
+
+		result := initializer.Run(context.Background(), &Manifest{
+			Resources: []ManifestResource{
+				{Name: "plugin", Url: standIn.server.URL + "/plugin.jar", VerifyPublishedChecksums: true, Action: DownloadManifestResourceAction, Destination: tmpDir},
+			},
+		})
+
+		Expect(result.Success).To(BeTrue(), result.Error)
+		Expect(result.UnverifiedResources).To(BeEmpty())
+		Expect(standIn.getRequests("/plugin.jar.sha1")).To(Equal(0))
+	})
+
+	It("falls back to the published SHA-1 checksum", func() {
+		standIn.files["/plugin.jar"] = []byte("tampered")
+		standIn.files["/plugin.jar.sha1"] = []byte("0000000000000000000000000000000000000000")
+
+		result := initializer.Run(context.Background(), &Manifest{
+			Resources: []ManifestResource{
+				{Name: "plugin", Url: standIn.server.URL + "/plugin.jar", VerifyPublishedChecksums: true, Action: DownloadManifestResourceAction, Destination: tmpDir},
+			},
+		})
+
+		Expect(result.Success).To(BeFalse())
+		Expect(result.Error).To(ContainSubstring("Published SHA-1 checksum mismatch"))
+		Expect(result.GetFailedResource().Reason).To(Equal(ChecksumMismatchResourceFailureReason))
+	})
+
+	It("accepts resources without published checksums", func() {
+		standIn.files["/plugin.jar"] = []byte("plugin")
+
+		result := initializer.Run(context.Background(), &Manifest{
+			Resources: []ManifestResource{
+				{Name: "plugin", Url: standIn.server.URL + "/plugin.jar", VerifyPublishedChecksums: true, Action: DownloadManifestResourceAction, Destination: tmpDir},
+			},
+		})
+
+		Expect(result.Success).To(BeTrue(), result.Error)
+		Expect(result.UnverifiedResources).To(Equal([]string{"plugin"}))
+		Expect(standIn.getRequests("/plugin.jar.sha256")).To(Equal(1))
+		Expect(standIn.getRequests("/plugin.jar.sha1")).To(Equal(1))
+	})
+
+	It("authenticates with the mounted credentials", func() {
+		standIn.files["/private.jar"] = []byte("private")
+		standIn.auth["/private.jar"] = "secret"
+		credentialsDir := filepath.Join(tmpDir, "credentials")
+		Expect(os.MkdirAll(credentialsDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(credentialsDir, "username"), []byte("user"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(credentialsDir, "password"), []byte("secret"), 0644)).To(Succeed())
+
+		result := initializer.Run(context.Background(), &Manifest{
+			Resources: []ManifestResource{
+				{Name: "private", Url: standIn.server.URL + "/private.jar", CredentialsDir: credentialsDir, Action: DownloadManifestResourceAction, Destination: tmpDir},
+			},
+		})
+
+		Expect(result.Success).To(BeTrue(), result.Error)
+	})
+
+	It("copies mounted resources", func() {
+		mountDir := filepath.Join(tmpDir, "configmaps", "plugins")
+		Expect(os.MkdirAll(mountDir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(mountDir, "patch.jar"), []byte("patch"), 0644)).To(Succeed())
+
+		result := initializer.Run(context.Background(), &Manifest{
+			Resources: []ManifestResource{
+				{Name: "patch", Path: filepath.Join(mountDir, "patch.jar"), VerifyPublishedChecksums: true, Action: DownloadManifestResourceAction, Destination: filepath.Join(tmpDir, "plugins")},
+			},
+		})
+
+		Expect(result.Success).To(BeTrue(), result.Error)
+		Expect(os.ReadFile(filepath.Join(tmpDir, "plugins", "patch.jar"))).To(BeEquivalentTo("patch"))
+	})
+
+	It("answers the Bearer challenges of registries", func() {
+		registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/token" && r.URL.Query().Get("scope") == "repository:plugin:pull":
+				_, _ = w.Write([]byte(`{"token":"abcd"}`))
+			case r.Header.Get("Authorization") == "Bearer abcd":
+				_, _ = w.Write([]byte("plugin"))
+			default:
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="registry",scope="repository:plugin:pull"`, r.Host))
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		defer registry.Close()
+
+		result := initializer.Run(context.Background(), &Manifest{
+			Resources: []ManifestResource{
+				{Name: "plugin", Url: registry.URL + "/v2/plugin/blobs/sha256:1234", FileName: "plugin.jar", Action: DownloadManifestResourceAction, Destination: tmpDir},
+			},
+		})
+
+		Expect(result.Success).To(BeTrue(), result.Error)
+		Expect(os.ReadFile(filepath.Join(tmpDir, "plugin.jar"))).To(BeEquivalentTo("plugin"))
+	})
+
+	It("skips resources already set up", func() {
+		marker := filepath.Join(tmpDir, ".shulker-world")
+		Expect(os.WriteFile(marker, []byte{}, 0644)).To(Succeed())
+
+		result := initializer.Run(context.Background(), &Manifest{
+			Resources: []ManifestResource{
+				{Name: "world", Url: standIn.server.URL + "/world.tar.gz", Action: ExtractManifestResourceAction, Destination: tmpDir, OnceMarker: marker},
+			},
+		})
+
+		Expect(result.Success).To(BeTrue(), result.Error)
+		Expect(result.Resources[0].Skipped).To(BeTrue())
+		Expect(standIn.getRequests("/world.tar.gz")).To(Equal(0))
+	})
+
+	It("round-trips the result through the termination message", func() {
+		path := filepath.Join(tmpDir, "result")
+		Expect(WriteResult(path, &Result{Error: "failed", FailedResource: &ResourceResult{Name: "plugin", Error: "boom"}})).To(Succeed())
+
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := ParseResult(string(content))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.GetFailedResource().Error).To(Equal("boom"))
+	})
+
+	It("keeps the result of many resources within the termination message", func() {
+		var resources []ManifestResource
+		for i := 0; i < 100; i++ {
+			path := fmt.Sprintf("/plugins/plugin-with-a-rather-long-name-%d.jar", i)
+			standIn.files[path] = []byte(fmt.Sprintf("plugin-%d", i))
+			resources = append(resources, ManifestResource{Name: fmt.Sprintf("spec.config.plugins[%d]", i), Url: standIn.server.URL + path, Action: DownloadManifestResourceAction, Destination: tmpDir})
+		}
+		resources = append(resources, ManifestResource{Name: "spec.config.plugins[100]", Url: standIn.server.URL + "/" + strings.Repeat("missing", 1000) + ".jar", Sha256: "0000", Action: DownloadManifestResourceAction, Destination: tmpDir})
+
+		result := initializer.Run(context.Background(), &Manifest{Resources: resources})
+		Expect(result.Success).To(BeFalse())
+		Expect(result.SetUpResources).To(Equal(100))
+
+		path := filepath.Join(tmpDir, "result")
+		Expect(WriteResult(path, result)).To(Succeed())
+
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(len(content)).To(BeNumerically("<=", MaxResultSize))
+
+		parsedResult, err := ParseResult(string(content))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsedResult.SetUpResources).To(Equal(100))
+		Expect(parsedResult.GetFailedResource().Name).To(Equal("spec.config.plugins[100]"))
+		Expect(parsedResult.GetFailedResource().Reason).To(Equal(DownloadFailedResourceFailureReason))
+	})
+
+	It("caps the unverified resources listed in the termination message", func() {
+		result := &Result{Success: true}
+		for i := 0; i < 100; i++ {
+			result.UnverifiedResources = append(result.UnverifiedResources, fmt.Sprintf("spec.config.plugins[%d]", i))
+		}
+
+		path := filepath.Join(tmpDir, "result")
+		Expect(WriteResult(path, result)).To(Succeed())
+
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		parsedResult, err := ParseResult(string(content))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsedResult.UnverifiedResources).To(HaveLen(17))
+		Expect(parsedResult.UnverifiedResources[16]).To(Equal("and 84 more"))
+		Expect(result.UnverifiedResources).To(HaveLen(100))
+	})
+})
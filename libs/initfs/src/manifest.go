@@ -0,0 +1,113 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package initfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Manifest describes how to prepare the filesystem of a server or a
+// proxy before it starts. It is generated by the operator and read
+// by the init container.
+type Manifest struct {
+	// Files to write, in order, before downloading the resources.
+	Files []ManifestFile `json:"files,omitempty"`
+
+	// Resources to download, in order.
+	Resources []ManifestResource `json:"resources,omitempty"`
+}
+
+// ManifestFile is a file to write, either copied from another file
+// or with the given content.
+type ManifestFile struct {
+	// Path of the file to copy. Ignored when Content is set.
+	Source string `json:"source,omitempty"`
+
+	// Content of the file.
+	Content string `json:"content,omitempty"`
+
+	// Whether the source file is base64 encoded and should be
+	// decoded.
+	Base64 bool `json:"base64,omitempty"`
+
+	// Path of the file to write, its parent directories are created
+	// if needed.
+	Destination string `json:"destination"`
+}
+
+type ManifestResourceAction string
+
+const (
+	// Download the resource as a file in the destination directory.
+	DownloadManifestResourceAction ManifestResourceAction = "Download"
+
+	// Extract the resource, a gzipped tarball or a zip archive, in
+	// the destination directory.
+	ExtractManifestResourceAction ManifestResourceAction = "Extract"
+)
+
+// ManifestResource is a resource to download.
+type ManifestResource struct {
+	// Name identifying the resource in the result, e.g. the path of
+	// its ResourceRef in the spec.
+	Name string `json:"name"`
+
+	// URL to download the resource from.
+	Url string `json:"url,omitempty"`
+
+	// Local file to use instead of downloading the resource, e.g. a
+	// mounted ConfigMap key.
+	Path string `json:"path,omitempty"`
+
+	// Directory containing the username and password files to
+	// authenticate with, if any.
+	CredentialsDir string `json:"credentialsDir,omitempty"`
+
+	// Expected hex-encoded SHA-256 checksum of the resource, if
+	// known.
+	Sha256 string `json:"sha256,omitempty"`
+
+	// Expected hex-encoded SHA-512 checksum of the resource, if
+	// known.
+	Sha512 string `json:"sha512,omitempty"`
+
+	// Whether to verify the resource against the checksum files
+	// published next to it, suffixed by .sha256 or .sha1, like in
+	// Maven repositories. Resources without such files are not
+	// verified.
+	VerifyPublishedChecksums bool `json:"verifyPublishedChecksums,omitempty"`
+
+	Action ManifestResourceAction `json:"action"`
+
+	// Directory where the resource is downloaded or extracted.
+	Destination string `json:"destination"`
+
+	// Name of the downloaded file. Defaults to the last segment of
+	// the URL path, or to the name of the local file.
+	FileName string `json:"fileName,omitempty"`
+
+	// Path of a file marking the resource as already set up. When it
+	// exists, the resource is skipped, and it is created once the
+	// resource is set up. Useful for resources living on persistent
+	// volumes.
+	OnceMarker string `json:"onceMarker,omitempty"`
+}
+
+func ReadManifest(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+
+	return manifest, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package initfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Maximum size of the result, the one of the termination messages of
+// containers. Kubernetes truncates longer messages.
+const MaxResultSize = 4096
+
+// Maximum length of the error messages and number of unverified
+// resources in the written result, which keep it below MaxResultSize.
+const (
+	maxResultErrorLength         = 1024
+	maxResultUnverifiedResources = 16
+)
+
+// Result is written by the init container once done, as the
+// termination message of the container. It only records the resource
+// which failed and a summary of the others to fit in MaxResultSize.
+type Result struct {
+	// Whether every file and resource was set up.
+	Success bool `json:"success"`
+
+	// Error which stopped the init container, if any.
+	Error string `json:"error,omitempty"`
+
+	// Number of resources set up, including the skipped ones.
+	SetUpResources int `json:"setUpResources,omitempty"`
+
+	// Number of resources skipped because they were already set up.
+	SkippedResources int `json:"skippedResources,omitempty"`
+
+	// Names of the resources set up although their published
+	// checksums could not be verified, as none was published.
+	UnverifiedResources []string `json:"unverifiedResources,omitempty"`
+
+	// Resource which stopped the init container, if any.
+	FailedResource *ResourceResult `json:"failedResource,omitempty"`
+
+	// Results of every resource handled. They are only logged, as
+	// they do not fit in the termination message.
+	Resources []ResourceResult `json:"-"`
+}
+
+type ResourceResult struct {
+	Name string `json:"name"`
+
+	// Hex-encoded SHA-256 checksum of the downloaded resource.
+	Sha256 string `json:"sha256,omitempty"`
+
+	// Whether the resource was skipped because it was already set
+	// up.
+	Skipped bool `json:"skipped,omitempty"`
+
+	// Number of download attempts.
+	Attempts int `json:"attempts,omitempty"`
+
+	// Whether the published checksums of the resource could not be
+	// verified, as none was published.
+	Unverified bool `json:"unverified,omitempty"`
+
+	// Machine-readable reason of the failure, if any.
+	Reason ResourceFailureReason `json:"reason,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+type ResourceFailureReason string
+
+const (
+	DownloadFailedResourceFailureReason   ResourceFailureReason = "DownloadFailed"
+	ChecksumMismatchResourceFailureReason ResourceFailureReason = "ChecksumMismatch"
+	SetUpFailedResourceFailureReason      ResourceFailureReason = "SetUpFailed"
+)
+
+// Returns the result of the resource which failed, if any.
+func (r *Result) GetFailedResource() *ResourceResult {
+	return r.FailedResource
+}
+
+// Writes the result, shortening its error messages to fit in
+// MaxResultSize.
+func WriteResult(path string, result *Result) error {
+	compactResult := *result
+	compactResult.Error = truncateMessage(compactResult.Error, maxResultErrorLength)
+	if compactResult.FailedResource != nil {
+		failedResource := *compactResult.FailedResource
+		failedResource.Name = truncateMessage(failedResource.Name, maxResultErrorLength)
+		failedResource.Error = truncateMessage(failedResource.Error, maxResultErrorLength)
+		compactResult.FailedResource = &failedResource
+	}
+
+	if len(compactResult.UnverifiedResources) > maxResultUnverifiedResources {
+		unverifiedResources := append([]string{}, compactResult.UnverifiedResources[:maxResultUnverifiedResources]...)
+		compactResult.UnverifiedResources = append(unverifiedResources, fmt.Sprintf("and %d more", len(compactResult.UnverifiedResources)-maxResultUnverifiedResources))
+	}
+
+	content, err := json.Marshal(&compactResult)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
+
+func truncateMessage(message string, maxLength int) string {
+	if len(message) <= maxLength {
+		return message
+	}
+	return strings.ToValidUTF8(message[:maxLength-3], "") + "..."
+}
+
+func ParseResult(content string) (*Result, error) {
+	result := &Result{}
+	if err := json.Unmarshal([]byte(content), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+// Returns the name of the Deployment, Service and
+// PersistentVolumeClaim of the artifact cache of a cluster.
+func GetArtifactCacheName(cluster *v1alpha1.MinecraftCluster) string {
+	return fmt.Sprintf("%s-cache", cluster.Name)
+}
+
+// Returns the URL of the artifact cache of a cluster, or an empty
+// string when the cluster has none.
+func GetArtifactCacheUrl(cluster *v1alpha1.MinecraftCluster) string {
+	if cluster == nil || cluster.Spec.Cache == nil {
+		return ""
+	}
+	return fmt.Sprintf("http://%s.%s.svc:%d", GetArtifactCacheName(cluster), cluster.Namespace, ArtifactCachePort)
+}
+
+// Returns the resource to download from the artifact cache at the
+// given URL instead of its source, when it can be cached. The cache
+// verifies the checksums published next to the resource in place of
+// the init container, which still verifies the expected ones.
+func (r *ResolvedResourceRef) ThroughArtifactCache(cacheUrl string) ResolvedResourceRef {
+	fileName := r.getCachedFileName()
+	if cacheUrl == "" || !r.isCacheable() || fileName == "" {
+		return *r
+	}
+
+	query := url.Values{"url": {r.Url}}
+	if r.Sha256 != "" {
+		query.Set("sha256", r.Sha256)
+	}
+	if r.Sha512 != "" {
+		query.Set("sha512", r.Sha512)
+	}
+	if r.VerifyPublishedChecksums {
+		query.Set("verifyPublishedChecksums", "true")
+	}
+
+	cachedRef := *r
+	cachedRef.Url = fmt.Sprintf("%s/artifacts/%s?%s", cacheUrl, url.PathEscape(fileName), query.Encode())
+	cachedRef.VerifyPublishedChecksums = false
+	cachedRef.FileName = fileName
+	return cachedRef
+}
+
+// Returns a list of resources to download from the artifact cache
+// at the given URL when they can be cached.
+func ThroughArtifactCache(refs []ResolvedResourceRef, cacheUrl string) []ResolvedResourceRef {
+	cachedRefs := make([]ResolvedResourceRef, 0, len(refs))
+	for i := range refs {
+		cachedRefs = append(cachedRefs, refs[i].ThroughArtifactCache(cacheUrl))
+	}
+	return cachedRefs
+}
+
+// Only resources which will not change and are not private are
+// shared through the cache.
+func (r *ResolvedResourceRef) isCacheable() bool {
+	if r.Url == "" || r.CredentialsSecretName != "" || r.ConfigMapName != "" || r.SecretName != "" {
+		return false
+	}
+	return r.Version != "" || r.Sha256 != "" || r.Sha512 != ""
+}
+
+func (r *ResolvedResourceRef) getCachedFileName() string {
+	if r.FileName != "" {
+		return path.Base(r.FileName)
+	}
+
+	parsedUrl, err := url.Parse(r.Url)
+	if err != nil {
+		return ""
+	}
+	fileName := path.Base(parsedUrl.Path)
+	if fileName == "/" || fileName == "." {
+		return ""
+	}
+	return fileName
+}
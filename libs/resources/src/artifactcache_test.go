@@ -0,0 +1,58 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("Artifact cache", func() {
+	cacheUrl := "http://my-cluster-cache.default.svc:8080"
+
+	It("gives the URL of the cache of a cluster", func() {
+		cluster := &v1alpha1.MinecraftCluster{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"}}
+		Expect(GetArtifactCacheUrl(cluster)).To(BeEmpty())
+
+		cluster.Spec.Cache = &v1alpha1.MinecraftClusterCacheSpec{}
+		Expect(GetArtifactCacheUrl(cluster)).To(Equal(cacheUrl))
+	})
+
+	It("downloads versioned resources through the cache", func() {
+		ref := &ResolvedResourceRef{Url: "https://example.com/plugins/plugin-1.0.jar", Version: "1.0", Sha256: "aaaa", VerifyPublishedChecksums: true}
+
+		cachedRef := ref.ThroughArtifactCache(cacheUrl)
+
+		Expect(cachedRef.Url).To(Equal(cacheUrl + "/artifacts/plugin-1.0.jar?sha256=aaaa&url=https%3A%2F%2Fexample.com%2Fplugins%2Fplugin-1.0.jar&verifyPublishedChecksums=true"))
+		Expect(cachedRef.Sha256).To(Equal("aaaa"))
+		Expect(cachedRef.VerifyPublishedChecksums).To(BeFalse())
+		Expect(cachedRef.FileName).To(Equal("plugin-1.0.jar"))
+	})
+
+	It("keeps the file name of the resource", func() {
+		ref := &ResolvedResourceRef{Url: "https://registry.example.com/v2/plugins/blobs/sha256:aaaa", Sha256: "aaaa", FileName: "plugin.jar"}
+
+		Expect(ref.ThroughArtifactCache(cacheUrl).Url).To(HavePrefix(cacheUrl + "/artifacts/plugin.jar?"))
+	})
+
+	DescribeTable("downloads other resources from their source",
+		func(ref ResolvedResourceRef) {
+			Expect(ref.ThroughArtifactCache(cacheUrl)).To(Equal(ref))
+		},
+		Entry("without version nor checksum", ResolvedResourceRef{Url: "https://example.com/latest.jar"}),
+		Entry("with credentials", ResolvedResourceRef{Url: "https://example.com/plugin-1.0.jar", Version: "1.0", CredentialsSecretName: "credentials"}),
+		Entry("from a ConfigMap", ResolvedResourceRef{Url: "configmap://plugins/plugin.jar", Sha256: "aaaa", ConfigMapName: "plugins", Key: "plugin.jar"}),
+	)
+
+	It("does nothing without cache", func() {
+		ref := &ResolvedResourceRef{Url: "https://example.com/plugin-1.0.jar", Version: "1.0"}
+
+		Expect(ref.ThroughArtifactCache("")).To(Equal(*ref))
+	})
+})
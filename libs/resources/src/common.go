@@ -11,6 +11,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// Directory where the init manifest is mounted in the init containers.
+const InitManifestDir = "/mnt/shulker/init"
+
+// Port the artifact cache of the clusters listens on.
+const ArtifactCachePort = 8080
+
 type ResourceBuilder interface {
 	Build() (client.Object, error)
 	Update(client.Object) error
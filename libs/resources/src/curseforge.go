@@ -0,0 +1,86 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var curseForgeModLoaderTypes = map[v1alpha1.ResourceRefCurseForgeModLoader]int{
+	v1alpha1.ResourceRefCurseForgeForgeModLoader:    1,
+	v1alpha1.ResourceRefCurseForgeFabricModLoader:   4,
+	v1alpha1.ResourceRefCurseForgeQuiltModLoader:    5,
+	v1alpha1.ResourceRefCurseForgeNeoForgeModLoader: 6,
+}
+
+type curseForgeFile struct {
+	Id          int    `json:"id"`
+	DisplayName string `json:"displayName"`
+	DownloadUrl string `json:"downloadUrl"`
+}
+
+func (r *ResourceRefResolver) resolveCurseForgeRef(curseForgeSelector *v1alpha1.ResourceRefCurseForgeSelector) (*ResolvedResourceRef, error) {
+	apiKey, err := r.getSecretKey(curseForgeSelector.ApiKeySecretName, "apiKey")
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{"X-Api-Key": []string{apiKey}}
+
+	file, err := r.getCurseForgeFile(curseForgeSelector, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.DownloadUrl == "" {
+		return nil, fmt.Errorf("file %d of CurseForge project %d cannot be downloaded by third-parties", file.Id, curseForgeSelector.ProjectId)
+	}
+
+	return &ResolvedResourceRef{
+		Url:     file.DownloadUrl,
+		Version: file.DisplayName,
+	}, nil
+}
+
+func (r *ResourceRefResolver) getCurseForgeFile(curseForgeSelector *v1alpha1.ResourceRefCurseForgeSelector, header http.Header) (*curseForgeFile, error) {
+	filesUrl := fmt.Sprintf("%s/v1/mods/%d/files", r.getSourceAPIs().CurseForge, curseForgeSelector.ProjectId)
+
+	if curseForgeSelector.FileId != 0 {
+		response := struct {
+			Data curseForgeFile `json:"data"`
+		}{}
+		if err := r.getSourceJSON(fmt.Sprintf("%s/%d", filesUrl, curseForgeSelector.FileId), nil, header, &response); err != nil {
+			return nil, err
+		}
+		return &response.Data, nil
+	}
+
+	query := url.Values{}
+	query.Set("pageSize", "1")
+	if curseForgeSelector.GameVersion != "" {
+		query.Set("gameVersion", curseForgeSelector.GameVersion)
+	}
+	if curseForgeSelector.ModLoader != "" {
+		query.Set("modLoaderType", strconv.Itoa(curseForgeModLoaderTypes[curseForgeSelector.ModLoader]))
+	}
+
+	// Files are listed from the most recent one
+	response := struct {
+		Data []curseForgeFile `json:"data"`
+	}{}
+	if err := r.getSourceJSON(fmt.Sprintf("%s?%s", filesUrl, query.Encode()), nil, header, &response); err != nil {
+		return nil, err
+	}
+
+	if len(response.Data) == 0 {
+		return nil, fmt.Errorf("no file of CurseForge project %d matches the selector", curseForgeSelector.ProjectId)
+	}
+	return &response.Data[0], nil
+}
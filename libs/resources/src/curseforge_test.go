@@ -0,0 +1,67 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("CurseForge source", func() {
+	var standIn *apiStandIn
+	var resolver *ResourceRefResolver
+
+	BeforeEach(func() {
+		standIn = newAPIStandIn()
+		standIn.responses["/v1/mods/1234/files"] = `{"data": [{"id": 42, "displayName": "Mod 1.2.0", "downloadUrl": "https://edge.forgecdn.net/files/mod-1.2.0.jar"}]}`
+		standIn.responses["/v1/mods/1234/files/41"] = `{"data": {"id": 41, "displayName": "Mod 1.1.0", "downloadUrl": null}}`
+		resolver = standIn.newResolver(newTestSecret("curseforge", map[string]string{"apiKey": "key"}))
+	})
+
+	AfterEach(func() {
+		standIn.server.Close()
+	})
+
+	resolveCurseForgeRef := func(selector v1alpha1.ResourceRefCurseForgeSelector) (*ResolvedResourceRef, error) {
+		selector.ProjectId = 1234
+		selector.ApiKeySecretName = "curseforge"
+		return resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{CurseForgeRef: &selector},
+		})
+	}
+
+	It("resolves the most recent file with the API key", func() {
+		resolvedRef, err := resolveCurseForgeRef(v1alpha1.ResourceRefCurseForgeSelector{
+			ModLoader:   v1alpha1.ResourceRefCurseForgeFabricModLoader,
+			GameVersion: "1.20.1",
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal("https://edge.forgecdn.net/files/mod-1.2.0.jar"))
+		Expect(resolvedRef.Version).To(Equal("Mod 1.2.0"))
+
+		request := standIn.getLastRequest()
+		Expect(request.Header.Get("X-Api-Key")).To(Equal("key"))
+		Expect(request.URL.Query().Get("modLoaderType")).To(Equal("4"))
+		Expect(request.URL.Query().Get("gameVersion")).To(Equal("1.20.1"))
+	})
+
+	It("fails on files which cannot be distributed", func() {
+		_, err := resolveCurseForgeRef(v1alpha1.ResourceRefCurseForgeSelector{FileId: 41})
+
+		Expect(err).To(MatchError(ContainSubstring("cannot be downloaded by third-parties")))
+	})
+
+	It("fails without the API key", func() {
+		_, err := resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{CurseForgeRef: &v1alpha1.ResourceRefCurseForgeSelector{ProjectId: 1234, ApiKeySecretName: "missing"}},
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("failed to get credentials Secret missing")))
+	})
+})
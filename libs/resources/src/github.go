@@ -0,0 +1,105 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+type gitHubRelease struct {
+	TagName    string `json:"tag_name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	Assets     []struct {
+		Name               string `json:"name"`
+		BrowserDownloadUrl string `json:"browser_download_url"`
+		Digest             string `json:"digest"`
+	} `json:"assets"`
+}
+
+func (r *ResourceRefResolver) resolveGitHubReleaseRef(gitHubSelector *v1alpha1.ResourceRefGitHubReleaseSelector) (*ResolvedResourceRef, error) {
+	var credentials *sourceCredentials
+	if gitHubSelector.CredentialsSecretName != "" {
+		var err error
+		credentials, err = r.getCredentials(gitHubSelector.CredentialsSecretName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	release, err := r.getGitHubRelease(gitHubSelector, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, asset := range release.Assets {
+		var matches bool
+		if gitHubSelector.AssetName != "" {
+			matches, err = path.Match(gitHubSelector.AssetName, asset.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid asset name pattern %q: %v", gitHubSelector.AssetName, err)
+			}
+		} else {
+			matches = strings.HasSuffix(asset.Name, ".jar")
+		}
+		if !matches {
+			continue
+		}
+
+		return &ResolvedResourceRef{
+			Url:     asset.BrowserDownloadUrl,
+			Version: release.TagName,
+			Sha256:  strings.TrimPrefix(asset.Digest, "sha256:"),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no asset of GitHub release %s of %s matches the selector", release.TagName, gitHubSelector.Repository)
+}
+
+func (r *ResourceRefResolver) getGitHubRelease(gitHubSelector *v1alpha1.ResourceRefGitHubReleaseSelector, credentials *sourceCredentials) (*gitHubRelease, error) {
+	repositoryUrl := fmt.Sprintf("%s/repos/%s", r.getSourceAPIs().GitHub, gitHubSelector.Repository)
+	header := http.Header{"Accept": []string{"application/vnd.github+json"}}
+
+	if !isVersionRange(gitHubSelector.Tag) {
+		releaseUrl := repositoryUrl + "/releases/latest"
+		if gitHubSelector.Tag != "" {
+			releaseUrl = fmt.Sprintf("%s/releases/tags/%s", repositoryUrl, url.PathEscape(gitHubSelector.Tag))
+		}
+
+		release := &gitHubRelease{}
+		if err := r.getSourceJSON(releaseUrl, credentials, header, release); err != nil {
+			return nil, err
+		}
+		return release, nil
+	}
+
+	// Releases are listed from the most recent one
+	var releases []gitHubRelease
+	if err := r.getSourceJSON(repositoryUrl+"/releases?per_page=100", credentials, header, &releases); err != nil {
+		return nil, err
+	}
+
+	for i, release := range releases {
+		if release.Draft || release.Prerelease {
+			continue
+		}
+
+		matches, err := matchVersion(strings.TrimPrefix(release.TagName, "v"), gitHubSelector.Tag)
+		if err != nil {
+			return nil, err
+		} else if matches {
+			return &releases[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no release of %s matches the selector", gitHubSelector.Repository)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+const gitHubLatestRelease = `{
+  "tag_name": "v2.0.0",
+  "assets": [
+    {"name": "plugin-2.0.0-sources.zip", "browser_download_url": "https://github.com/owner/plugin/releases/download/v2.0.0/plugin-2.0.0-sources.zip"},
+    {"name": "plugin-2.0.0.jar", "browser_download_url": "https://github.com/owner/plugin/releases/download/v2.0.0/plugin-2.0.0.jar", "digest": "sha256:abcd"},
+    {"name": "plugin-2.0.0-all.jar", "browser_download_url": "https://github.com/owner/plugin/releases/download/v2.0.0/plugin-2.0.0-all.jar"}
+  ]
+}`
+
+const gitHubReleases = `[
+  {"tag_name": "v2.1.0-rc.1", "prerelease": true, "assets": [{"name": "plugin.jar", "browser_download_url": "https://github.com/owner/plugin/releases/download/v2.1.0-rc.1/plugin.jar"}]},
+  {"tag_name": "v2.0.0", "assets": [{"name": "plugin.jar", "browser_download_url": "https://github.com/owner/plugin/releases/download/v2.0.0/plugin.jar"}]},
+  {"tag_name": "v1.4.0", "assets": [{"name": "plugin.jar", "browser_download_url": "https://github.com/owner/plugin/releases/download/v1.4.0/plugin.jar"}]}
+]`
+
+var _ = Describe("GitHub Releases source", func() {
+	var standIn *apiStandIn
+	var resolver *ResourceRefResolver
+
+	BeforeEach(func() {
+		standIn = newAPIStandIn()
+		standIn.responses["/repos/owner/plugin/releases/latest"] = gitHubLatestRelease
+		standIn.responses["/repos/owner/plugin/releases/tags/v2.0.0"] = gitHubLatestRelease
+		standIn.responses["/repos/owner/plugin/releases"] = gitHubReleases
+		resolver = standIn.newResolver(newTestSecret("github-credentials", map[string]string{"username": "user", "password": "token"}))
+	})
+
+	AfterEach(func() {
+		standIn.server.Close()
+	})
+
+	resolveGitHubReleaseRef := func(selector v1alpha1.ResourceRefGitHubReleaseSelector) (*ResolvedResourceRef, error) {
+		selector.Repository = "owner/plugin"
+		return resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{GitHubReleaseRef: &selector},
+		})
+	}
+
+	It("resolves the jar asset of the latest release with its checksum", func() {
+		resolvedRef, err := resolveGitHubReleaseRef(v1alpha1.ResourceRefGitHubReleaseSelector{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal("https://github.com/owner/plugin/releases/download/v2.0.0/plugin-2.0.0.jar"))
+		Expect(resolvedRef.Version).To(Equal("v2.0.0"))
+		Expect(resolvedRef.Sha256).To(Equal("abcd"))
+	})
+
+	It("resolves assets matching a pattern in a tagged release", func() {
+		resolvedRef, err := resolveGitHubReleaseRef(v1alpha1.ResourceRefGitHubReleaseSelector{Tag: "v2.0.0", AssetName: "*-all.jar"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal("https://github.com/owner/plugin/releases/download/v2.0.0/plugin-2.0.0-all.jar"))
+	})
+
+	It("resolves the most recent release of a range, ignoring pre-releases", func() {
+		resolvedRef, err := resolveGitHubReleaseRef(v1alpha1.ResourceRefGitHubReleaseSelector{Tag: ">=1.0.0"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Version).To(Equal("v2.0.0"))
+	})
+
+	It("authenticates with the credentials Secret", func() {
+		_, err := resolveGitHubReleaseRef(v1alpha1.ResourceRefGitHubReleaseSelector{CredentialsSecretName: "github-credentials"})
+
+		Expect(err).NotTo(HaveOccurred())
+		username, password, ok := standIn.getLastRequest().BasicAuth()
+		Expect(ok).To(BeTrue())
+		Expect(username).To(Equal("user"))
+		Expect(password).To(Equal("token"))
+	})
+
+	It("fails when no asset matches", func() {
+		_, err := resolveGitHubReleaseRef(v1alpha1.ResourceRefGitHubReleaseSelector{AssetName: "*.zip.asc"})
+
+		Expect(err).To(MatchError(ContainSubstring("no asset of GitHub release v2.0.0")))
+	})
+})
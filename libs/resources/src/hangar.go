@@ -0,0 +1,94 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+const hangarVersionsPageSize = 25
+
+type hangarVersionsPage struct {
+	Pagination struct {
+		Count int `json:"count"`
+	} `json:"pagination"`
+	Result []struct {
+		Name      string `json:"name"`
+		Downloads map[string]struct {
+			FileInfo *struct {
+				Sha256Hash string `json:"sha256Hash"`
+			} `json:"fileInfo"`
+			DownloadUrl string `json:"downloadUrl"`
+			ExternalUrl string `json:"externalUrl"`
+		} `json:"downloads"`
+		PlatformDependencies map[string][]string `json:"platformDependencies"`
+	} `json:"result"`
+}
+
+func (r *ResourceRefResolver) resolveHangarRef(hangarSelector *v1alpha1.ResourceRefHangarSelector) (*ResolvedResourceRef, error) {
+	platform := string(hangarSelector.Platform)
+
+	// Versions are listed from the most recent one
+	for offset := 0; ; offset += hangarVersionsPageSize {
+		query := url.Values{}
+		query.Set("platform", platform)
+		query.Set("limit", strconv.Itoa(hangarVersionsPageSize))
+		query.Set("offset", strconv.Itoa(offset))
+
+		page := hangarVersionsPage{}
+		versionsUrl := fmt.Sprintf("%s/api/v1/projects/%s/versions?%s", r.getSourceAPIs().Hangar, url.PathEscape(hangarSelector.Project), query.Encode())
+		if err := r.getSourceJSON(versionsUrl, nil, nil, &page); err != nil {
+			return nil, err
+		}
+
+		for _, version := range page.Result {
+			download, ok := version.Downloads[platform]
+			if !ok {
+				continue
+			}
+
+			if hangarSelector.GameVersion != "" && !containsString(version.PlatformDependencies[platform], hangarSelector.GameVersion) {
+				continue
+			}
+
+			matches, err := matchVersion(version.Name, hangarSelector.Version)
+			if err != nil {
+				return nil, err
+			} else if !matches {
+				continue
+			}
+
+			resolvedRef := &ResolvedResourceRef{
+				Url:     download.DownloadUrl,
+				Version: version.Name,
+			}
+			// Versions hosted elsewhere have no known checksum
+			if resolvedRef.Url == "" {
+				resolvedRef.Url = download.ExternalUrl
+			} else if download.FileInfo != nil {
+				resolvedRef.Sha256 = download.FileInfo.Sha256Hash
+			}
+			return resolvedRef, nil
+		}
+
+		if len(page.Result) == 0 || offset+hangarVersionsPageSize >= page.Pagination.Count {
+			return nil, fmt.Errorf("no version of Hangar project %s matches the selector", hangarSelector.Project)
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+const hangarVersions = `{
+  "pagination": {"limit": 25, "offset": 0, "count": 3},
+  "result": [
+    {
+      "name": "3.0.0",
+      "downloads": {"VELOCITY": {"fileInfo": {"sha256Hash": "ffff"}, "downloadUrl": "https://hangarcdn.papermc.io/plugin-3.0.0-velocity.jar"}},
+      "platformDependencies": {"VELOCITY": ["3.2"]}
+    },
+    {
+      "name": "2.1.0",
+      "downloads": {"PAPER": {"fileInfo": {"sha256Hash": "eeee"}, "downloadUrl": "https://hangarcdn.papermc.io/plugin-2.1.0.jar"}},
+      "platformDependencies": {"PAPER": ["1.20", "1.20.1"]}
+    },
+    {
+      "name": "2.0.0",
+      "downloads": {"PAPER": {"fileInfo": null, "downloadUrl": null, "externalUrl": "https://example.com/plugin-2.0.0.jar"}},
+      "platformDependencies": {"PAPER": ["1.19.4"]}
+    }
+  ]
+}`
+
+var _ = Describe("Hangar source", func() {
+	var standIn *apiStandIn
+	var resolver *ResourceRefResolver
+
+	BeforeEach(func() {
+		standIn = newAPIStandIn()
+		standIn.responses["/api/v1/projects/plugin/versions"] = hangarVersions
+		resolver = standIn.newResolver()
+	})
+
+	AfterEach(func() {
+		standIn.server.Close()
+	})
+
+	resolveHangarRef := func(selector v1alpha1.ResourceRefHangarSelector) (*ResolvedResourceRef, error) {
+		selector.Project = "plugin"
+		selector.Platform = v1alpha1.ResourceRefHangarPaperPlatform
+		return resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{HangarRef: &selector},
+		})
+	}
+
+	It("resolves the most recent version of the platform with its checksum", func() {
+		resolvedRef, err := resolveHangarRef(v1alpha1.ResourceRefHangarSelector{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal("https://hangarcdn.papermc.io/plugin-2.1.0.jar"))
+		Expect(resolvedRef.Version).To(Equal("2.1.0"))
+		Expect(resolvedRef.Sha256).To(Equal("eeee"))
+		Expect(standIn.getLastRequest().URL.Query().Get("platform")).To(Equal("PAPER"))
+	})
+
+	It("resolves versions hosted elsewhere supporting the game version", func() {
+		resolvedRef, err := resolveHangarRef(v1alpha1.ResourceRefHangarSelector{GameVersion: "1.19.4"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal("https://example.com/plugin-2.0.0.jar"))
+		Expect(resolvedRef.Sha256).To(BeEmpty())
+	})
+
+	It("fails when no version matches", func() {
+		_, err := resolveHangarRef(v1alpha1.ResourceRefHangarSelector{Version: ">=3.0.0"})
+
+		Expect(err).To(MatchError(ContainSubstring("no version of Hangar project plugin")))
+	})
+})
@@ -0,0 +1,167 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	DefaultMinecraftServerImage = "itzg/minecraft-server:latest"
+	DefaultProxyImage           = "itzg/bungeecord:latest"
+
+	// Image of the init containers preparing the filesystem of the
+	// servers and proxies.
+	DefaultInitImage = "ghcr.io/iamblueslime/shulker-init:latest"
+
+	// Image of the backup sidecar of the servers.
+	DefaultBackupImage = "ghcr.io/iamblueslime/shulker-backup:latest"
+
+	// Image of the artifact cache of the clusters.
+	DefaultArtifactCacheImage = "ghcr.io/iamblueslime/shulker-cache:latest"
+
+	// Image of the sidecar probing the servers and proxies with the
+	// Server List Ping protocol.
+	DefaultProbeImage = "ghcr.io/iamblueslime/shulker-probe:latest"
+)
+
+// Images of the containers created by the operator, and how they are
+// pulled. The Pods of servers and proxies can override them.
+type Images struct {
+	MinecraftServer string
+	Proxy           string
+	Init            string
+	Backup          string
+	ArtifactCache   string
+	Probe           string
+
+	// Registry to pull all the images from instead of their own,
+	// applied by Complete.
+	Registry string
+
+	// Pull policy of all the images, defaults to the one of
+	// Kubernetes when empty.
+	PullPolicy corev1.PullPolicy
+
+	// Names of the Secrets to pull all the images with.
+	PullSecrets []string
+}
+
+// Returns the images published for this version of the operator.
+func NewDefaultImages() *Images {
+	return &Images{
+		MinecraftServer: DefaultMinecraftServerImage,
+		Proxy:           DefaultProxyImage,
+		Init:            DefaultInitImage,
+		Backup:          DefaultBackupImage,
+		ArtifactCache:   DefaultArtifactCacheImage,
+		Probe:           DefaultProbeImage,
+	}
+}
+
+// BindFlags registers the flags configuring the images, Complete
+// must be called once they are parsed.
+func (i *Images) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&i.MinecraftServer, "minecraft-server-image", i.MinecraftServer, "Image of the Minecraft servers.")
+	fs.StringVar(&i.Proxy, "proxy-image", i.Proxy, "Image of the proxies.")
+	fs.StringVar(&i.Init, "init-image", i.Init, "Image of the init containers of the servers and proxies.")
+	fs.StringVar(&i.Backup, "backup-image", i.Backup, "Image of the backup sidecar of the servers.")
+	fs.StringVar(&i.ArtifactCache, "artifact-cache-image", i.ArtifactCache, "Image of the artifact cache of the clusters.")
+	fs.StringVar(&i.Probe, "probe-image", i.Probe, "Image of the sidecar probing the servers and proxies with the Server List Ping protocol.")
+	fs.StringVar(&i.Registry, "image-registry", i.Registry, "Registry to pull all the images from instead of their own, e.g. a private mirror.")
+	fs.Func("image-pull-policy", "Pull policy of all the images, one of Always, Never or IfNotPresent.", func(value string) error {
+		switch policy := corev1.PullPolicy(value); policy {
+		case corev1.PullAlways, corev1.PullNever, corev1.PullIfNotPresent:
+			i.PullPolicy = policy
+			return nil
+		default:
+			return fmt.Errorf("unsupported pull policy %s", value)
+		}
+	})
+	fs.Func("image-pull-secrets", "Comma-separated names of the Secrets to pull all the images with.", func(value string) error {
+		i.PullSecrets = nil
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				i.PullSecrets = append(i.PullSecrets, name)
+			}
+		}
+		return nil
+	})
+}
+
+// Complete pulls all the images from the configured registry, if
+// any.
+func (i *Images) Complete() {
+	if i.Registry == "" {
+		return
+	}
+
+	for _, image := range []*string{&i.MinecraftServer, &i.Proxy, &i.Init, &i.Backup, &i.ArtifactCache, &i.Probe} {
+		*image = WithImageRegistry(*image, i.Registry)
+	}
+	i.Registry = ""
+}
+
+// Returns the references to the Secrets to pull the images with,
+// followed by the extra ones given.
+func (i *Images) GetPullSecrets(extra []corev1.LocalObjectReference) []corev1.LocalObjectReference {
+	if len(i.PullSecrets) == 0 && len(extra) == 0 {
+		return nil
+	}
+
+	pullSecrets := make([]corev1.LocalObjectReference, 0, len(i.PullSecrets)+len(extra))
+	for _, name := range i.PullSecrets {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+	return append(pullSecrets, extra...)
+}
+
+// Returns the image pulled from the given registry instead of its
+// own, or Docker Hub when it has none. Official Docker Hub images
+// keep their library/ prefix, as mirrors expect it.
+func WithImageRegistry(image string, registry string) string {
+	registry = strings.TrimSuffix(registry, "/")
+
+	name := image
+	if parts := strings.SplitN(image, "/", 2); len(parts) == 2 && isImageRegistry(parts[0]) {
+		name = parts[1]
+	} else if !strings.Contains(image, "/") {
+		name = "library/" + image
+	}
+
+	return fmt.Sprintf("%s/%s", registry, name)
+}
+
+// The first component of an image is its registry when it looks
+// like a host name.
+func isImageRegistry(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
+
+// Applies the pull policy and Secrets to the containers of a Pod
+// which have no pull policy of their own, the given ones overriding
+// or adding to the configured ones.
+func (i *Images) ApplyPullOptions(podSpec *corev1.PodSpec, pullPolicy corev1.PullPolicy, pullSecrets []corev1.LocalObjectReference) {
+	if pullPolicy == "" {
+		pullPolicy = i.PullPolicy
+	}
+
+	for j := range podSpec.InitContainers {
+		if podSpec.InitContainers[j].ImagePullPolicy == "" {
+			podSpec.InitContainers[j].ImagePullPolicy = pullPolicy
+		}
+	}
+	for j := range podSpec.Containers {
+		if podSpec.Containers[j].ImagePullPolicy == "" {
+			podSpec.Containers[j].ImagePullPolicy = pullPolicy
+		}
+	}
+	podSpec.ImagePullSecrets = i.GetPullSecrets(pullSecrets)
+}
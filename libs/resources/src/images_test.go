@@ -0,0 +1,73 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"flag"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Images", func() {
+	DescribeTable("pulls images from another registry",
+		func(image string, expected string) {
+			Expect(WithImageRegistry(image, "registry.example.com/mirror/")).To(Equal(expected))
+		},
+		Entry("from Docker Hub", "itzg/minecraft-server:latest", "registry.example.com/mirror/itzg/minecraft-server:latest"),
+		Entry("official from Docker Hub", "alpine:latest", "registry.example.com/mirror/library/alpine:latest"),
+		Entry("from another registry", "ghcr.io/iamblueslime/shulker-init:latest", "registry.example.com/mirror/iamblueslime/shulker-init:latest"),
+		Entry("from a registry with a port", "localhost:5000/shulker-init@sha256:aaaa", "registry.example.com/mirror/shulker-init@sha256:aaaa"),
+	)
+
+	It("is configured with flags", func() {
+		images := NewDefaultImages()
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		images.BindFlags(fs)
+
+		Expect(fs.Parse([]string{
+			"--proxy-image=example.com/proxy:1.0",
+			"--image-registry=registry.example.com",
+			"--image-pull-policy=Always",
+			"--image-pull-secrets=first, second",
+		})).To(Succeed())
+		images.Complete()
+
+		Expect(images.MinecraftServer).To(Equal("registry.example.com/itzg/minecraft-server:latest"))
+		Expect(images.Proxy).To(Equal("registry.example.com/proxy:1.0"))
+		Expect(images.PullPolicy).To(Equal(corev1.PullAlways))
+		Expect(images.PullSecrets).To(Equal([]string{"first", "second"}))
+
+		Expect(fs.Parse([]string{"--image-pull-policy=Sometimes"})).NotTo(Succeed())
+	})
+
+	It("applies the pull options to all the containers", func() {
+		images := &Images{PullPolicy: corev1.PullIfNotPresent, PullSecrets: []string{"operator"}}
+		newPodSpec := func() *corev1.PodSpec {
+			return &corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init"}},
+				Containers:     []corev1.Container{{Name: "main"}, {Name: "sidecar", ImagePullPolicy: corev1.PullAlways}},
+			}
+		}
+
+		podSpec := newPodSpec()
+		images.ApplyPullOptions(podSpec, corev1.PullNever, []corev1.LocalObjectReference{{Name: "pod"}})
+
+		Expect(podSpec.InitContainers[0].ImagePullPolicy).To(Equal(corev1.PullNever))
+		Expect(podSpec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullNever))
+		Expect(podSpec.Containers[1].ImagePullPolicy).To(Equal(corev1.PullAlways))
+		Expect(podSpec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "operator"}, {Name: "pod"}}))
+
+		podSpec = newPodSpec()
+		images.ApplyPullOptions(podSpec, "", nil)
+
+		Expect(podSpec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+		Expect(podSpec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "operator"}}))
+	})
+})
@@ -0,0 +1,214 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+const mebibyte = 1024 * 1024
+
+// Heaps from this size on use the larger G1 regions of Aikar's
+// flags.
+const aikarLargeHeapMebibytes = 12 * 1024
+
+var releaseVersionRegexp = regexp.MustCompile(`^1\.[0-9]+(\.[0-9]+)?$`)
+
+// Returns the memory the container can use: its limit, or its
+// request when it has no limit.
+func GetContainerMemory(resources *corev1.ResourceRequirements) *resource.Quantity {
+	if resources == nil {
+		return nil
+	}
+
+	if memory, ok := resources.Limits[corev1.ResourceMemory]; ok && !memory.IsZero() {
+		return &memory
+	}
+	if memory, ok := resources.Requests[corev1.ResourceMemory]; ok && !memory.IsZero() {
+		return &memory
+	}
+	return nil
+}
+
+// Returns the size of the heap in mebibytes, or zero when it
+// cannot be known before the JVM starts.
+func GetJvmHeapMebibytes(jvm *v1alpha1.JvmSpec, resources *corev1.ResourceRequirements) int64 {
+	if jvm.GetHeapPolicy() == v1alpha1.JvmHeapFixed {
+		if jvm.HeapSize == nil {
+			return 0
+		}
+		return jvm.HeapSize.Value() / mebibyte
+	}
+
+	memory := GetContainerMemory(resources)
+	if memory == nil {
+		return 0
+	}
+	return memory.Value() * int64(jvm.GetHeapPercentage()) / 100 / mebibyte
+}
+
+// Returns the environment variables of the itzg images sizing the
+// heap and tuning the JVM. The initial and maximum sizes of the heap
+// are always the same, as the flag presets expect.
+func GetJvmEnv(jvm *v1alpha1.JvmSpec, resources *corev1.ResourceRequirements, defaultPreset v1alpha1.JvmFlagsPreset) []corev1.EnvVar {
+	heapMebibytes := GetJvmHeapMebibytes(jvm, resources)
+
+	memory := ""
+	xxOpts := []string{}
+	if heapMebibytes > 0 {
+		memory = fmt.Sprintf("%dM", heapMebibytes)
+	} else {
+		// Without a known memory size, the JVM sizes the heap from
+		// the memory it sees when starting
+		percentage := jvm.GetHeapPercentage()
+		xxOpts = append(xxOpts,
+			fmt.Sprintf("-XX:InitialRAMPercentage=%d", percentage),
+			fmt.Sprintf("-XX:MaxRAMPercentage=%d", percentage),
+		)
+	}
+
+	preset := defaultPreset
+	if jvm != nil && jvm.FlagsPreset != "" {
+		preset = jvm.FlagsPreset
+	}
+	xxOpts = append(xxOpts, getJvmPresetFlags(preset, heapMebibytes)...)
+
+	env := []corev1.EnvVar{
+		{
+			Name:  "MEMORY",
+			Value: memory,
+		},
+		{
+			Name:  "JVM_XX_OPTS",
+			Value: strings.Join(xxOpts, " "),
+		},
+	}
+
+	if jvm != nil && len(jvm.ExtraArgs) > 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  "JVM_OPTS",
+			Value: strings.Join(jvm.ExtraArgs, " "),
+		})
+	}
+
+	return env
+}
+
+func getJvmPresetFlags(preset v1alpha1.JvmFlagsPreset, heapMebibytes int64) []string {
+	switch preset {
+	case v1alpha1.JvmFlagsAikar:
+		// See https://docs.papermc.io/paper/aikars-flags
+		newSizePercent, maxNewSizePercent, regionSize, reservePercent, initiatingHeapOccupancyPercent := 30, 40, "8M", 20, 15
+		if heapMebibytes >= aikarLargeHeapMebibytes {
+			newSizePercent, maxNewSizePercent, regionSize, reservePercent, initiatingHeapOccupancyPercent = 40, 50, "16M", 15, 20
+		}
+
+		return []string{
+			"-XX:+UseG1GC",
+			"-XX:+ParallelRefProcEnabled",
+			"-XX:MaxGCPauseMillis=200",
+			"-XX:+UnlockExperimentalVMOptions",
+			"-XX:+DisableExplicitGC",
+			"-XX:+AlwaysPreTouch",
+			fmt.Sprintf("-XX:G1NewSizePercent=%d", newSizePercent),
+			fmt.Sprintf("-XX:G1MaxNewSizePercent=%d", maxNewSizePercent),
+			fmt.Sprintf("-XX:G1HeapRegionSize=%s", regionSize),
+			fmt.Sprintf("-XX:G1ReservePercent=%d", reservePercent),
+			"-XX:G1HeapWastePercent=5",
+			"-XX:G1MixedGCCountTarget=4",
+			fmt.Sprintf("-XX:InitiatingHeapOccupancyPercent=%d", initiatingHeapOccupancyPercent),
+			"-XX:G1MixedGCLiveThresholdPercent=90",
+			"-XX:G1RSetUpdatingPauseTimePercent=5",
+			"-XX:SurvivorRatio=32",
+			"-XX:+PerfDisableSharedMem",
+			"-XX:MaxTenuringThreshold=1",
+			"-Dusing.aikars.flags=https://mcflags.emc.gs",
+			"-Daikars.new.flags=true",
+		}
+
+	case v1alpha1.JvmFlagsVelocity:
+		// See https://docs.papermc.io/velocity/tuning
+		return []string{
+			"-XX:+UseG1GC",
+			"-XX:G1HeapRegionSize=4M",
+			"-XX:+UnlockExperimentalVMOptions",
+			"-XX:+ParallelRefProcEnabled",
+			"-XX:+AlwaysPreTouch",
+			"-XX:MaxInlineLevel=15",
+		}
+	}
+
+	return nil
+}
+
+// Returns the tag of the itzg images running the given major version
+// of Java.
+func GetJavaImageTag(javaVersion string) string {
+	return fmt.Sprintf("java%s", javaVersion)
+}
+
+// Returns the major version of Java required to run a release of
+// Minecraft, or an empty string when the version is not a release,
+// e.g. latest or a snapshot.
+func GetJavaVersionForMinecraftVersion(minecraftVersion string) string {
+	if !releaseVersionRegexp.MatchString(minecraftVersion) {
+		return ""
+	}
+
+	if compareVersions(minecraftVersion, "1.17") < 0 {
+		return "8"
+	} else if compareVersions(minecraftVersion, "1.18") < 0 {
+		return "16"
+	} else if compareVersions(minecraftVersion, "1.20.5") < 0 {
+		return "17"
+	}
+	return "21"
+}
+
+// Returns the image of the servers running the given major version
+// of Java. Without one, the version required by Minecraft is only
+// derived for the itzg image, possibly mirrored, as the tags of custom
+// images are unknown.
+func GetMinecraftServerImage(image string, javaVersion string, minecraftVersion string) string {
+	if javaVersion == "" && isMinecraftServerImage(image) {
+		javaVersion = GetJavaVersionForMinecraftVersion(minecraftVersion)
+	}
+
+	if javaVersion == "" {
+		return image
+	}
+	return WithImageTag(image, GetJavaImageTag(javaVersion))
+}
+
+func isMinecraftServerImage(image string) bool {
+	repository, _, _ := strings.Cut(image, "@")
+	if colon := strings.LastIndex(repository, ":"); colon > strings.LastIndex(repository, "/") {
+		repository = repository[:colon]
+	}
+
+	name := strings.TrimSuffix(DefaultMinecraftServerImage, ":latest")
+	return repository == name || strings.HasSuffix(repository, "/"+name)
+}
+
+// Returns the image with its tag replaced. Images pinned by digest
+// are kept as they are.
+func WithImageTag(image string, tag string) string {
+	if strings.Contains(image, "@") {
+		return image
+	}
+
+	if colon := strings.LastIndex(image, ":"); colon > strings.LastIndex(image, "/") {
+		image = image[:colon]
+	}
+	return fmt.Sprintf("%s:%s", image, tag)
+}
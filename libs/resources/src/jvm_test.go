@@ -0,0 +1,107 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("JVM", func() {
+	getEnvValue := func(env []corev1.EnvVar, name string) string {
+		for _, envVar := range env {
+			if envVar.Name == name {
+				return envVar.Value
+			}
+		}
+		return "<unset>"
+	}
+
+	memoryResources := func(limit string) *corev1.ResourceRequirements {
+		return &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(limit)},
+		}
+	}
+
+	It("gives the heap a share of the memory limit", func() {
+		env := GetJvmEnv(nil, memoryResources("4Gi"), v1alpha1.JvmFlagsNone)
+
+		Expect(getEnvValue(env, "MEMORY")).To(Equal("3072M"))
+		Expect(getEnvValue(env, "JVM_XX_OPTS")).To(BeEmpty())
+		Expect(getEnvValue(env, "JVM_OPTS")).To(Equal("<unset>"))
+	})
+
+	It("falls back to the memory request", func() {
+		resources := &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+		}
+		jvm := &v1alpha1.JvmSpec{HeapPercentage: 50}
+
+		Expect(getEnvValue(GetJvmEnv(jvm, resources, v1alpha1.JvmFlagsNone), "MEMORY")).To(Equal("1024M"))
+	})
+
+	It("lets the JVM size the heap when the memory is unknown", func() {
+		env := GetJvmEnv(nil, nil, v1alpha1.JvmFlagsNone)
+
+		Expect(getEnvValue(env, "MEMORY")).To(BeEmpty())
+		Expect(getEnvValue(env, "JVM_XX_OPTS")).To(Equal("-XX:InitialRAMPercentage=75 -XX:MaxRAMPercentage=75"))
+	})
+
+	It("uses a fixed heap size", func() {
+		heapSize := resource.MustParse("1536Mi")
+		jvm := &v1alpha1.JvmSpec{HeapPolicy: v1alpha1.JvmHeapFixed, HeapSize: &heapSize, ExtraArgs: []string{"-Dfoo=bar", "-Dbaz=qux"}}
+		env := GetJvmEnv(jvm, memoryResources("4Gi"), v1alpha1.JvmFlagsNone)
+
+		Expect(getEnvValue(env, "MEMORY")).To(Equal("1536M"))
+		Expect(getEnvValue(env, "JVM_OPTS")).To(Equal("-Dfoo=bar -Dbaz=qux"))
+	})
+
+	It("adds the flags of the preset", func() {
+		Expect(getEnvValue(GetJvmEnv(nil, memoryResources("4Gi"), v1alpha1.JvmFlagsAikar), "JVM_XX_OPTS")).To(ContainSubstring("-XX:G1HeapRegionSize=8M"))
+		Expect(getEnvValue(GetJvmEnv(nil, memoryResources("20Gi"), v1alpha1.JvmFlagsAikar), "JVM_XX_OPTS")).To(ContainSubstring("-XX:G1HeapRegionSize=16M"))
+
+		jvm := &v1alpha1.JvmSpec{FlagsPreset: v1alpha1.JvmFlagsVelocity}
+		Expect(getEnvValue(GetJvmEnv(jvm, nil, v1alpha1.JvmFlagsAikar), "JVM_XX_OPTS")).To(ContainSubstring("-XX:MaxInlineLevel=15"))
+	})
+
+	DescribeTable("picks the Java version of Minecraft versions",
+		func(minecraftVersion string, expected string) {
+			Expect(GetJavaVersionForMinecraftVersion(minecraftVersion)).To(Equal(expected))
+		},
+		Entry("before 1.17", "1.16.5", "8"),
+		Entry("1.17", "1.17.1", "16"),
+		Entry("before 1.20.5", "1.20.4", "17"),
+		Entry("from 1.20.5", "1.20.5", "21"),
+		Entry("latest", "latest", ""),
+		Entry("snapshot", "23w13a", ""),
+	)
+
+	DescribeTable("picks the image of servers",
+		func(image string, javaVersion string, minecraftVersion string, expected string) {
+			Expect(GetMinecraftServerImage(image, javaVersion, minecraftVersion)).To(Equal(expected))
+		},
+		Entry("default image", DefaultMinecraftServerImage, "", "1.20.4", "itzg/minecraft-server:java17"),
+		Entry("mirrored default image", "registry.example.com/mirror/itzg/minecraft-server:latest", "", "1.16.5", "registry.example.com/mirror/itzg/minecraft-server:java8"),
+		Entry("default image with an unknown version", DefaultMinecraftServerImage, "", "latest", DefaultMinecraftServerImage),
+		Entry("custom image", "myreg/custom:1.2", "", "1.20.4", "myreg/custom:1.2"),
+		Entry("custom image with a Java version", "myreg/custom:1.2", "21", "1.20.4", "myreg/custom:java21"),
+		Entry("default image with a Java version", DefaultMinecraftServerImage, "21", "1.16.5", "itzg/minecraft-server:java21"),
+	)
+
+	DescribeTable("replaces the tag of images",
+		func(image string, expected string) {
+			Expect(WithImageTag(image, "java17")).To(Equal(expected))
+		},
+		Entry("with a tag", "itzg/minecraft-server:latest", "itzg/minecraft-server:java17"),
+		Entry("without a tag", "itzg/minecraft-server", "itzg/minecraft-server:java17"),
+		Entry("from a registry with a port", "localhost:5000/minecraft-server", "localhost:5000/minecraft-server:java17"),
+		Entry("pinned by digest", "itzg/minecraft-server@sha256:aaaa", "itzg/minecraft-server@sha256:aaaa"),
+	)
+})
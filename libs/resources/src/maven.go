@@ -0,0 +1,131 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+const (
+	mavenLatestVersion  = "LATEST"
+	mavenReleaseVersion = "RELEASE"
+	mavenSnapshotSuffix = "-SNAPSHOT"
+)
+
+type mavenMetadata struct {
+	Versioning struct {
+		Latest   string `xml:"latest"`
+		Release  string `xml:"release"`
+		Snapshot struct {
+			Timestamp   string `xml:"timestamp"`
+			BuildNumber int    `xml:"buildNumber"`
+		} `xml:"snapshot"`
+		SnapshotVersions []struct {
+			Classifier string `xml:"classifier"`
+			Extension  string `xml:"extension"`
+			Value      string `xml:"value"`
+		} `xml:"snapshotVersions>snapshotVersion"`
+	} `xml:"versioning"`
+}
+
+func (r *ResourceRefResolver) resolveMavenRef(mavenSelector *v1alpha1.ResourceRefMavenSelector) (*ResolvedResourceRef, error) {
+	var credentials *sourceCredentials
+	if mavenSelector.CredentialsSecretName != "" {
+		var err error
+		credentials, err = r.getCredentials(mavenSelector.CredentialsSecretName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	artifactUrl := fmt.Sprintf(
+		"%s/%s/%s",
+		strings.TrimSuffix(mavenSelector.Repository, "/"),
+		strings.ReplaceAll(mavenSelector.GroupId, ".", "/"),
+		mavenSelector.ArtifactId,
+	)
+
+	version := mavenSelector.Version
+	if version == mavenLatestVersion || version == mavenReleaseVersion {
+		metadata, err := r.getMavenMetadata(artifactUrl+"/maven-metadata.xml", credentials)
+		if err != nil {
+			return nil, err
+		}
+
+		if version == mavenLatestVersion {
+			version = metadata.Versioning.Latest
+		} else {
+			version = metadata.Versioning.Release
+		}
+		if version == "" {
+			return nil, fmt.Errorf("no %s version found for Maven artifact %s:%s", mavenSelector.Version, mavenSelector.GroupId, mavenSelector.ArtifactId)
+		}
+	}
+
+	fileVersion := version
+	if strings.HasSuffix(version, mavenSnapshotSuffix) {
+		metadata, err := r.getMavenMetadata(fmt.Sprintf("%s/%s/maven-metadata.xml", artifactUrl, version), credentials)
+		if err != nil {
+			return nil, err
+		}
+		fileVersion = getMavenSnapshotFileVersion(metadata, version, mavenSelector.Classifier, mavenSelector.GetExtension())
+	}
+
+	fileName := fmt.Sprintf("%s-%s", mavenSelector.ArtifactId, fileVersion)
+	if mavenSelector.Classifier != "" {
+		fileName += "-" + mavenSelector.Classifier
+	}
+	fileName += "." + mavenSelector.GetExtension()
+
+	mavenUrl, err := url.Parse(fmt.Sprintf("%s/%s/%s", artifactUrl, version, fileName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResolvedResourceRef{
+		Url:                      mavenUrl.String(),
+		Version:                  fileVersion,
+		CredentialsSecretName:    mavenSelector.CredentialsSecretName,
+		VerifyPublishedChecksums: true,
+	}, nil
+}
+
+// Returns the timestamped version of the file of a SNAPSHOT artifact.
+// Repositories which do not keep unique SNAPSHOT builds have no
+// timestamp, the version is then used as is.
+func getMavenSnapshotFileVersion(metadata *mavenMetadata, version string, classifier string, extension string) string {
+	for _, snapshotVersion := range metadata.Versioning.SnapshotVersions {
+		if snapshotVersion.Classifier == classifier && snapshotVersion.Extension == extension && snapshotVersion.Value != "" {
+			return snapshotVersion.Value
+		}
+	}
+
+	snapshot := metadata.Versioning.Snapshot
+	if snapshot.Timestamp == "" {
+		return version
+	}
+
+	return fmt.Sprintf("%s-%s-%d", strings.TrimSuffix(version, mavenSnapshotSuffix), snapshot.Timestamp, snapshot.BuildNumber)
+}
+
+func (r *ResourceRefResolver) getMavenMetadata(metadataUrl string, credentials *sourceCredentials) (*mavenMetadata, error) {
+	content, err := r.getSource(metadataUrl, credentials, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Maven metadata %s: %v", metadataUrl, err)
+	}
+
+	metadata := &mavenMetadata{}
+	if err := xml.Unmarshal(content, metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse Maven metadata %s: %v", metadataUrl, err)
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+const mavenArtifactMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>io.shulkermc</groupId>
+  <artifactId>plugin</artifactId>
+  <versioning>
+    <latest>2.0.0-SNAPSHOT</latest>
+    <release>1.1.0</release>
+    <versions>
+      <version>1.0.0</version>
+      <version>1.1.0</version>
+      <version>2.0.0-SNAPSHOT</version>
+    </versions>
+  </versioning>
+</metadata>`
+
+const mavenSnapshotMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata modelVersion="1.1.0">
+  <groupId>io.shulkermc</groupId>
+  <artifactId>plugin</artifactId>
+  <version>2.0.0-SNAPSHOT</version>
+  <versioning>
+    <snapshot>
+      <timestamp>20230102.030405</timestamp>
+      <buildNumber>7</buildNumber>
+    </snapshot>
+    <snapshotVersions>
+      <snapshotVersion>
+        <extension>jar</extension>
+        <value>2.0.0-20230102.030405-7</value>
+      </snapshotVersion>
+      <snapshotVersion>
+        <classifier>all</classifier>
+        <extension>jar</extension>
+        <value>2.0.0-20230101.000000-6</value>
+      </snapshotVersion>
+    </snapshotVersions>
+  </versioning>
+</metadata>`
+
+var _ = Describe("ResourceRefResolver", func() {
+	var server *httptest.Server
+	var files map[string]string
+	var resolver *ResourceRefResolver
+
+	BeforeEach(func() {
+		files = map[string]string{
+			"/repository/io/shulkermc/plugin/maven-metadata.xml":                mavenArtifactMetadata,
+			"/repository/io/shulkermc/plugin/2.0.0-SNAPSHOT/maven-metadata.xml": mavenSnapshotMetadata,
+		}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); ok && (username != "user" || password != "secret") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			content, ok := files[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(content))
+		}))
+
+		resolver = &ResourceRefResolver{
+			Client: fake.NewClientBuilder().WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "maven-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"username": []byte("user"), "password": []byte("secret")},
+			}).Build(),
+			Ctx:        context.Background(),
+			Namespace:  "default",
+			HTTPClient: server.Client(),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	resolveMavenRef := func(selector v1alpha1.ResourceRefMavenSelector) (*ResolvedResourceRef, error) {
+		selector.Repository = server.URL + "/repository"
+		selector.GroupId = "io.shulkermc"
+		selector.ArtifactId = "plugin"
+		return resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{MavenRef: &selector},
+		})
+	}
+
+	It("resolves fixed versions with a classifier and an extension", func() {
+		resolvedRef, err := resolveMavenRef(v1alpha1.ResourceRefMavenSelector{Version: "1.0.0", Classifier: "patch", Extension: "zip"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal(server.URL + "/repository/io/shulkermc/plugin/1.0.0/plugin-1.0.0-patch.zip"))
+		Expect(resolvedRef.Version).To(Equal("1.0.0"))
+		Expect(resolvedRef.VerifyPublishedChecksums).To(BeTrue())
+	})
+
+	It("resolves the RELEASE version", func() {
+		resolvedRef, err := resolveMavenRef(v1alpha1.ResourceRefMavenSelector{Version: "RELEASE"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal(server.URL + "/repository/io/shulkermc/plugin/1.1.0/plugin-1.1.0.jar"))
+		Expect(resolvedRef.Version).To(Equal("1.1.0"))
+	})
+
+	It("resolves the LATEST version to a timestamped SNAPSHOT", func() {
+		resolvedRef, err := resolveMavenRef(v1alpha1.ResourceRefMavenSelector{Version: "LATEST"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal(server.URL + "/repository/io/shulkermc/plugin/2.0.0-SNAPSHOT/plugin-2.0.0-20230102.030405-7.jar"))
+		Expect(resolvedRef.Version).To(Equal("2.0.0-20230102.030405-7"))
+	})
+
+	It("resolves SNAPSHOT versions of classified artifacts", func() {
+		resolvedRef, err := resolveMavenRef(v1alpha1.ResourceRefMavenSelector{Version: "2.0.0-SNAPSHOT", Classifier: "all"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal(server.URL + "/repository/io/shulkermc/plugin/2.0.0-SNAPSHOT/plugin-2.0.0-20230101.000000-6-all.jar"))
+	})
+
+	It("falls back to the latest SNAPSHOT timestamp", func() {
+		resolvedRef, err := resolveMavenRef(v1alpha1.ResourceRefMavenSelector{Version: "2.0.0-SNAPSHOT", Extension: "zip"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Version).To(Equal("2.0.0-20230102.030405-7"))
+	})
+
+	It("authenticates with the credentials Secret", func() {
+		resolvedRef, err := resolveMavenRef(v1alpha1.ResourceRefMavenSelector{Version: "RELEASE", CredentialsSecretName: "maven-credentials"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.CredentialsSecretName).To(Equal("maven-credentials"))
+	})
+
+	It("fails when the metadata cannot be found", func() {
+		delete(files, "/repository/io/shulkermc/plugin/maven-metadata.xml")
+
+		_, err := resolveMavenRef(v1alpha1.ResourceRefMavenSelector{Version: "LATEST"})
+
+		Expect(err).To(MatchError(ContainSubstring("unexpected status 404")))
+	})
+})
@@ -16,6 +16,9 @@ import (
 type MinecraftClusterResourceBuilder struct {
 	Instance *shulkermciov1alpha1.MinecraftCluster
 	Scheme   *runtime.Scheme
+
+	// Images of the created containers.
+	Images *common.Images
 }
 
 func (b *MinecraftClusterResourceBuilder) ResourceBuilders() ([]common.ResourceBuilder, []common.ResourceBuilder) {
@@ -28,6 +31,17 @@ func (b *MinecraftClusterResourceBuilder) ResourceBuilders() ([]common.ResourceB
 	}
 	dirtyBuilders := []common.ResourceBuilder{}
 
+	cacheBuilders := []common.ResourceBuilder{
+		b.MinecraftClusterCachePersistentVolumeClaim(),
+		b.MinecraftClusterCacheDeployment(),
+		b.MinecraftClusterCacheService(),
+	}
+	if b.Instance.Spec.Cache != nil {
+		builders = append(builders, cacheBuilders...)
+	} else {
+		dirtyBuilders = append(dirtyBuilders, cacheBuilders...)
+	}
+
 	return builders, dirtyBuilders
 }
 
@@ -51,6 +65,10 @@ func (b *MinecraftClusterResourceBuilder) getMinecraftServerServiceAccountName()
 	return fmt.Sprintf("%s-server", b.Instance.Name)
 }
 
+func (b *MinecraftClusterResourceBuilder) getCacheName() string {
+	return common.GetArtifactCacheName(b.Instance)
+}
+
 func (b *MinecraftClusterResourceBuilder) getLabels() map[string]string {
 	labels := map[string]string{
 		"app.kubernetes.io/name":             b.Instance.Name,
@@ -60,3 +78,10 @@ func (b *MinecraftClusterResourceBuilder) getLabels() map[string]string {
 
 	return labels
 }
+
+func (b *MinecraftClusterResourceBuilder) getCacheLabels() map[string]string {
+	labels := b.getLabels()
+	labels["app.kubernetes.io/component"] = "cache"
+
+	return labels
+}
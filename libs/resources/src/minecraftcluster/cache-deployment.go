@@ -0,0 +1,138 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	common "github.com/iamblueslime/shulker/libs/resources/src"
+)
+
+const minecraftClusterCacheDir = "/var/cache/shulker"
+
+type MinecraftClusterCacheDeploymentBuilder struct {
+	*MinecraftClusterResourceBuilder
+}
+
+func (b *MinecraftClusterResourceBuilder) MinecraftClusterCacheDeployment() *MinecraftClusterCacheDeploymentBuilder {
+	return &MinecraftClusterCacheDeploymentBuilder{b}
+}
+
+func (b *MinecraftClusterCacheDeploymentBuilder) Build() (client.Object, error) {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.getCacheName(),
+			Namespace: b.Instance.Namespace,
+			Labels:    b.getCacheLabels(),
+		},
+	}, nil
+}
+
+func (b *MinecraftClusterCacheDeploymentBuilder) Update(object client.Object) error {
+	deployment := object.(*appsv1.Deployment)
+	cache := b.Instance.Spec.Cache
+	replicas := int32(1)
+
+	// Some space is left on the volume for the resources being
+	// downloaded
+	maxSize := cache.Size.Value() / 10 * 9
+
+	container := corev1.Container{
+		Image: b.Images.ArtifactCache,
+		Name:  "cache",
+		Args: []string{
+			fmt.Sprintf("--dir=%s", minecraftClusterCacheDir),
+			fmt.Sprintf("--listen-address=:%d", common.ArtifactCachePort),
+			fmt.Sprintf("--max-size=%d", maxSize),
+		},
+		Ports: []corev1.ContainerPort{{
+			Name:          "http",
+			ContainerPort: common.ArtifactCachePort,
+		}},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: "/healthz",
+					Port: intstr.FromString("http"),
+				},
+			},
+			PeriodSeconds: 10,
+		},
+		SecurityContext: b.getCacheSecurityContext(),
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      "cache",
+			MountPath: minecraftClusterCacheDir,
+		}},
+	}
+	if cache.Resources != nil {
+		container.Resources = *cache.Resources
+	}
+
+	fsGroup := int64(1000)
+	deployment.Spec.Replicas = &replicas
+	deployment.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: b.getCacheLabels(),
+	}
+	// The volume can only be mounted by one Pod at a time
+	deployment.Spec.Strategy = appsv1.DeploymentStrategy{
+		Type: appsv1.RecreateDeploymentStrategyType,
+	}
+	deployment.Spec.Template = corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: b.getCacheLabels(),
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{container},
+			SecurityContext: &corev1.PodSecurityContext{
+				FSGroup: &fsGroup,
+			},
+			Volumes: []corev1.Volume{{
+				Name: "cache",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: b.getCacheName(),
+					},
+				},
+			}},
+		},
+	}
+
+	b.Images.ApplyPullOptions(&deployment.Spec.Template.Spec, "", nil)
+
+	if err := controllerutil.SetControllerReference(b.Instance, deployment, b.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference for Deployment: %v", err)
+	}
+
+	return nil
+}
+
+func (b *MinecraftClusterCacheDeploymentBuilder) CanBeUpdated() bool {
+	return true
+}
+
+func (b *MinecraftClusterCacheDeploymentBuilder) getCacheSecurityContext() *corev1.SecurityContext {
+	securityEscalation := false
+	readOnlyFs := true
+	runAsNonRoot := true
+	userUid := int64(1000)
+
+	return &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &securityEscalation,
+		ReadOnlyRootFilesystem:   &readOnlyFs,
+		RunAsNonRoot:             &runAsNonRoot,
+		RunAsUser:                &userUid,
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+	}
+}
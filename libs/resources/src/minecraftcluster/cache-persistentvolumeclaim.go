@@ -0,0 +1,67 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+type MinecraftClusterCachePersistentVolumeClaimBuilder struct {
+	*MinecraftClusterResourceBuilder
+}
+
+func (b *MinecraftClusterResourceBuilder) MinecraftClusterCachePersistentVolumeClaim() *MinecraftClusterCachePersistentVolumeClaimBuilder {
+	return &MinecraftClusterCachePersistentVolumeClaimBuilder{b}
+}
+
+func (b *MinecraftClusterCachePersistentVolumeClaimBuilder) Build() (client.Object, error) {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.getCacheName(),
+			Namespace: b.Instance.Namespace,
+			Labels:    b.getCacheLabels(),
+		},
+	}, nil
+}
+
+func (b *MinecraftClusterCachePersistentVolumeClaimBuilder) Update(object client.Object) error {
+	persistentVolumeClaim := object.(*corev1.PersistentVolumeClaim)
+	cache := b.Instance.Spec.Cache
+
+	// Most of the claim is immutable once created, only its size
+	// can grow afterwards
+	if persistentVolumeClaim.CreationTimestamp.IsZero() {
+		persistentVolumeClaim.Spec = corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: cache.StorageClassName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: cache.Size,
+				},
+			},
+		}
+	} else {
+		currentSize := persistentVolumeClaim.Spec.Resources.Requests[corev1.ResourceStorage]
+		if cache.Size.Cmp(currentSize) > 0 {
+			persistentVolumeClaim.Spec.Resources.Requests[corev1.ResourceStorage] = cache.Size
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(b.Instance, persistentVolumeClaim, b.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference for PersistentVolumeClaim: %v", err)
+	}
+
+	return nil
+}
+
+func (b *MinecraftClusterCachePersistentVolumeClaimBuilder) CanBeUpdated() bool {
+	return true
+}
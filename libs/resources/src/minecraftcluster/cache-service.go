@@ -0,0 +1,59 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	common "github.com/iamblueslime/shulker/libs/resources/src"
+)
+
+type MinecraftClusterCacheServiceBuilder struct {
+	*MinecraftClusterResourceBuilder
+}
+
+func (b *MinecraftClusterResourceBuilder) MinecraftClusterCacheService() *MinecraftClusterCacheServiceBuilder {
+	return &MinecraftClusterCacheServiceBuilder{b}
+}
+
+func (b *MinecraftClusterCacheServiceBuilder) Build() (client.Object, error) {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.getCacheName(),
+			Namespace: b.Instance.Namespace,
+			Labels:    b.getCacheLabels(),
+		},
+	}, nil
+}
+
+func (b *MinecraftClusterCacheServiceBuilder) Update(object client.Object) error {
+	service := object.(*corev1.Service)
+
+	service.Spec.Selector = b.getCacheLabels()
+	service.Spec.Type = corev1.ServiceTypeClusterIP
+	service.Spec.Ports = []corev1.ServicePort{{
+		Name:       "http",
+		Protocol:   corev1.ProtocolTCP,
+		Port:       common.ArtifactCachePort,
+		TargetPort: intstr.FromString("http"),
+	}}
+
+	if err := controllerutil.SetControllerReference(b.Instance, service, b.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference for Service: %v", err)
+	}
+
+	return nil
+}
+
+func (b *MinecraftClusterCacheServiceBuilder) CanBeUpdated() bool {
+	return true
+}
@@ -11,6 +11,7 @@ import (
 
 	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
 	common "github.com/iamblueslime/shulker/libs/resources/src"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -20,18 +21,43 @@ type MinecraftServerResourceBuilder struct {
 	Scheme   *runtime.Scheme
 	Client   client.Client
 	Ctx      context.Context
+
+	// Images of the created containers.
+	Images *common.Images
+
+	// Cluster of the instance, its artifact cache is used to
+	// download the resources when it has one.
+	Cluster *shulkermciov1alpha1.MinecraftCluster
+
+	resolvedResources *minecraftServerResolvedResources
+}
+
+type minecraftServerResolvedResources struct {
+	World   *common.ResolvedResourceRef
+	Plugins []common.ResolvedResourceRef
+	Mods    []common.ResolvedResourceRef
+	Patches []common.ResolvedResourceRef
 }
 
 func (b *MinecraftServerResourceBuilder) ResourceBuilders() ([]common.ResourceBuilder, []common.ResourceBuilder) {
-	builders := []common.ResourceBuilder{
-		b.MinecraftServerPod(),
-	}
+	builders := []common.ResourceBuilder{}
 	dirtyBuilders := []common.ResourceBuilder{}
 
+	// The claim must exist before the Pod mounting it
+	if b.Instance.Spec.Persistence != nil {
+		builders = append(builders, b.MinecraftServerPersistentVolumeClaim())
+	}
+	if b.Instance.Spec.Backup != nil {
+		builders = append(builders, b.MinecraftServerRconSecret())
+	}
 	if b.Instance.Spec.Configuration.ExistingConfigMapName == "" {
 		builders = append(builders, b.MinecraftServerConfigMap())
 	}
 
+	// The Pod is held back when its resources cannot be resolved, so
+	// it comes last
+	builders = append(builders, b.MinecraftServerInitConfigMap(), b.MinecraftServerPod())
+
 	return builders, dirtyBuilders
 }
 
@@ -46,6 +72,29 @@ func (b *MinecraftServerResourceBuilder) GetConfigMapName() string {
 	return fmt.Sprintf("%s-config", b.Instance.Name)
 }
 
+func (b *MinecraftServerResourceBuilder) GetInitConfigMapName() string {
+	return fmt.Sprintf("%s-init", b.Instance.Name)
+}
+
+func (b *MinecraftServerResourceBuilder) GetPersistentVolumeClaimName() string {
+	return fmt.Sprintf("%s-data", b.Instance.Name)
+}
+
+func (b *MinecraftServerResourceBuilder) GetRconSecretName() string {
+	return fmt.Sprintf("%s-rcon", b.Instance.Name)
+}
+
+// The world is extracted directly in the data directory when it is
+// persisted, so it is not overwritten by the server configuration
+// copied at each start.
+func (b *MinecraftServerResourceBuilder) getWorldDir() string {
+	if b.Instance.Spec.Persistence != nil {
+		return minecraftServerDataDir
+	}
+
+	return minecraftServerConfigDir
+}
+
 func (b *MinecraftServerResourceBuilder) getServiceAccountName() string {
 	return fmt.Sprintf("%s-server", b.Instance.Spec.ClusterRef.Name)
 }
@@ -57,14 +106,166 @@ func (b *MinecraftServerResourceBuilder) getLabels() map[string]string {
 		"minecraftcluster.shulkermc.io/name": b.Instance.Spec.ClusterRef.Name,
 	}
 
-	for _, ownerReference := range b.Instance.OwnerReferences {
-		if *ownerReference.Controller {
-			labels["app.kubernetes.io/name"] = ownerReference.Name
-			labels["app.kubernetes.io/instance"] = b.Instance.Name
-			labels["minecraftserverdeployment.shulkermc.io/name"] = ownerReference.Name
-			break
-		}
+	if ownerReference := b.getDeploymentOwnerReference(); ownerReference != nil {
+		labels["app.kubernetes.io/name"] = ownerReference.Name
+		labels["app.kubernetes.io/instance"] = b.Instance.Name
+		labels["minecraftserverdeployment.shulkermc.io/name"] = ownerReference.Name
 	}
 
 	return labels
 }
+
+func (b *MinecraftServerResourceBuilder) getDeploymentOwnerReference() *metav1.OwnerReference {
+	for i, ownerReference := range b.Instance.OwnerReferences {
+		if ownerReference.Controller != nil && *ownerReference.Controller {
+			return &b.Instance.OwnerReferences[i]
+		}
+	}
+
+	return nil
+}
+
+// Returns how the resources of the server were resolved, to be
+// reported in status.
+func (b *MinecraftServerResourceBuilder) GetResolvedResources() ([]shulkermciov1alpha1.ResolvedResourceRefStatus, error) {
+	resolvedResources, err := b.resolveResources()
+	if err != nil {
+		return nil, err
+	}
+
+	return common.ToResolvedResourceRefStatuses(resolvedResources.all()), nil
+}
+
+// Returns the resources of the server, resolved once per spec hash
+// so their sources are not queried on every reconciliation.
+func (b *MinecraftServerResourceBuilder) GetResourcesLock(specHash string) (*shulkermciov1alpha1.ResourceRefLock, error) {
+	if resourcesLock := b.Instance.Status.ResourcesLock; resourcesLock != nil && resourcesLock.TemplateHash == specHash {
+		return resourcesLock, nil
+	}
+
+	resourceRefResolver := common.ResourceRefResolver{
+		Client:    b.Client,
+		Ctx:       b.Ctx,
+		Namespace: b.Instance.Namespace,
+	}
+	resolvedResources, err := ResolveMinecraftServerResources(&resourceRefResolver, &b.Instance.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &shulkermciov1alpha1.ResourceRefLock{
+		TemplateHash: specHash,
+		Resources:    common.ToLockedResourceRefs(resolvedResources),
+	}, nil
+}
+
+// Resolves the resources of the server once, they are needed by both
+// the init ConfigMap and the Pod. The resources locked by the owning
+// deployment, or else in status, are used as is.
+func (b *MinecraftServerResourceBuilder) resolveResources() (*minecraftServerResolvedResources, error) {
+	if b.resolvedResources != nil {
+		return b.resolvedResources, nil
+	}
+
+	if len(b.Instance.Spec.LockedResources) > 0 {
+		b.resolvedResources = getLockedMinecraftServerResources(b.Instance.Spec.LockedResources)
+		return b.resolvedResources, nil
+	}
+
+	if resourcesLock := b.Instance.Status.ResourcesLock; resourcesLock != nil {
+		b.resolvedResources = getLockedMinecraftServerResources(resourcesLock.Resources)
+		return b.resolvedResources, nil
+	}
+
+	resourceRefResolver := common.ResourceRefResolver{
+		Client:    b.Client,
+		Ctx:       b.Ctx,
+		Namespace: b.Instance.Namespace,
+	}
+	resolvedResources, err := resolveMinecraftServerResources(&resourceRefResolver, &b.Instance.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	b.resolvedResources = resolvedResources
+	return resolvedResources, nil
+}
+
+// Resolves the resources of a MinecraftServer spec, to be locked in
+// its status or in the one of the deployment it is the template of.
+func ResolveMinecraftServerResources(resourceRefResolver *common.ResourceRefResolver, spec *shulkermciov1alpha1.MinecraftServerSpec) ([]common.ResolvedResourceRef, error) {
+	resolvedResources, err := resolveMinecraftServerResources(resourceRefResolver, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolvedResources.all(), nil
+}
+
+func resolveMinecraftServerResources(resourceRefResolver *common.ResourceRefResolver, spec *shulkermciov1alpha1.MinecraftServerSpec) (*minecraftServerResolvedResources, error) {
+	resolvedResources := &minecraftServerResolvedResources{}
+	var err error
+
+	if spec.Configuration.World != nil {
+		resolvedResources.World, err = resourceRefResolver.ResolveAt(spec.Configuration.World, "spec.config.world")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resolvedResources.Plugins, err = resourceRefResolver.ResolveAll(spec.Configuration.Plugins, "spec.config.plugins")
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedResources.Mods, err = resourceRefResolver.ResolveAll(spec.Configuration.Mods, "spec.config.mods")
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedResources.Patches, err = resourceRefResolver.ResolveAll(spec.Configuration.Patches, "spec.config.patches")
+	if err != nil {
+		return nil, err
+	}
+
+	return resolvedResources, nil
+}
+
+func getLockedMinecraftServerResources(lockedResources []shulkermciov1alpha1.LockedResourceRef) *minecraftServerResolvedResources {
+	resolvedResources := &minecraftServerResolvedResources{
+		Plugins: common.FromLockedResourceRefs(lockedResources, "spec.config.plugins"),
+		Mods:    common.FromLockedResourceRefs(lockedResources, "spec.config.mods"),
+		Patches: common.FromLockedResourceRefs(lockedResources, "spec.config.patches"),
+	}
+
+	if world := common.FromLockedResourceRefs(lockedResources, "spec.config.world"); len(world) > 0 {
+		resolvedResources.World = &world[0]
+	}
+
+	return resolvedResources
+}
+
+// Returns the resources to download from the artifact cache at the
+// given URL when they can be cached.
+func (r *minecraftServerResolvedResources) throughArtifactCache(cacheUrl string) *minecraftServerResolvedResources {
+	cachedResources := &minecraftServerResolvedResources{
+		Plugins: common.ThroughArtifactCache(r.Plugins, cacheUrl),
+		Mods:    common.ThroughArtifactCache(r.Mods, cacheUrl),
+		Patches: common.ThroughArtifactCache(r.Patches, cacheUrl),
+	}
+	if r.World != nil {
+		world := r.World.ThroughArtifactCache(cacheUrl)
+		cachedResources.World = &world
+	}
+	return cachedResources
+}
+
+func (r *minecraftServerResolvedResources) all() []common.ResolvedResourceRef {
+	var all []common.ResolvedResourceRef
+	if r.World != nil {
+		all = append(all, *r.World)
+	}
+	all = append(all, r.Plugins...)
+	all = append(all, r.Mods...)
+	return append(all, r.Patches...)
+}
@@ -29,10 +29,10 @@ func GetPaperGlobalYml(spec *shulkermciov1alpha1.MinecraftServerConfigurationSpe
 	paperGlobalYml := paperGlobalYml{
 		Proxies: paperGlobalProxiesYml{
 			BungeeCord: paperGlobalProxiesBungeeCordYml{
-				OnlineMode: spec.ProxyForwardingMode == shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord,
+				OnlineMode: spec.GetProxyForwardingMode() == shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord,
 			},
 			Velocity: paperGlobalProxiesVelocityYml{
-				Enabled:    spec.ProxyForwardingMode == shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity,
+				Enabled:    spec.GetProxyForwardingMode() == shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity,
 				OnlineMode: true,
 				Secret:     "${CFG_VELOCITY_FORWARDING_SECRET}",
 			},
@@ -26,7 +26,7 @@ type spigotYml struct {
 func GetSpigotYml(spec *shulkermciov1alpha1.MinecraftServerConfigurationSpec) (string, error) {
 	spigotYml := spigotYml{
 		Settings: spigotSettingsYml{
-			BungeeCord:     spec.ProxyForwardingMode == shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord,
+			BungeeCord:     spec.GetProxyForwardingMode() == shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord,
 			RestartOnCrash: false,
 		},
 		Advancements: spigotSaveableYml{
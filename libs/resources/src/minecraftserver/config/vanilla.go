@@ -8,7 +8,11 @@ import (
 	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
 )
 
-func GetServerProperties(spec *shulkermciov1alpha1.MinecraftServerConfigurationSpec) string {
+const RconPort = 25575
+
+// The RCON password is only known by the Pod, it is replaced by the
+// server image when copying the configuration.
+func GetServerProperties(spec *shulkermciov1alpha1.MinecraftServerConfigurationSpec, enableRcon bool) string {
 	properties := make(map[string]string)
 
 	for k, v := range spec.ServerProperties {
@@ -18,9 +22,15 @@ func GetServerProperties(spec *shulkermciov1alpha1.MinecraftServerConfigurationS
 	properties["online-mode"] = "false"
 	properties["prevent-proxy-connections"] = "false"
 	properties["enforce-secure-profiles"] = "true"
-	properties["max-players"] = strconv.Itoa(*spec.MaxPlayers)
+	properties["max-players"] = strconv.Itoa(spec.GetMaxPlayers())
 	properties["allow-nether"] = strconv.FormatBool(!spec.DisableNether)
 
+	if enableRcon {
+		properties["enable-rcon"] = "true"
+		properties["rcon.port"] = strconv.Itoa(RconPort)
+		properties["rcon.password"] = "${CFG_RCON_PASSWORD}"
+	}
+
 	lines := []string{}
 	for k, v := range properties {
 		lines = append(lines, fmt.Sprintf("%s=%s", k, v))
@@ -7,7 +7,6 @@ package resources
 
 import (
 	"fmt"
-	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,7 +38,7 @@ func (b *MinecraftServerResourceConfigMapBuilder) Build() (client.Object, error)
 func (b *MinecraftServerResourceConfigMapBuilder) Update(object client.Object) error {
 	configMap := object.(*corev1.ConfigMap)
 
-	configMapData, err := GetConfigMapDataFromConfigSpec(&b.Instance.Spec.Configuration)
+	configMapData, err := GetConfigMapDataFromConfigSpec(&b.Instance.Spec.Configuration, b.Instance.Spec.Backup != nil)
 	if err != nil {
 		return err
 	}
@@ -56,46 +55,10 @@ func (b *MinecraftServerResourceConfigMapBuilder) CanBeUpdated() bool {
 	return true
 }
 
-func GetConfigMapDataFromConfigSpec(spec *shulkermciov1alpha1.MinecraftServerConfigurationSpec) (map[string]string, error) {
+func GetConfigMapDataFromConfigSpec(spec *shulkermciov1alpha1.MinecraftServerConfigurationSpec, enableRcon bool) (map[string]string, error) {
 	configMapData := make(map[string]string)
 
-	configMapData["init-fs.sh"] = trimScript(`
-		#!/bin/sh
-		set -euo pipefail
-		set -o xtrace
-
-		cp "${SHULKER_CONFIG_DIR}/server.properties" "${SERVER_CONFIG_DIR}/server.properties"
-		if [ "${TYPE}" == "BUKKIT" ]; then
-			cp "${SHULKER_CONFIG_DIR}/bukkit-config.yml" "${SERVER_CONFIG_DIR}/bukkit.yml"
-		elif [ "${TYPE}" == "SPIGOT" ]; then
-			cp "${SHULKER_CONFIG_DIR}/bukkit-config.yml" "${SERVER_CONFIG_DIR}/bukkit.yml"
-			cp "${SHULKER_CONFIG_DIR}/spigot-config.yml" "${SERVER_CONFIG_DIR}/spigot.yml"
-		elif [ "${TYPE}" == "PAPER" ]; then
-			cp "${SHULKER_CONFIG_DIR}/bukkit-config.yml" "${SERVER_CONFIG_DIR}/bukkit.yml"
-			cp "${SHULKER_CONFIG_DIR}/spigot-config.yml" "${SERVER_CONFIG_DIR}/spigot.yml"
-			mkdir -p "${SERVER_CONFIG_DIR}/config"
-			cp "${SHULKER_CONFIG_DIR}/paper-global-config.yml" "${SERVER_CONFIG_DIR}/config/paper-global.yml"
-		fi
-
-		if [ "${SERVER_WORLD_URL}" != "" ]; then
-			(cd "${SERVER_CONFIG_DIR}" && wget "${SERVER_WORLD_URL}" -O - | tar -xzv)
-		fi
-
-		if [ "${SERVER_PLUGIN_URLS}" != "" ]; then
-			mkdir -p "${SERVER_CONFIG_DIR}/plugins"
-			for plugin_url in ${SERVER_PLUGIN_URLS//;/ }; do
-				(cd "${SERVER_CONFIG_DIR}/plugins" && wget "${plugin_url}")
-			done
-		fi
-
-		if [ "${SERVER_PATCH_URLS}" != "" ]; then
-			for patch_url in ${SERVER_PATCH_URLS//;/ }; do
-				(cd "${SERVER_CONFIG_DIR}" && wget "${patch_url}" -O - | tar -xzv)
-			done
-		fi
-	`)
-
-	configMapData["server.properties"] = config.GetServerProperties(spec)
+	configMapData["server.properties"] = config.GetServerProperties(spec, enableRcon)
 
 	bukkitConfigYml, err := config.GetBukkitYml(spec)
 	if err != nil {
@@ -117,11 +80,3 @@ func GetConfigMapDataFromConfigSpec(spec *shulkermciov1alpha1.MinecraftServerCon
 
 	return configMapData, nil
 }
-
-func trimScript(script string) string {
-	lines := strings.Split(strings.TrimSpace(script), "\n")
-	for i := range lines {
-		lines[i] = strings.TrimSpace(lines[i])
-	}
-	return strings.Join(lines, "\n")
-}
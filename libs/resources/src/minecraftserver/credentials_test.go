@@ -0,0 +1,175 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	initfs "github.com/iamblueslime/shulker/libs/initfs/src"
+	common "github.com/iamblueslime/shulker/libs/resources/src"
+)
+
+const mavenMetadata = `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <versioning>
+    <release>1.1.0</release>
+  </versioning>
+</metadata>`
+
+var _ = Describe("Resource credentials", func() {
+	const username = "deployer"
+	const password = "s3cr3t-password"
+
+	var server *httptest.Server
+	var builder *MinecraftServerResourceBuilder
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if givenUsername, givenPassword, ok := r.BasicAuth(); !ok || givenUsername != username || givenPassword != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Path != "/repository/io/shulkermc/plugin/maven-metadata.xml" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(mavenMetadata))
+		}))
+
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(shulkermciov1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "maven-credentials"},
+			Data: map[string][]byte{
+				"username": []byte(username),
+				"password": []byte(password),
+			},
+		}
+
+		builder = &MinecraftServerResourceBuilder{
+			Instance: &shulkermciov1alpha1.MinecraftServer{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "my-server"},
+				MinecraftServerTemplate: shulkermciov1alpha1.MinecraftServerTemplate{
+					Spec: shulkermciov1alpha1.MinecraftServerSpec{
+						ClusterRef: shulkermciov1alpha1.MinecraftClusterRef{Name: "my-cluster"},
+						Version: shulkermciov1alpha1.MinecraftServerVersionSpec{
+							Channel: shulkermciov1alpha1.MinecraftServerVersionPaper,
+							Name:    "1.20.4",
+						},
+						Configuration: shulkermciov1alpha1.MinecraftServerConfigurationSpec{
+							Plugins: []shulkermciov1alpha1.ResourceRef{{
+								UrlFrom: &shulkermciov1alpha1.ResourceRefSource{
+									MavenRef: &shulkermciov1alpha1.ResourceRefMavenSelector{
+										Repository:            server.URL + "/repository",
+										GroupId:               "io.shulkermc",
+										ArtifactId:            "plugin",
+										Version:               "RELEASE",
+										CredentialsSecretName: "maven-credentials",
+									},
+								},
+							}},
+						},
+					},
+				},
+			},
+			Scheme: scheme,
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build(),
+			Ctx:    context.Background(),
+			Images: common.NewDefaultImages(),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("only gives the directory of the credentials in the init manifest", func() {
+		configMapBuilder := builder.MinecraftServerInitConfigMap()
+		configMap, err := configMapBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMapBuilder.Update(configMap)).To(Succeed())
+
+		manifestJson := configMap.(*corev1.ConfigMap).Data["manifest.json"]
+		Expect(manifestJson).NotTo(ContainSubstring(password))
+		Expect(manifestJson).NotTo(ContainSubstring(username))
+
+		manifest := &initfs.Manifest{}
+		Expect(json.Unmarshal([]byte(manifestJson), manifest)).To(Succeed())
+		Expect(manifest.Resources).To(HaveLen(1))
+		Expect(manifest.Resources[0].Url).To(Equal(server.URL + "/repository/io/shulkermc/plugin/1.1.0/plugin-1.1.0.jar"))
+		Expect(manifest.Resources[0].CredentialsDir).To(Equal(common.ResourceRefCredentialsDir + "/maven-credentials"))
+	})
+
+	It("mounts the credentials in the init container without exposing them", func() {
+		podBuilder := builder.MinecraftServerPod()
+		pod, err := podBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(podBuilder.Update(pod)).To(Succeed())
+
+		podJson, err := json.Marshal(pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(podJson)).NotTo(ContainSubstring(password))
+		Expect(string(podJson)).NotTo(ContainSubstring(username))
+
+		podSpec := pod.(*corev1.Pod).Spec
+		for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+			for _, env := range container.Env {
+				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+					Expect(env.ValueFrom.SecretKeyRef.Name).NotTo(Equal("maven-credentials"), "env %s of container %s", env.Name, container.Name)
+				}
+			}
+			for _, envFrom := range container.EnvFrom {
+				if envFrom.SecretRef != nil {
+					Expect(envFrom.SecretRef.Name).NotTo(Equal("maven-credentials"), "container %s", container.Name)
+				}
+			}
+		}
+
+		var credentialsVolumeName string
+		for _, volume := range podSpec.Volumes {
+			if volume.Secret != nil && volume.Secret.SecretName == "maven-credentials" {
+				credentialsVolumeName = volume.Name
+			}
+		}
+		Expect(credentialsVolumeName).NotTo(BeEmpty())
+
+		for _, container := range podSpec.Containers {
+			for _, volumeMount := range container.VolumeMounts {
+				Expect(volumeMount.Name).NotTo(Equal(credentialsVolumeName), "container %s", container.Name)
+			}
+		}
+		Expect(podSpec.InitContainers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      credentialsVolumeName,
+			MountPath: common.ResourceRefCredentialsDir + "/maven-credentials",
+			ReadOnly:  true,
+		}))
+	})
+
+	It("locks the resolved resources without their credentials", func() {
+		resourcesLock, err := builder.GetResourcesLock("hash")
+		Expect(err).NotTo(HaveOccurred())
+
+		lockJson, err := json.Marshal(resourcesLock)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(lockJson)).NotTo(ContainSubstring(password))
+		Expect(string(lockJson)).NotTo(ContainSubstring(username))
+		Expect(resourcesLock.Resources[0].CredentialsSecretName).To(Equal("maven-credentials"))
+	})
+})
@@ -0,0 +1,119 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	initfs "github.com/iamblueslime/shulker/libs/initfs/src"
+	common "github.com/iamblueslime/shulker/libs/resources/src"
+)
+
+type MinecraftServerResourceInitConfigMapBuilder struct {
+	*MinecraftServerResourceBuilder
+}
+
+func (b *MinecraftServerResourceBuilder) MinecraftServerInitConfigMap() *MinecraftServerResourceInitConfigMapBuilder {
+	return &MinecraftServerResourceInitConfigMapBuilder{b}
+}
+
+func (b *MinecraftServerResourceInitConfigMapBuilder) Build() (client.Object, error) {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.GetInitConfigMapName(),
+			Namespace: b.Instance.Namespace,
+			Labels:    b.getLabels(),
+		},
+	}, nil
+}
+
+func (b *MinecraftServerResourceInitConfigMapBuilder) Update(object client.Object) error {
+	configMap := object.(*corev1.ConfigMap)
+
+	manifest, err := b.getInitManifest()
+	if err != nil {
+		return err
+	}
+
+	manifestJson, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	configMap.Data = map[string]string{
+		"manifest.json": string(manifestJson),
+	}
+
+	if err := controllerutil.SetControllerReference(b.Instance, configMap, b.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference for ConfigMap: %v", err)
+	}
+
+	return nil
+}
+
+func (b *MinecraftServerResourceInitConfigMapBuilder) CanBeUpdated() bool {
+	return true
+}
+
+func (b *MinecraftServerResourceInitConfigMapBuilder) getInitManifest() (*initfs.Manifest, error) {
+	resolvedResources, err := b.resolveResources()
+	if err != nil {
+		return nil, err
+	}
+	resolvedResources = resolvedResources.throughArtifactCache(common.GetArtifactCacheUrl(b.Cluster))
+
+	manifest := &initfs.Manifest{
+		Files: []initfs.ManifestFile{
+			b.getConfigFile("server.properties", "server.properties"),
+		},
+	}
+
+	switch b.Instance.Spec.Version.Channel {
+	case shulkermciov1alpha1.MinecraftServerVersionBukkit:
+		manifest.Files = append(manifest.Files, b.getConfigFile("bukkit-config.yml", "bukkit.yml"))
+	case shulkermciov1alpha1.MinecraftServerVersionSpigot:
+		manifest.Files = append(manifest.Files,
+			b.getConfigFile("bukkit-config.yml", "bukkit.yml"),
+			b.getConfigFile("spigot-config.yml", "spigot.yml"),
+		)
+	case shulkermciov1alpha1.MinecraftServerVersionPaper:
+		manifest.Files = append(manifest.Files,
+			b.getConfigFile("bukkit-config.yml", "bukkit.yml"),
+			b.getConfigFile("spigot-config.yml", "spigot.yml"),
+			b.getConfigFile("paper-global-config.yml", "config/paper-global.yml"),
+		)
+	}
+
+	// The world is only extracted once when persisted, so it does not
+	// overwrite the progress of the players
+	if resolvedResources.World != nil {
+		world := resolvedResources.World.ToManifestResource("spec.config.world", initfs.ExtractManifestResourceAction, b.getWorldDir())
+		world.OnceMarker = fmt.Sprintf("%s/.shulker-world", b.getWorldDir())
+		manifest.Resources = append(manifest.Resources, world)
+	}
+
+	manifest.Resources = append(manifest.Resources, common.ToManifestResources(resolvedResources.Plugins, "spec.config.plugins",
+		initfs.DownloadManifestResourceAction, fmt.Sprintf("%s/plugins", minecraftServerConfigDir))...)
+	manifest.Resources = append(manifest.Resources, common.ToManifestResources(resolvedResources.Mods, "spec.config.mods",
+		initfs.DownloadManifestResourceAction, fmt.Sprintf("%s/mods", minecraftServerConfigDir))...)
+	manifest.Resources = append(manifest.Resources, common.ToManifestResources(resolvedResources.Patches, "spec.config.patches",
+		initfs.ExtractManifestResourceAction, minecraftServerConfigDir)...)
+
+	return manifest, nil
+}
+
+func (b *MinecraftServerResourceInitConfigMapBuilder) getConfigFile(source string, destination string) initfs.ManifestFile {
+	return initfs.ManifestFile{
+		Source:      fmt.Sprintf("%s/%s", minecraftServerShulkerConfigDir, source),
+		Destination: fmt.Sprintf("%s/%s", minecraftServerConfigDir, destination),
+	}
+}
@@ -0,0 +1,19 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMinecraftServerResources(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "MinecraftServer Resources Suite")
+}
@@ -0,0 +1,99 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+type MinecraftServerResourcePersistentVolumeClaimBuilder struct {
+	*MinecraftServerResourceBuilder
+}
+
+func (b *MinecraftServerResourceBuilder) MinecraftServerPersistentVolumeClaim() *MinecraftServerResourcePersistentVolumeClaimBuilder {
+	return &MinecraftServerResourcePersistentVolumeClaimBuilder{b}
+}
+
+func (b *MinecraftServerResourcePersistentVolumeClaimBuilder) Build() (client.Object, error) {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.GetPersistentVolumeClaimName(),
+			Namespace: b.Instance.Namespace,
+			Labels:    b.getLabels(),
+		},
+	}, nil
+}
+
+func (b *MinecraftServerResourcePersistentVolumeClaimBuilder) Update(object client.Object) error {
+	persistentVolumeClaim := object.(*corev1.PersistentVolumeClaim)
+	persistence := b.Instance.Spec.Persistence
+
+	// Most of the claim is immutable once created, only its size
+	// can grow afterwards
+	if persistentVolumeClaim.CreationTimestamp.IsZero() {
+		persistentVolumeClaim.Spec = corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: persistence.StorageClassName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: persistence.Size,
+				},
+			},
+		}
+
+		return b.setOwner(persistentVolumeClaim)
+	}
+
+	currentSize := persistentVolumeClaim.Spec.Resources.Requests[corev1.ResourceStorage]
+	if persistence.Size.Cmp(currentSize) > 0 {
+		persistentVolumeClaim.Spec.Resources.Requests[corev1.ResourceStorage] = persistence.Size
+	}
+
+	return nil
+}
+
+func (b *MinecraftServerResourcePersistentVolumeClaimBuilder) CanBeUpdated() bool {
+	return true
+}
+
+// Makes the claim garbage collected with the resource its retention
+// policy binds it to, if any.
+func (b *MinecraftServerResourcePersistentVolumeClaimBuilder) setOwner(persistentVolumeClaim *corev1.PersistentVolumeClaim) error {
+	retentionPolicy := b.Instance.Spec.Persistence.RetentionPolicy
+	deploymentOwnerReference := b.getDeploymentOwnerReference()
+
+	if deploymentOwnerReference != nil {
+		if retentionPolicy.WhenScaled == shulkermciov1alpha1.DeletePersistentVolumeClaimRetentionPolicyType {
+			return b.setMinecraftServerOwner(persistentVolumeClaim)
+		} else if retentionPolicy.WhenDeleted == shulkermciov1alpha1.DeletePersistentVolumeClaimRetentionPolicyType {
+			persistentVolumeClaim.OwnerReferences = append(persistentVolumeClaim.OwnerReferences, metav1.OwnerReference{
+				APIVersion: deploymentOwnerReference.APIVersion,
+				Kind:       deploymentOwnerReference.Kind,
+				Name:       deploymentOwnerReference.Name,
+				UID:        deploymentOwnerReference.UID,
+			})
+		}
+	} else if retentionPolicy.WhenDeleted == shulkermciov1alpha1.DeletePersistentVolumeClaimRetentionPolicyType {
+		return b.setMinecraftServerOwner(persistentVolumeClaim)
+	}
+
+	return nil
+}
+
+func (b *MinecraftServerResourcePersistentVolumeClaimBuilder) setMinecraftServerOwner(persistentVolumeClaim *corev1.PersistentVolumeClaim) error {
+	if err := controllerutil.SetControllerReference(b.Instance, persistentVolumeClaim, b.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference for PersistentVolumeClaim: %v", err)
+	}
+
+	return nil
+}
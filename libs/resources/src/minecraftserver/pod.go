@@ -7,7 +7,6 @@ package resources
 
 import (
 	"fmt"
-	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -16,11 +15,17 @@ import (
 
 	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
 	resources "github.com/iamblueslime/shulker/libs/resources/src"
+	config "github.com/iamblueslime/shulker/libs/resources/src/minecraftserver/config"
 )
 
 const minecraftServerShulkerConfigDir = "/mnt/shulker/config"
 const minecraftServerConfigDir = "/config"
 const minecraftServerDataDir = "/data"
+const minecraftServerBackupCredentialsDir = "/mnt/shulker/backup-credentials"
+
+// Time given to the server to stop and to the last backup to be
+// uploaded before the Pod is killed.
+const minecraftServerBackupOnShutdownGracePeriodSeconds = 600
 
 type MinecraftServerResourcePodBuilder struct {
 	*MinecraftServerResourceBuilder
@@ -43,20 +48,28 @@ func (b *MinecraftServerResourcePodBuilder) Build() (client.Object, error) {
 func (b *MinecraftServerResourcePodBuilder) Update(object client.Object) error {
 	pod := object.(*corev1.Pod)
 
-	initEnv, err := b.getInitEnv()
+	resolvedResources, err := b.resolveResources()
 	if err != nil {
 		return err
 	}
+	resourceVolumes, resourceVolumeMounts := resources.GetResourceRefVolumes(resolvedResources.all())
+
+	startupProbe, livenessProbe, readinessProbe := resources.GetProbes(b.Instance.Spec.Probes, "/health.sh", b.getDefaultStartupFailureThreshold())
 
 	pod.Spec = corev1.PodSpec{
 		InitContainers: []corev1.Container{
 			{
-				Image:           "alpine:latest",
-				Name:            "init-fs",
-				Command:         []string{"sh", fmt.Sprintf("%s/init-fs.sh", minecraftServerShulkerConfigDir)},
-				Env:             initEnv,
-				SecurityContext: b.getSecurityContext(),
-				VolumeMounts: []corev1.VolumeMount{
+				Image:                    b.Images.Init,
+				Name:                     "init-fs",
+				Args:                     []string{fmt.Sprintf("--manifest=%s/manifest.json", resources.InitManifestDir)},
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				SecurityContext:          b.getSecurityContext(),
+				VolumeMounts: append([]corev1.VolumeMount{
+					{
+						Name:      "shulker-init",
+						MountPath: resources.InitManifestDir,
+						ReadOnly:  true,
+					},
 					{
 						Name:      "shulker-config",
 						MountPath: minecraftServerShulkerConfigDir,
@@ -66,36 +79,26 @@ func (b *MinecraftServerResourcePodBuilder) Update(object client.Object) error {
 						Name:      "server-config",
 						MountPath: minecraftServerConfigDir,
 					},
-				},
+					{
+						Name:      "server-data",
+						MountPath: minecraftServerDataDir,
+					},
+				}, resourceVolumeMounts...),
 			},
 		},
 		Containers: []corev1.Container{
 			{
-				Image: "itzg/minecraft-server:latest",
-				Name:  "minecraft-server",
+				Image:                    b.getImage(),
+				Name:                     "minecraft-server",
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				Ports: []corev1.ContainerPort{{
 					Name:          "minecraft",
 					ContainerPort: 25565,
 				}},
-				Env: b.getEnv(),
-				LivenessProbe: &corev1.Probe{
-					ProbeHandler: corev1.ProbeHandler{
-						Exec: &corev1.ExecAction{
-							Command: []string{"bash", "/health.sh"},
-						},
-					},
-					InitialDelaySeconds: 60,
-					PeriodSeconds:       10,
-				},
-				ReadinessProbe: &corev1.Probe{
-					ProbeHandler: corev1.ProbeHandler{
-						Exec: &corev1.ExecAction{
-							Command: []string{"bash", "/health.sh"},
-						},
-					},
-					InitialDelaySeconds: 60,
-					PeriodSeconds:       10,
-				},
+				Env:             b.getEnv(),
+				StartupProbe:    startupProbe,
+				LivenessProbe:   livenessProbe,
+				ReadinessProbe:  readinessProbe,
 				SecurityContext: b.getSecurityContext(),
 				VolumeMounts: []corev1.VolumeMount{
 					{
@@ -115,7 +118,18 @@ func (b *MinecraftServerResourcePodBuilder) Update(object client.Object) error {
 		},
 		ServiceAccountName: b.getServiceAccountName(),
 		RestartPolicy:      corev1.RestartPolicyNever,
-		Volumes: []corev1.Volume{
+		SecurityContext:    b.getPodSecurityContext(),
+		Volumes: append([]corev1.Volume{
+			{
+				Name: "shulker-init",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: b.GetInitConfigMapName(),
+						},
+					},
+				},
+			},
 			{
 				Name: "shulker-config",
 				VolumeSource: corev1.VolumeSource{
@@ -133,10 +147,8 @@ func (b *MinecraftServerResourcePodBuilder) Update(object client.Object) error {
 				},
 			},
 			{
-				Name: "server-data",
-				VolumeSource: corev1.VolumeSource{
-					EmptyDir: &corev1.EmptyDirVolumeSource{},
-				},
+				Name:         "server-data",
+				VolumeSource: b.getDataVolumeSource(),
 			},
 			{
 				Name: "server-tmp",
@@ -144,16 +156,63 @@ func (b *MinecraftServerResourcePodBuilder) Update(object client.Object) error {
 					EmptyDir: &corev1.EmptyDirVolumeSource{},
 				},
 			},
-		},
+		}, resourceVolumes...),
+	}
+
+	if b.Instance.Spec.Backup != nil {
+		b.addBackupContainer(&pod.Spec)
+	}
+
+	// The probe sidecar watches the server process to stop along
+	// with it, for the Pod to complete
+	if probeContainer := resources.GetProbeContainer(b.Instance.Spec.Probes, b.Images.Probe, 25565, b.getSecurityContext()); probeContainer != nil {
+		shareProcessNamespace := true
+		pod.Spec.Containers = append(pod.Spec.Containers, *probeContainer)
+		pod.Spec.ShareProcessNamespace = &shareProcessNamespace
 	}
 
+	var pullPolicy corev1.PullPolicy
+	var pullSecrets []corev1.LocalObjectReference
 	if b.Instance.Spec.PodOverrides != nil {
-		if b.Instance.Spec.PodOverrides.Resources != nil {
-			pod.Spec.Containers[0].Resources = *b.Instance.Spec.PodOverrides.Resources
+		podOverrides := b.Instance.Spec.PodOverrides
+
+		if podOverrides.Image != "" {
+			pod.Spec.Containers[0].Image = podOverrides.Image
 		}
 
-		if b.Instance.Spec.PodOverrides.Affinity != nil {
-			pod.Spec.Affinity = b.Instance.Spec.PodOverrides.Affinity
+		if podOverrides.Resources != nil {
+			pod.Spec.Containers[0].Resources = *podOverrides.Resources
+		}
+
+		if podOverrides.Affinity != nil {
+			pod.Spec.Affinity = podOverrides.Affinity
+		}
+
+		if podOverrides.ServiceAccountName != "" {
+			pod.Spec.ServiceAccountName = podOverrides.ServiceAccountName
+		}
+
+		// The labels of Shulker select the Pod, they cannot be
+		// overridden
+		pod.Labels = resources.MergeStringMaps(podOverrides.Labels, pod.Labels)
+		pod.Annotations = resources.MergeStringMaps(pod.Annotations, podOverrides.Annotations)
+
+		pod.Spec.NodeSelector = podOverrides.NodeSelector
+		pod.Spec.Tolerations = podOverrides.Tolerations
+		pod.Spec.TopologySpreadConstraints = podOverrides.TopologySpreadConstraints
+		pod.Spec.PriorityClassName = podOverrides.PriorityClassName
+		pod.Spec.Volumes = append(pod.Spec.Volumes, podOverrides.Volumes...)
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, podOverrides.VolumeMounts...)
+		pod.Spec.Containers = append(pod.Spec.Containers, podOverrides.Sidecars...)
+
+		pullPolicy = podOverrides.ImagePullPolicy
+		pullSecrets = podOverrides.ImagePullSecrets
+	}
+	b.Images.ApplyPullOptions(&pod.Spec, pullPolicy, pullSecrets)
+
+	if b.Instance.Spec.PodOverrides != nil {
+		if err := resources.ApplyPodTemplatePatch(pod, b.Instance.Spec.PodOverrides.PodTemplate); err != nil {
+			return err
 		}
 	}
 
@@ -193,74 +252,6 @@ func getVersionEnvFromVersionChannel(channel shulkermciov1alpha1.MinecraftServer
 	return "VERSION"
 }
 
-func (b *MinecraftServerResourcePodBuilder) getInitEnv() ([]corev1.EnvVar, error) {
-	resourceRefResolver := resources.ResourceRefResolver{
-		Client:    b.Client,
-		Ctx:       b.Ctx,
-		Namespace: b.Instance.Namespace,
-	}
-
-	var worldUrl string
-	var err error
-	if b.Instance.Spec.Configuration.World != nil {
-		worldUrl, err = resourceRefResolver.ResolveUrl(b.Instance.Spec.Configuration.World)
-		if err != nil {
-			return []corev1.EnvVar{}, nil
-		}
-	}
-
-	var pluginUrls []string
-	for _, ref := range b.Instance.Spec.Configuration.Plugins {
-		pluginUrl, err := resourceRefResolver.ResolveUrl(&ref)
-		if err != nil {
-			return []corev1.EnvVar{}, nil
-		}
-		pluginUrls = append(pluginUrls, pluginUrl)
-	}
-
-	var patchesUrls []string
-	for _, ref := range b.Instance.Spec.Configuration.Patches {
-		patchUrl, err := resourceRefResolver.ResolveUrl(&ref)
-		if err != nil {
-			return []corev1.EnvVar{}, nil
-		}
-		patchesUrls = append(patchesUrls, patchUrl)
-	}
-
-	env := []corev1.EnvVar{
-		{
-			Name:  "SHULKER_CONFIG_DIR",
-			Value: minecraftServerShulkerConfigDir,
-		},
-		{
-			Name:  "SERVER_CONFIG_DIR",
-			Value: minecraftServerConfigDir,
-		},
-		{
-			Name:  "SERVER_DATA_DIR",
-			Value: minecraftServerDataDir,
-		},
-		{
-			Name:  "TYPE",
-			Value: getTypeFromVersionChannel(b.Instance.Spec.Version.Channel),
-		},
-		{
-			Name:  "SERVER_WORLD_URL",
-			Value: worldUrl,
-		},
-		{
-			Name:  "SERVER_PLUGIN_URLS",
-			Value: strings.Join(pluginUrls, ";"),
-		},
-		{
-			Name:  "SERVER_PATCH_URLS",
-			Value: strings.Join(patchesUrls, ";"),
-		},
-	}
-
-	return env, nil
-}
-
 func (b *MinecraftServerResourcePodBuilder) getEnv() []corev1.EnvVar {
 	env := []corev1.EnvVar{
 		{
@@ -314,14 +305,14 @@ func (b *MinecraftServerResourcePodBuilder) getEnv() []corev1.EnvVar {
 				},
 			},
 		},
-		{
-			Name:  "MEMORY",
-			Value: "",
-		},
-		{
-			Name:  "JVM_XX_OPTS",
-			Value: "-XX:MaxRAMPercentage=75",
-		},
+	}
+	env = append(env, resources.GetJvmEnv(b.Instance.Spec.Jvm, b.getResources(), shulkermciov1alpha1.JvmFlagsAikar)...)
+
+	if b.Instance.Spec.Backup != nil {
+		env = append(env, corev1.EnvVar{
+			Name:      "CFG_RCON_PASSWORD",
+			ValueFrom: b.getRconPasswordEnvSource(),
+		})
 	}
 
 	if b.Instance.Spec.PodOverrides != nil {
@@ -331,6 +322,151 @@ func (b *MinecraftServerResourcePodBuilder) getEnv() []corev1.EnvVar {
 	return env
 }
 
+func (b *MinecraftServerResourcePodBuilder) getImage() string {
+	javaVersion := ""
+	if b.Instance.Spec.Jvm != nil {
+		javaVersion = b.Instance.Spec.Jvm.JavaVersion
+	}
+	return resources.GetMinecraftServerImage(b.Images.MinecraftServer, javaVersion, b.Instance.Spec.Version.Name)
+}
+
+// Modded servers load their mods before accepting players, which can
+// take several minutes.
+func (b *MinecraftServerResourcePodBuilder) getDefaultStartupFailureThreshold() int32 {
+	switch b.Instance.Spec.Version.Channel {
+	case shulkermciov1alpha1.MinecraftServerVersionForge, shulkermciov1alpha1.MinecraftServerVersionFabric, shulkermciov1alpha1.MinecraftServerVersionQuilt:
+		return 60
+	}
+	return 30
+}
+
+func (b *MinecraftServerResourcePodBuilder) getResources() *corev1.ResourceRequirements {
+	if b.Instance.Spec.PodOverrides == nil {
+		return nil
+	}
+	return b.Instance.Spec.PodOverrides.Resources
+}
+
+// The backup sidecar watches the server process to know when it
+// stops, which requires the containers to share their process
+// namespace.
+func (b *MinecraftServerResourcePodBuilder) addBackupContainer(podSpec *corev1.PodSpec) {
+	backupSpec := b.Instance.Spec.Backup
+	shareProcessNamespace := true
+
+	args := []string{
+		fmt.Sprintf("--data-dir=%s", minecraftServerDataDir),
+		"--tmp-dir=/tmp",
+		fmt.Sprintf("--credentials-dir=%s", minecraftServerBackupCredentialsDir),
+		fmt.Sprintf("--schedule=%s", backupSpec.Schedule),
+		fmt.Sprintf("--retention=%d", backupSpec.Retention),
+		fmt.Sprintf("--on-shutdown=%t", backupSpec.OnShutdown),
+		fmt.Sprintf("--rcon-address=localhost:%d", config.RconPort),
+		fmt.Sprintf("--s3-endpoint=%s", backupSpec.Bucket.Endpoint),
+		fmt.Sprintf("--s3-region=%s", backupSpec.Bucket.GetRegion()),
+		fmt.Sprintf("--s3-bucket=%s", backupSpec.Bucket.Name),
+		fmt.Sprintf("--s3-prefix=%s", b.getBackupPrefix()),
+	}
+
+	podSpec.Containers = append(podSpec.Containers, corev1.Container{
+		Image: b.Images.Backup,
+		Name:  "backup",
+		Args:  args,
+		Env: []corev1.EnvVar{
+			{
+				Name:      "RCON_PASSWORD",
+				ValueFrom: b.getRconPasswordEnvSource(),
+			},
+		},
+		SecurityContext: b.getSecurityContext(),
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "server-data",
+				MountPath: minecraftServerDataDir,
+				ReadOnly:  true,
+			},
+			{
+				Name:      "backup-tmp",
+				MountPath: "/tmp",
+			},
+			{
+				Name:      "backup-credentials",
+				MountPath: minecraftServerBackupCredentialsDir,
+				ReadOnly:  true,
+			},
+		},
+	})
+
+	podSpec.Volumes = append(podSpec.Volumes,
+		corev1.Volume{
+			Name: "backup-tmp",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+		corev1.Volume{
+			Name: "backup-credentials",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: backupSpec.Bucket.CredentialsSecretName,
+				},
+			},
+		},
+	)
+
+	podSpec.ShareProcessNamespace = &shareProcessNamespace
+	if backupSpec.OnShutdown {
+		gracePeriodSeconds := int64(minecraftServerBackupOnShutdownGracePeriodSeconds)
+		podSpec.TerminationGracePeriodSeconds = &gracePeriodSeconds
+	}
+}
+
+func (b *MinecraftServerResourcePodBuilder) getRconPasswordEnvSource() *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: b.GetRconSecretName(),
+			},
+			Key: "password",
+		},
+	}
+}
+
+// Servers of a MinecraftServerDeployment are replaced on each update,
+// their backups are grouped under the name of the deployment.
+func (b *MinecraftServerResourcePodBuilder) getBackupPrefix() string {
+	if b.Instance.Spec.Backup.Bucket.Prefix != "" {
+		return b.Instance.Spec.Backup.Bucket.Prefix
+	}
+
+	if ownerReference := b.getDeploymentOwnerReference(); ownerReference != nil {
+		return fmt.Sprintf("%s/%s/%s", b.Instance.Namespace, ownerReference.Name, b.Instance.Name)
+	}
+	return fmt.Sprintf("%s/%s", b.Instance.Namespace, b.Instance.Name)
+}
+
+func (b *MinecraftServerResourcePodBuilder) getDataVolumeSource() corev1.VolumeSource {
+	if b.Instance.Spec.Persistence != nil {
+		return corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: b.GetPersistentVolumeClaimName(),
+			},
+		}
+	}
+
+	return corev1.VolumeSource{
+		EmptyDir: &corev1.EmptyDirVolumeSource{},
+	}
+}
+
+func (b *MinecraftServerResourcePodBuilder) getPodSecurityContext() *corev1.PodSecurityContext {
+	fsGroup := int64(1000)
+
+	return &corev1.PodSecurityContext{
+		FSGroup: &fsGroup,
+	}
+}
+
 func (b *MinecraftServerResourcePodBuilder) getSecurityContext() *corev1.SecurityContext {
 	securityEscalation := false
 	readOnlyFs := true
@@ -0,0 +1,77 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+type MinecraftServerResourceRconSecretBuilder struct {
+	*MinecraftServerResourceBuilder
+}
+
+func (b *MinecraftServerResourceBuilder) MinecraftServerRconSecret() *MinecraftServerResourceRconSecretBuilder {
+	return &MinecraftServerResourceRconSecretBuilder{b}
+}
+
+func (b *MinecraftServerResourceRconSecretBuilder) Build() (client.Object, error) {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.GetRconSecretName(),
+			Namespace: b.Instance.Namespace,
+			Labels:    b.getLabels(),
+		},
+		Type: corev1.SecretTypeOpaque,
+	}, nil
+}
+
+func (b *MinecraftServerResourceRconSecretBuilder) Update(object client.Object) error {
+	secret := object.(*corev1.Secret)
+
+	// The password must stay the same for the whole life of the Pod
+	if len(secret.Data["password"]) == 0 {
+		password, err := getRconPassword()
+		if err != nil {
+			return fmt.Errorf("failed generating RCON password: %v", err)
+		}
+
+		secret.Data = map[string][]byte{
+			"password": []byte(password),
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(b.Instance, secret, b.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference for Secret: %v", err)
+	}
+
+	return nil
+}
+
+func (b *MinecraftServerResourceRconSecretBuilder) CanBeUpdated() bool {
+	return true
+}
+
+const rconPasswordChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func getRconPassword() (string, error) {
+	password := make([]byte, 32)
+
+	for i := range password {
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(rconPasswordChars))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = rconPasswordChars[index.Int64()]
+	}
+	return string(password), nil
+}
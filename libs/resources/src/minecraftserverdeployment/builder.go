@@ -6,18 +6,24 @@ SPDX-License-Identifier: GPL-3.0-or-later
 package resources
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
 	common "github.com/iamblueslime/shulker/libs/resources/src"
+	minecraftServerResources "github.com/iamblueslime/shulker/libs/resources/src/minecraftserver"
 )
 
 type MinecraftServerDeploymentResourceBuilder struct {
 	Instance *shulkermciov1alpha1.MinecraftServerDeployment
 	Scheme   *runtime.Scheme
+	Client   client.Client
+	Ctx      context.Context
 }
 
 func (b *MinecraftServerDeploymentResourceBuilder) ResourceBuilders() ([]common.ResourceBuilder, []common.ResourceBuilder) {
@@ -53,3 +59,31 @@ func (b *MinecraftServerDeploymentResourceBuilder) getLabels() map[string]string
 
 	return labels
 }
+
+// Returns the resources of the template, resolved once per template
+// hash so all the servers created from it use the very same ones.
+func (b *MinecraftServerDeploymentResourceBuilder) GetResourcesLock(templateHash string) (*shulkermciov1alpha1.ResourceRefLock, error) {
+	if resourcesLock := b.Instance.Status.ResourcesLock; resourcesLock != nil && resourcesLock.TemplateHash == templateHash {
+		return resourcesLock, nil
+	}
+
+	resourceRefResolver := common.ResourceRefResolver{
+		Client:    b.Client,
+		Ctx:       b.Ctx,
+		Namespace: b.Instance.Namespace,
+	}
+	resolvedResources, err := minecraftServerResources.ResolveMinecraftServerResources(&resourceRefResolver, &b.Instance.Spec.Template.Spec)
+	if err != nil {
+		// Paths are relative to the spec of the servers
+		var resolutionError *common.ResourceRefResolutionError
+		if errors.As(err, &resolutionError) {
+			resolutionError.Path = "spec.template." + resolutionError.Path
+		}
+		return nil, err
+	}
+
+	return &shulkermciov1alpha1.ResourceRefLock{
+		TemplateHash: templateHash,
+		Resources:    common.ToLockedResourceRefs(resolvedResources),
+	}, nil
+}
@@ -37,7 +37,7 @@ func (b *MinecraftServerDeploymentResourceConfigMapBuilder) Build() (client.Obje
 func (b *MinecraftServerDeploymentResourceConfigMapBuilder) Update(object client.Object) error {
 	configMap := object.(*corev1.ConfigMap)
 
-	configMapData, err := minecraftserver.GetConfigMapDataFromConfigSpec(&b.Instance.Spec.Template.Spec.Configuration)
+	configMapData, err := minecraftserver.GetConfigMapDataFromConfigSpec(&b.Instance.Spec.Template.Spec.Configuration, b.Instance.Spec.Template.Spec.Backup != nil)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,67 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+type modrinthVersion struct {
+	VersionNumber string `json:"version_number"`
+	Files         []struct {
+		Url      string `json:"url"`
+		Filename string `json:"filename"`
+		Primary  bool   `json:"primary"`
+		Hashes   struct {
+			Sha512 string `json:"sha512"`
+		} `json:"hashes"`
+	} `json:"files"`
+}
+
+func (r *ResourceRefResolver) resolveModrinthRef(modrinthSelector *v1alpha1.ResourceRefModrinthSelector) (*ResolvedResourceRef, error) {
+	query := url.Values{}
+	if modrinthSelector.Loader != "" {
+		query.Set("loaders", fmt.Sprintf("[%q]", modrinthSelector.Loader))
+	}
+	if modrinthSelector.GameVersion != "" {
+		query.Set("game_versions", fmt.Sprintf("[%q]", modrinthSelector.GameVersion))
+	}
+
+	// Versions are listed from the most recent one
+	var versions []modrinthVersion
+	versionsUrl := fmt.Sprintf("%s/v2/project/%s/version?%s", r.getSourceAPIs().Modrinth, url.PathEscape(modrinthSelector.Project), query.Encode())
+	if err := r.getSourceJSON(versionsUrl, nil, nil, &versions); err != nil {
+		return nil, err
+	}
+
+	for _, version := range versions {
+		matches, err := matchVersion(version.VersionNumber, modrinthSelector.Version)
+		if err != nil {
+			return nil, err
+		} else if !matches || len(version.Files) == 0 {
+			continue
+		}
+
+		file := version.Files[0]
+		for _, candidate := range version.Files {
+			if candidate.Primary {
+				file = candidate
+				break
+			}
+		}
+
+		return &ResolvedResourceRef{
+			Url:     file.Url,
+			Version: version.VersionNumber,
+			Sha512:  file.Hashes.Sha512,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no version of Modrinth project %s matches the selector", modrinthSelector.Project)
+}
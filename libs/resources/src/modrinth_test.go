@@ -0,0 +1,87 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+const modrinthVersions = `[
+  {
+    "version_number": "2.0.0",
+    "files": [{"url": "https://cdn.modrinth.com/plugin-2.0.0.jar", "filename": "plugin-2.0.0.jar", "primary": true, "hashes": {"sha512": "aaaa"}}]
+  },
+  {
+    "version_number": "1.5.0",
+    "files": [
+      {"url": "https://cdn.modrinth.com/plugin-1.5.0-sources.jar", "filename": "plugin-1.5.0-sources.jar", "primary": false, "hashes": {"sha512": "bbbb"}},
+      {"url": "https://cdn.modrinth.com/plugin-1.5.0.jar", "filename": "plugin-1.5.0.jar", "primary": true, "hashes": {"sha512": "cccc"}}
+    ]
+  }
+]`
+
+var _ = Describe("Modrinth source", func() {
+	var standIn *apiStandIn
+	var resolver *ResourceRefResolver
+
+	BeforeEach(func() {
+		standIn = newAPIStandIn()
+		standIn.responses["/v2/project/plugin/version"] = modrinthVersions
+		resolver = standIn.newResolver()
+	})
+
+	AfterEach(func() {
+		standIn.server.Close()
+	})
+
+	resolveModrinthRef := func(selector v1alpha1.ResourceRefModrinthSelector) (*ResolvedResourceRef, error) {
+		selector.Project = "plugin"
+		return resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{ModrinthRef: &selector},
+		})
+	}
+
+	It("resolves the most recent version with its checksum", func() {
+		resolvedRef, err := resolveModrinthRef(v1alpha1.ResourceRefModrinthSelector{Loader: "paper", GameVersion: "1.20.1"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal("https://cdn.modrinth.com/plugin-2.0.0.jar"))
+		Expect(resolvedRef.Version).To(Equal("2.0.0"))
+		Expect(resolvedRef.Sha512).To(Equal("aaaa"))
+
+		query := standIn.getLastRequest().URL.Query()
+		Expect(query.Get("loaders")).To(Equal(`["paper"]`))
+		Expect(query.Get("game_versions")).To(Equal(`["1.20.1"]`))
+	})
+
+	It("resolves the primary file of a version range", func() {
+		resolvedRef, err := resolveModrinthRef(v1alpha1.ResourceRefModrinthSelector{Version: ">=1.0.0 <2.0.0"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal("https://cdn.modrinth.com/plugin-1.5.0.jar"))
+		Expect(resolvedRef.Sha512).To(Equal("cccc"))
+	})
+
+	It("prefers the checksum given in the spec", func() {
+		resolvedRef, err := resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom:  &v1alpha1.ResourceRefSource{ModrinthRef: &v1alpha1.ResourceRefModrinthSelector{Project: "plugin"}},
+			Checksum: &v1alpha1.ResourceRefChecksum{Sha256: "DDDD"},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Sha256).To(Equal("dddd"))
+		Expect(resolvedRef.Sha512).To(BeEmpty())
+	})
+
+	It("fails when no version matches", func() {
+		_, err := resolveModrinthRef(v1alpha1.ResourceRefModrinthSelector{Version: "3.0.0"})
+
+		Expect(err).To(MatchError(ContainSubstring("no version of Modrinth project plugin")))
+	})
+})
@@ -0,0 +1,61 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+// The key is only read by the init container, checking it beforehand
+// avoids creating a Pod which cannot start.
+func (r *ResourceRefResolver) resolveConfigMapRef(keySelector *v1alpha1.ResourceRefKeySelector) (*ResolvedResourceRef, error) {
+	configMap := &corev1.ConfigMap{}
+	err := r.Get(r.Ctx, types.NamespacedName{
+		Namespace: r.Namespace,
+		Name:      keySelector.Name,
+	}, configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s: %v", keySelector.Name, err)
+	}
+
+	_, hasData := configMap.Data[keySelector.Key]
+	_, hasBinaryData := configMap.BinaryData[keySelector.Key]
+	if !hasData && !hasBinaryData {
+		return nil, fmt.Errorf("missing %s in ConfigMap %s", keySelector.Key, keySelector.Name)
+	}
+
+	return &ResolvedResourceRef{
+		Url:           fmt.Sprintf("configmap://%s/%s", keySelector.Name, keySelector.Key),
+		ConfigMapName: keySelector.Name,
+		Key:           keySelector.Key,
+	}, nil
+}
+
+func (r *ResourceRefResolver) resolveSecretRef(keySelector *v1alpha1.ResourceRefKeySelector) (*ResolvedResourceRef, error) {
+	secret := &corev1.Secret{}
+	err := r.Get(r.Ctx, types.NamespacedName{
+		Namespace: r.Namespace,
+		Name:      keySelector.Name,
+	}, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s: %v", keySelector.Name, err)
+	}
+
+	if _, ok := secret.Data[keySelector.Key]; !ok {
+		return nil, fmt.Errorf("missing %s in Secret %s", keySelector.Key, keySelector.Name)
+	}
+
+	return &ResolvedResourceRef{
+		Url:        fmt.Sprintf("secret://%s/%s", keySelector.Name, keySelector.Key),
+		SecretName: keySelector.Name,
+		Key:        keySelector.Key,
+	}, nil
+}
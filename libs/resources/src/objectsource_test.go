@@ -0,0 +1,79 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	initfs "github.com/iamblueslime/shulker/libs/initfs/src"
+)
+
+var _ = Describe("ConfigMap and Secret sources", func() {
+	var standIn *apiStandIn
+	var resolver *ResourceRefResolver
+
+	BeforeEach(func() {
+		standIn = newAPIStandIn()
+		resolver = standIn.newResolver(
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "patches", Namespace: "default"},
+				BinaryData: map[string][]byte{"patch.jar": []byte("patch")},
+			},
+			newTestSecret("private-plugins", map[string]string{"plugin.jar": "plugin"}),
+		)
+	})
+
+	AfterEach(func() {
+		standIn.server.Close()
+	})
+
+	It("resolves binary data keys of ConfigMaps to their mounted file", func() {
+		resolvedRef, err := resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{ConfigMapRef: &v1alpha1.ResourceRefKeySelector{Name: "patches", Key: "patch.jar"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		resource := resolvedRef.ToManifestResource("patch", initfs.DownloadManifestResourceAction, "/plugins")
+		Expect(resource.Url).To(BeEmpty())
+		Expect(resource.Path).To(Equal(ResourceRefConfigMapsDir + "/patches/patch.jar"))
+	})
+
+	It("resolves keys of Secrets to their mounted file", func() {
+		resolvedRef, err := resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{SecretRef: &v1alpha1.ResourceRefKeySelector{Name: "private-plugins", Key: "plugin.jar"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		resource := resolvedRef.ToManifestResource("plugin", initfs.DownloadManifestResourceAction, "/plugins")
+		Expect(resource.Path).To(Equal(ResourceRefSecretsDir + "/private-plugins/plugin.jar"))
+	})
+
+	It("fails when the key is missing", func() {
+		_, err := resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{ConfigMapRef: &v1alpha1.ResourceRefKeySelector{Name: "patches", Key: "missing.jar"}},
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("missing missing.jar in ConfigMap patches")))
+	})
+
+	It("mounts only the used keys, once per object", func() {
+		volumes, volumeMounts := GetResourceRefVolumes([]ResolvedResourceRef{
+			{ConfigMapName: "patches", Key: "b.jar"},
+			{ConfigMapName: "patches", Key: "a.jar"},
+			{SecretName: "private-plugins", Key: "plugin.jar"},
+		})
+
+		Expect(volumes).To(HaveLen(2))
+		Expect(volumes[0].ConfigMap.Name).To(Equal("patches"))
+		Expect(volumes[0].ConfigMap.Items).To(Equal([]corev1.KeyToPath{{Key: "a.jar", Path: "a.jar"}, {Key: "b.jar", Path: "b.jar"}}))
+		Expect(volumes[1].Secret.SecretName).To(Equal("private-plugins"))
+		Expect(volumeMounts[1].MountPath).To(Equal(ResourceRefSecretsDir + "/private-plugins"))
+	})
+})
@@ -0,0 +1,186 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	initfs "github.com/iamblueslime/shulker/libs/initfs/src"
+)
+
+const (
+	ociDefaultTag          = "latest"
+	ociTitleAnnotation     = "org.opencontainers.image.title"
+	ociDockerHubRegistry   = "registry-1.docker.io"
+	ociManifestMediaTypes  = "application/vnd.oci.image.manifest.v1+json, application/vnd.docker.distribution.manifest.v2+json"
+	ociIndexMediaType      = "application/vnd.oci.image.index.v1+json"
+	ociDockerListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+type ociManifest struct {
+	MediaType string `json:"mediaType"`
+	Layers    []struct {
+		Digest      string            `json:"digest"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"layers"`
+}
+
+func (r *ResourceRefResolver) resolveOCIRef(ociSelector *v1alpha1.ResourceRefOCISelector) (*ResolvedResourceRef, error) {
+	var credentials *sourceCredentials
+	if ociSelector.CredentialsSecretName != "" {
+		var err error
+		credentials, err = r.getCredentials(ociSelector.CredentialsSecretName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	registry, name := parseOCIRepository(ociSelector.Repository)
+	repositoryUrl := fmt.Sprintf("%s/v2/%s", getOCIRegistryUrl(registry), name)
+
+	reference := ociSelector.Digest
+	if reference == "" {
+		reference = ociSelector.Tag
+	}
+	if reference == "" {
+		reference = ociDefaultTag
+	}
+
+	content, err := r.getOCISource(fmt.Sprintf("%s/manifests/%s", repositoryUrl, reference), credentials, ociManifestMediaTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest of %s: %v", ociSelector.Repository, err)
+	}
+
+	manifestChecksum := sha256.Sum256(content)
+	manifestDigest := "sha256:" + hex.EncodeToString(manifestChecksum[:])
+	if ociSelector.Digest != "" && ociSelector.Digest != manifestDigest {
+		return nil, fmt.Errorf("manifest of %s does not match digest %s", ociSelector.Repository, ociSelector.Digest)
+	}
+
+	manifest := ociManifest{}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest of %s: %v", ociSelector.Repository, err)
+	}
+	if manifest.MediaType == ociIndexMediaType || manifest.MediaType == ociDockerListMediaType {
+		return nil, fmt.Errorf("%s is a multi-platform image, not an artifact", ociSelector.Repository)
+	}
+
+	layerIndex := -1
+	for i, layer := range manifest.Layers {
+		if ociSelector.FileName == "" || layer.Annotations[ociTitleAnnotation] == ociSelector.FileName {
+			if layerIndex != -1 {
+				return nil, fmt.Errorf("artifact %s has several files, the file name must be given", ociSelector.Repository)
+			}
+			layerIndex = i
+		}
+	}
+	if layerIndex == -1 {
+		return nil, fmt.Errorf("no file of artifact %s matches the selector", ociSelector.Repository)
+	}
+	layer := manifest.Layers[layerIndex]
+
+	algorithm, checksum, _ := strings.Cut(layer.Digest, ":")
+	if algorithm != "sha256" {
+		return nil, fmt.Errorf("unsupported digest %s in artifact %s", layer.Digest, ociSelector.Repository)
+	}
+
+	// Blob URLs end with their digest, which is not a usable file name
+	fileName := layer.Annotations[ociTitleAnnotation]
+	if fileName == "" {
+		fileName = path.Base(name) + ".jar"
+	}
+
+	return &ResolvedResourceRef{
+		Url:                   fmt.Sprintf("%s/blobs/%s", repositoryUrl, layer.Digest),
+		Version:               manifestDigest,
+		CredentialsSecretName: ociSelector.CredentialsSecretName,
+		Sha256:                checksum,
+		FileName:              fileName,
+	}, nil
+}
+
+// Splits a repository in its registry and name, following the
+// conventions of container images: repositories without registry are
+// on Docker Hub.
+func parseOCIRepository(repository string) (string, string) {
+	registry, name, found := strings.Cut(repository, "/")
+	if !found || (!strings.ContainsAny(registry, ".:") && registry != "localhost") {
+		registry = ociDockerHubRegistry
+		name = repository
+	} else if registry == "docker.io" {
+		registry = ociDockerHubRegistry
+	}
+
+	if registry == ociDockerHubRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+
+	return registry, name
+}
+
+// Local registries are usually served without TLS.
+func getOCIRegistryUrl(registry string) string {
+	host := registry
+	if i := strings.LastIndex(host, ":"); i != -1 {
+		host = host[:i]
+	}
+
+	if host == "localhost" || host == "127.0.0.1" {
+		return "http://" + registry
+	}
+	return "https://" + registry
+}
+
+// Queries a registry, answering its Bearer challenge if any.
+func (r *ResourceRefResolver) getOCISource(url string, credentials *sourceCredentials, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(r.Ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("User-Agent", "iamblueslime/shulker")
+
+	var username, password string
+	if credentials != nil {
+		username, password = credentials.username, credentials.password
+		req.SetBasicAuth(username, password)
+	}
+
+	httpClient := r.getHTTPClient()
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if initfs.IsBearerChallenge(res) {
+		token, err := initfs.GetBearerToken(r.Ctx, httpClient, res, username, password)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+		res, err = httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, resourceSourceMaxResponseSize))
+}
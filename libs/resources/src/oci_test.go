@@ -0,0 +1,101 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+const ociManifestContent = `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.manifest.v1+json",
+  "layers": [
+    {"digest": "sha256:aaaa", "annotations": {"org.opencontainers.image.title": "plugin-1.0.jar"}},
+    {"digest": "sha256:bbbb", "annotations": {"org.opencontainers.image.title": "plugin-1.0-sources.jar"}}
+  ]
+}`
+
+var _ = Describe("OCI source", func() {
+	var registry *httptest.Server
+	var resolver *ResourceRefResolver
+	var repository string
+
+	BeforeEach(func() {
+		// Registry asking for a token, like most public registries
+		registry = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/token":
+				_, _ = w.Write([]byte(`{"access_token":"abcd"}`))
+			case r.Header.Get("Authorization") != "Bearer abcd":
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="registry"`, r.Host))
+				w.WriteHeader(http.StatusUnauthorized)
+			case strings.HasPrefix(r.URL.Path, "/v2/owner/plugin/manifests/"):
+				_, _ = w.Write([]byte(ociManifestContent))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		standIn := newAPIStandIn()
+		DeferCleanup(standIn.server.Close)
+		resolver = standIn.newResolver()
+		resolver.HTTPClient = registry.Client()
+		repository = strings.TrimPrefix(registry.URL, "http://") + "/owner/plugin"
+	})
+
+	AfterEach(func() {
+		registry.Close()
+	})
+
+	It("resolves the blob of the selected file", func() {
+		resolvedRef, err := resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{OCIRef: &v1alpha1.ResourceRefOCISelector{Repository: repository, Tag: "1.0", FileName: "plugin-1.0.jar"}},
+		})
+
+		manifestChecksum := sha256.Sum256([]byte(ociManifestContent))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resolvedRef.Url).To(Equal(registry.URL + "/v2/owner/plugin/blobs/sha256:aaaa"))
+		Expect(resolvedRef.Version).To(Equal("sha256:" + hex.EncodeToString(manifestChecksum[:])))
+		Expect(resolvedRef.Sha256).To(Equal("aaaa"))
+		Expect(resolvedRef.FileName).To(Equal("plugin-1.0.jar"))
+	})
+
+	It("fails when the artifact has several files and none is selected", func() {
+		_, err := resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{OCIRef: &v1alpha1.ResourceRefOCISelector{Repository: repository, Tag: "1.0"}},
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("the file name must be given")))
+	})
+
+	It("fails when the manifest does not match the digest", func() {
+		_, err := resolver.Resolve(&v1alpha1.ResourceRef{
+			UrlFrom: &v1alpha1.ResourceRefSource{OCIRef: &v1alpha1.ResourceRefOCISelector{Repository: repository, Digest: "sha256:" + strings.Repeat("0", 64)}},
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("does not match digest")))
+	})
+
+	It("defaults to Docker Hub for repositories without registry", func() {
+		registryHost, name := parseOCIRepository("plugin")
+		Expect(registryHost).To(Equal("registry-1.docker.io"))
+		Expect(name).To(Equal("library/plugin"))
+
+		registryHost, name = parseOCIRepository("ghcr.io/owner/plugin")
+		Expect(registryHost).To(Equal("ghcr.io"))
+		Expect(name).To(Equal("owner/plugin"))
+	})
+})
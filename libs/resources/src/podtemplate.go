@@ -0,0 +1,57 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// Returns the union of the given maps, the values of the last ones
+// taking precedence.
+func MergeStringMaps(maps ...map[string]string) map[string]string {
+	merged := map[string]string{}
+	for _, m := range maps {
+		for key, value := range m {
+			merged[key] = value
+		}
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// Applies a strategic merge patch, with metadata and spec fields, to
+// a Pod.
+func ApplyPodTemplatePatch(pod *corev1.Pod, patch *runtime.RawExtension) error {
+	if patch == nil || len(patch.Raw) == 0 {
+		return nil
+	}
+
+	original, err := json.Marshal(pod)
+	if err != nil {
+		return err
+	}
+
+	patched, err := strategicpatch.StrategicMergePatch(original, patch.Raw, corev1.Pod{})
+	if err != nil {
+		return fmt.Errorf("failed to apply pod template patch: %v", err)
+	}
+
+	patchedPod := corev1.Pod{}
+	if err := json.Unmarshal(patched, &patchedPod); err != nil {
+		return fmt.Errorf("failed to apply pod template patch: %v", err)
+	}
+
+	*pod = patchedPod
+	return nil
+}
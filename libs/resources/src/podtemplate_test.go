@@ -0,0 +1,56 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Pod templates", func() {
+	It("merges maps with the last ones taking precedence", func() {
+		Expect(MergeStringMaps(
+			map[string]string{"a": "user", "b": "user"},
+			map[string]string{"b": "shulker"},
+		)).To(Equal(map[string]string{"a": "user", "b": "shulker"}))
+		Expect(MergeStringMaps(nil, map[string]string{})).To(BeNil())
+	})
+
+	It("applies a strategic merge patch to a pod", func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "server", Labels: map[string]string{"app": "shulker"}},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "minecraft-server", Image: "itzg/minecraft-server"},
+					{Name: "backup", Image: "shulker-backup"},
+				},
+			},
+		}
+
+		Expect(ApplyPodTemplatePatch(pod, &runtime.RawExtension{Raw: []byte(`{
+			"metadata": {"labels": {"team": "games"}},
+			"spec": {
+				"hostNetwork": true,
+				"containers": [{"name": "minecraft-server", "stdin": true}]
+			}
+		}`)})).To(Succeed())
+
+		Expect(pod.Name).To(Equal("server"))
+		Expect(pod.Labels).To(Equal(map[string]string{"app": "shulker", "team": "games"}))
+		Expect(pod.Spec.HostNetwork).To(BeTrue())
+		Expect(pod.Spec.Containers).To(HaveLen(2))
+		Expect(pod.Spec.Containers[0].Image).To(Equal("itzg/minecraft-server"))
+		Expect(pod.Spec.Containers[0].Stdin).To(BeTrue())
+	})
+
+	It("fails on an invalid patch", func() {
+		pod := &corev1.Pod{}
+		Expect(ApplyPodTemplatePatch(pod, &runtime.RawExtension{Raw: []byte(`{"spec": {"containers": "nope"}}`)})).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,127 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+// Port the probe sidecar of the servers and proxies listens on.
+const ProbePort = 8081
+
+// Name of the probe sidecar, which cannot be taken by other
+// containers.
+const ProbeContainerName = "probe"
+
+const (
+	defaultProbePeriodSeconds    = 10
+	defaultProbeTimeoutSeconds   = 5
+	defaultProbeFailureThreshold = 3
+)
+
+// Returns the startup, liveness and readiness probes of the main
+// container of a server or proxy, the readiness one running the given
+// script in Exec mode. The startup probe holds back the other ones,
+// so they need no initial delay.
+func GetProbes(spec *v1alpha1.ProbesSpec, readinessScript string, defaultStartupFailureThreshold int32) (*corev1.Probe, *corev1.Probe, *corev1.Probe) {
+	handler := corev1.ProbeHandler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"bash", "/health.sh"},
+		},
+	}
+	readinessHandler := corev1.ProbeHandler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"bash", readinessScript},
+		},
+	}
+	if spec.GetMode() == v1alpha1.ProbeModeServerListPing {
+		handler = corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/probe",
+				Port: intstr.FromInt(ProbePort),
+			},
+		}
+		readinessHandler = corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/ready",
+				Port: intstr.FromInt(ProbePort),
+			},
+		}
+	}
+
+	var startup, liveness, readiness *v1alpha1.ProbeThresholdsSpec
+	if spec != nil {
+		startup, liveness, readiness = spec.Startup, spec.Liveness, spec.Readiness
+	}
+
+	return newProbe(handler, defaultStartupFailureThreshold, startup),
+		newProbe(handler, defaultProbeFailureThreshold, liveness),
+		newProbe(readinessHandler, defaultProbeFailureThreshold, readiness)
+}
+
+func newProbe(handler corev1.ProbeHandler, defaultFailureThreshold int32, thresholds *v1alpha1.ProbeThresholdsSpec) *corev1.Probe {
+	probe := &corev1.Probe{
+		ProbeHandler:     handler,
+		PeriodSeconds:    defaultProbePeriodSeconds,
+		TimeoutSeconds:   defaultProbeTimeoutSeconds,
+		FailureThreshold: defaultFailureThreshold,
+	}
+
+	if thresholds != nil {
+		if thresholds.InitialDelaySeconds != nil {
+			probe.InitialDelaySeconds = *thresholds.InitialDelaySeconds
+		}
+		if thresholds.PeriodSeconds != nil {
+			probe.PeriodSeconds = *thresholds.PeriodSeconds
+		}
+		if thresholds.TimeoutSeconds != nil {
+			probe.TimeoutSeconds = *thresholds.TimeoutSeconds
+		}
+		if thresholds.FailureThreshold != nil {
+			probe.FailureThreshold = *thresholds.FailureThreshold
+		}
+	}
+
+	return probe
+}
+
+// Returns the sidecar answering the HTTP probes by pinging the
+// server or proxy listening on the given port, when the probes use
+// the Server List Ping protocol.
+func GetProbeContainer(spec *v1alpha1.ProbesSpec, image string, port int32, securityContext *corev1.SecurityContext) *corev1.Container {
+	if spec.GetMode() != v1alpha1.ProbeModeServerListPing {
+		return nil
+	}
+
+	return &corev1.Container{
+		Image: image,
+		Name:  ProbeContainerName,
+		Args: []string{
+			fmt.Sprintf("--listen-address=:%d", ProbePort),
+			fmt.Sprintf("--target-address=localhost:%d", port),
+		},
+		Ports: []corev1.ContainerPort{{
+			Name:          "probe",
+			ContainerPort: ProbePort,
+		}},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("16Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("32Mi"),
+			},
+		},
+		SecurityContext: securityContext,
+	}
+}
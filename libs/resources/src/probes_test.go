@@ -0,0 +1,50 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("Probes", func() {
+	It("runs the health script by default", func() {
+		startup, liveness, readiness := GetProbes(nil, "/server/probe-readiness.sh", 30)
+
+		Expect(startup.Exec.Command).To(Equal([]string{"bash", "/health.sh"}))
+		Expect(startup.FailureThreshold).To(Equal(int32(30)))
+		Expect(liveness.Exec.Command).To(Equal([]string{"bash", "/health.sh"}))
+		Expect(liveness.InitialDelaySeconds).To(BeZero())
+		Expect(readiness.Exec.Command).To(Equal([]string{"bash", "/server/probe-readiness.sh"}))
+		Expect(GetProbeContainer(nil, DefaultProbeImage, 25565, nil)).To(BeNil())
+	})
+
+	It("pings the server from a sidecar", func() {
+		periodSeconds, failureThreshold := int32(20), int32(5)
+		spec := &v1alpha1.ProbesSpec{
+			Mode: v1alpha1.ProbeModeServerListPing,
+			Liveness: &v1alpha1.ProbeThresholdsSpec{
+				PeriodSeconds:    &periodSeconds,
+				FailureThreshold: &failureThreshold,
+			},
+		}
+		_, liveness, readiness := GetProbes(spec, "/health.sh", 30)
+
+		Expect(liveness.HTTPGet.Path).To(Equal("/probe"))
+		Expect(liveness.HTTPGet.Port.IntValue()).To(Equal(ProbePort))
+		Expect(liveness.PeriodSeconds).To(Equal(int32(20)))
+		Expect(liveness.FailureThreshold).To(Equal(int32(5)))
+		Expect(liveness.TimeoutSeconds).To(Equal(int32(defaultProbeTimeoutSeconds)))
+		Expect(readiness.HTTPGet.Path).To(Equal("/ready"))
+
+		container := GetProbeContainer(spec, DefaultProbeImage, 25577, nil)
+		Expect(container).NotTo(BeNil())
+		Expect(container.Name).To(Equal(ProbeContainerName))
+		Expect(container.Args).To(ContainElement("--target-address=localhost:25577"))
+	})
+})
@@ -20,18 +20,34 @@ type ProxyResourceBuilder struct {
 	Scheme   *runtime.Scheme
 	Client   client.Client
 	Ctx      context.Context
+
+	// Images of the created containers.
+	Images *common.Images
+
+	// Cluster of the instance, its artifact cache is used to
+	// download the resources when it has one.
+	Cluster *shulkermciov1alpha1.MinecraftCluster
+
+	resolvedResources *proxyResolvedResources
+}
+
+type proxyResolvedResources struct {
+	Plugins []common.ResolvedResourceRef
+	Patches []common.ResolvedResourceRef
 }
 
 func (b *ProxyResourceBuilder) ResourceBuilders() ([]common.ResourceBuilder, []common.ResourceBuilder) {
-	builders := []common.ResourceBuilder{
-		b.ProxyPod(),
-	}
+	builders := []common.ResourceBuilder{}
 	dirtyBuilders := []common.ResourceBuilder{}
 
 	if b.Instance.Spec.Configuration.ExistingConfigMapName == "" {
 		builders = append(builders, b.ProxyConfigMap())
 	}
 
+	// The Pod is held back when its resources cannot be resolved, so
+	// it comes last
+	builders = append(builders, b.ProxyInitConfigMap(), b.ProxyPod())
+
 	return builders, dirtyBuilders
 }
 
@@ -39,6 +55,10 @@ func (b *ProxyResourceBuilder) GetPodName() string {
 	return b.Instance.Name
 }
 
+func (b *ProxyResourceBuilder) GetInitConfigMapName() string {
+	return fmt.Sprintf("%s-init", b.Instance.Name)
+}
+
 func (b *ProxyResourceBuilder) GetConfigMapName() string {
 	if b.Instance.Spec.Configuration.ExistingConfigMapName != "" {
 		return b.Instance.Spec.Configuration.ExistingConfigMapName
@@ -68,3 +88,119 @@ func (b *ProxyResourceBuilder) getLabels() map[string]string {
 
 	return labels
 }
+
+// Returns how the resources of the proxy were resolved, to be
+// reported in status.
+func (b *ProxyResourceBuilder) GetResolvedResources() ([]shulkermciov1alpha1.ResolvedResourceRefStatus, error) {
+	resolvedResources, err := b.resolveResources()
+	if err != nil {
+		return nil, err
+	}
+
+	return common.ToResolvedResourceRefStatuses(resolvedResources.all()), nil
+}
+
+// Returns the resources of the proxy, resolved once per spec hash so
+// their sources are not queried on every reconciliation.
+func (b *ProxyResourceBuilder) GetResourcesLock(specHash string) (*shulkermciov1alpha1.ResourceRefLock, error) {
+	if resourcesLock := b.Instance.Status.ResourcesLock; resourcesLock != nil && resourcesLock.TemplateHash == specHash {
+		return resourcesLock, nil
+	}
+
+	resourceRefResolver := common.ResourceRefResolver{
+		Client:    b.Client,
+		Ctx:       b.Ctx,
+		Namespace: b.Instance.Namespace,
+	}
+	resolvedResources, err := ResolveProxyResources(&resourceRefResolver, &b.Instance.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &shulkermciov1alpha1.ResourceRefLock{
+		TemplateHash: specHash,
+		Resources:    common.ToLockedResourceRefs(resolvedResources),
+	}, nil
+}
+
+// Resolves the resources of the proxy once, they are needed by both
+// the init ConfigMap and the Pod. The resources locked by the owning
+// deployment, or else in status, are used as is.
+func (b *ProxyResourceBuilder) resolveResources() (*proxyResolvedResources, error) {
+	if b.resolvedResources != nil {
+		return b.resolvedResources, nil
+	}
+
+	if len(b.Instance.Spec.LockedResources) > 0 {
+		b.resolvedResources = getLockedProxyResources(b.Instance.Spec.LockedResources)
+		return b.resolvedResources, nil
+	}
+
+	if resourcesLock := b.Instance.Status.ResourcesLock; resourcesLock != nil {
+		b.resolvedResources = getLockedProxyResources(resourcesLock.Resources)
+		return b.resolvedResources, nil
+	}
+
+	resourceRefResolver := common.ResourceRefResolver{
+		Client:    b.Client,
+		Ctx:       b.Ctx,
+		Namespace: b.Instance.Namespace,
+	}
+	resolvedResources, err := resolveProxyResources(&resourceRefResolver, &b.Instance.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	b.resolvedResources = resolvedResources
+	return resolvedResources, nil
+}
+
+// Resolves the resources of a Proxy spec, to be locked in its status
+// or in the one of the deployment it is the template of.
+func ResolveProxyResources(resourceRefResolver *common.ResourceRefResolver, spec *shulkermciov1alpha1.ProxySpec) ([]common.ResolvedResourceRef, error) {
+	resolvedResources, err := resolveProxyResources(resourceRefResolver, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolvedResources.all(), nil
+}
+
+func resolveProxyResources(resourceRefResolver *common.ResourceRefResolver, spec *shulkermciov1alpha1.ProxySpec) (*proxyResolvedResources, error) {
+	resolvedResources := &proxyResolvedResources{}
+	var err error
+
+	resolvedResources.Plugins, err = resourceRefResolver.ResolveAll(spec.Configuration.Plugins, "spec.config.plugins")
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedResources.Patches, err = resourceRefResolver.ResolveAll(spec.Configuration.Patches, "spec.config.patches")
+	if err != nil {
+		return nil, err
+	}
+
+	return resolvedResources, nil
+}
+
+func getLockedProxyResources(lockedResources []shulkermciov1alpha1.LockedResourceRef) *proxyResolvedResources {
+	return &proxyResolvedResources{
+		Plugins: common.FromLockedResourceRefs(lockedResources, "spec.config.plugins"),
+		Patches: common.FromLockedResourceRefs(lockedResources, "spec.config.patches"),
+	}
+}
+
+// Returns the resources to download from the artifact cache at the
+// given URL when they can be cached.
+func (r *proxyResolvedResources) throughArtifactCache(cacheUrl string) *proxyResolvedResources {
+	return &proxyResolvedResources{
+		Plugins: common.ThroughArtifactCache(r.Plugins, cacheUrl),
+		Patches: common.ThroughArtifactCache(r.Patches, cacheUrl),
+	}
+}
+
+func (r *proxyResolvedResources) all() []common.ResolvedResourceRef {
+	var all []common.ResolvedResourceRef
+	all = append(all, r.Plugins...)
+	return append(all, r.Patches...)
+}
@@ -61,41 +61,6 @@ func (b *ProxyResourceConfigMapBuilder) CanBeUpdated() bool {
 func GetConfigMapDataFromConfigSpec(spec *shulkermciov1alpha1.ProxyConfigurationSpec) (map[string]string, error) {
 	configMapData := make(map[string]string)
 
-	configMapData["init-fs.sh"] = trimScript(`
-		#!/bin/sh
-		set -euo pipefail
-		set -o xtrace
-
-		cp "${SHULKER_CONFIG_DIR}/probe-readiness.sh" "${PROXY_DATA_DIR}/probe-readiness.sh"
-		cat "${SHULKER_CONFIG_DIR}/server-icon.png" | base64 -d > "${PROXY_DATA_DIR}/server-icon.png"
-	
-		if [ "${TYPE}" == "VELOCITY" ]; then
-			cp "${SHULKER_CONFIG_DIR}/velocity-config.toml" "${PROXY_DATA_DIR}/velocity.toml"
-			echo "dummy" > "${PROXY_DATA_DIR}/forwarding.secret"
-		else
-			cp "${SHULKER_CONFIG_DIR}/bungeecord-config.yml" "${PROXY_DATA_DIR}/config.yml"
-		fi
-	
-		mkdir -p "${PROXY_DATA_DIR}/plugins"
-		if [ "${TYPE}" == "VELOCITY" ]; then
-			(cd "${PROXY_DATA_DIR}/plugins" && wget https://maven.jeremylvln.fr/artifactory/shulker/io/shulkermc/shulker-proxy-agent-velocity/${SHULKER_PROXY_AGENT_VERSION}/shulker-proxy-agent-velocity-${SHULKER_PROXY_AGENT_VERSION}.jar)
-		else
-			(cd "${PROXY_DATA_DIR}/plugins" && wget https://maven.jeremylvln.fr/artifactory/shulker/io/shulkermc/shulker-proxy-agent-bungeecord/${SHULKER_PROXY_AGENT_VERSION}/shulker-proxy-agent-bungeecord-${SHULKER_PROXY_AGENT_VERSION}.jar)
-		fi
-
-		if [ "${PROXY_PLUGIN_URLS}" != "" ]; then
-			for plugin_url in ${PROXY_PLUGIN_URLS//;/ }; do
-				(cd "${PROXY_DATA_DIR}/plugins" && wget "${plugin_url}")
-			done
-		fi
-
-		if [ "${PROXY_PATCH_URLS}" != "" ]; then
-			for patch_url in ${PROXY_PATCH_URLS//;/ }; do
-				(cd "${PROXY_DATA_DIR}" && wget "${patch_url}" -O - | tar -xzv)
-			done
-		fi
-	`)
-
 	configMapData["probe-readiness.sh"] = trimScript(`
 		#!/bin/sh
 		set -euo pipefail
@@ -0,0 +1,128 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	initfs "github.com/iamblueslime/shulker/libs/initfs/src"
+	common "github.com/iamblueslime/shulker/libs/resources/src"
+)
+
+const proxyAgentVersion = "0.0.1"
+
+type ProxyResourceInitConfigMapBuilder struct {
+	*ProxyResourceBuilder
+}
+
+func (b *ProxyResourceBuilder) ProxyInitConfigMap() *ProxyResourceInitConfigMapBuilder {
+	return &ProxyResourceInitConfigMapBuilder{b}
+}
+
+func (b *ProxyResourceInitConfigMapBuilder) Build() (client.Object, error) {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      b.GetInitConfigMapName(),
+			Namespace: b.Instance.Namespace,
+			Labels:    b.getLabels(),
+		},
+	}, nil
+}
+
+func (b *ProxyResourceInitConfigMapBuilder) Update(object client.Object) error {
+	configMap := object.(*corev1.ConfigMap)
+
+	manifest, err := b.getInitManifest()
+	if err != nil {
+		return err
+	}
+
+	manifestJson, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	configMap.Data = map[string]string{
+		"manifest.json": string(manifestJson),
+	}
+
+	if err := controllerutil.SetControllerReference(b.Instance, configMap, b.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference for ConfigMap: %v", err)
+	}
+
+	return nil
+}
+
+func (b *ProxyResourceInitConfigMapBuilder) CanBeUpdated() bool {
+	return true
+}
+
+func (b *ProxyResourceInitConfigMapBuilder) getInitManifest() (*initfs.Manifest, error) {
+	resolvedResources, err := b.resolveResources()
+	if err != nil {
+		return nil, err
+	}
+	resolvedResources = resolvedResources.throughArtifactCache(common.GetArtifactCacheUrl(b.Cluster))
+
+	manifest := &initfs.Manifest{
+		Files: []initfs.ManifestFile{
+			b.getConfigFile("probe-readiness.sh", "probe-readiness.sh"),
+			{
+				Source:      fmt.Sprintf("%s/server-icon.png", proxyShulkerConfigDir),
+				Base64:      true,
+				Destination: fmt.Sprintf("%s/server-icon.png", proxyDataDir),
+			},
+		},
+	}
+
+	agentPlatform := "bungeecord"
+	if b.Instance.Spec.Version.Channel == shulkermciov1alpha1.ProxyVersionVelocity {
+		agentPlatform = "velocity"
+
+		// The actual secret is given by the agent, Velocity only
+		// requires the file to exist
+		manifest.Files = append(manifest.Files,
+			b.getConfigFile("velocity-config.toml", "velocity.toml"),
+			initfs.ManifestFile{
+				Content:     "dummy",
+				Destination: fmt.Sprintf("%s/forwarding.secret", proxyDataDir),
+			},
+		)
+	} else {
+		manifest.Files = append(manifest.Files, b.getConfigFile("bungeecord-config.yml", "config.yml"))
+	}
+
+	pluginsDir := fmt.Sprintf("%s/plugins", proxyDataDir)
+	manifest.Resources = append(manifest.Resources, initfs.ManifestResource{
+		Name: "shulker-proxy-agent",
+		Url: fmt.Sprintf(
+			"https://maven.jeremylvln.fr/artifactory/shulker/io/shulkermc/shulker-proxy-agent-%[1]s/%[2]s/shulker-proxy-agent-%[1]s-%[2]s.jar",
+			agentPlatform,
+			proxyAgentVersion,
+		),
+		Action:      initfs.DownloadManifestResourceAction,
+		Destination: pluginsDir,
+	})
+	manifest.Resources = append(manifest.Resources, common.ToManifestResources(resolvedResources.Plugins, "spec.config.plugins",
+		initfs.DownloadManifestResourceAction, pluginsDir)...)
+	manifest.Resources = append(manifest.Resources, common.ToManifestResources(resolvedResources.Patches, "spec.config.patches",
+		initfs.ExtractManifestResourceAction, proxyDataDir)...)
+
+	return manifest, nil
+}
+
+func (b *ProxyResourceInitConfigMapBuilder) getConfigFile(source string, destination string) initfs.ManifestFile {
+	return initfs.ManifestFile{
+		Source:      fmt.Sprintf("%s/%s", proxyShulkerConfigDir, source),
+		Destination: fmt.Sprintf("%s/%s", proxyDataDir, destination),
+	}
+}
@@ -7,7 +7,6 @@ package resources
 
 import (
 	"fmt"
-	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -22,6 +21,7 @@ const proxyShulkerConfigDir = "/mnt/shulker/config"
 const proxyShulkerForwardingSecretDir = "/mnt/shulker/forwarding-secret"
 const proxyDataDir = "/server"
 const proxyDrainLockDir = "/mnt/drain-lock"
+const proxyProbeTmpDir = "/mnt/proxy-tmp"
 
 type ProxyResourcePodBuilder struct {
 	*ProxyResourceBuilder
@@ -44,20 +44,28 @@ func (b *ProxyResourcePodBuilder) Build() (client.Object, error) {
 func (b *ProxyResourcePodBuilder) Update(object client.Object) error {
 	pod := object.(*corev1.Pod)
 
-	initEnv, err := b.getInitEnv()
+	resolvedResources, err := b.resolveResources()
 	if err != nil {
 		return err
 	}
+	resourceVolumes, resourceVolumeMounts := resources.GetResourceRefVolumes(resolvedResources.all())
+
+	startupProbe, livenessProbe, readinessProbe := resources.GetProbes(b.Instance.Spec.Probes, fmt.Sprintf("%s/probe-readiness.sh", proxyDataDir), 30)
 
 	pod.Spec = corev1.PodSpec{
 		InitContainers: []corev1.Container{
 			{
-				Image:           "alpine:latest",
-				Name:            "init-fs",
-				Command:         []string{"sh", fmt.Sprintf("%s/init-fs.sh", proxyShulkerConfigDir)},
-				Env:             initEnv,
-				SecurityContext: b.getSecurityContext(),
-				VolumeMounts: []corev1.VolumeMount{
+				Image:                    b.Images.Init,
+				Name:                     "init-fs",
+				Args:                     []string{fmt.Sprintf("--manifest=%s/manifest.json", resources.InitManifestDir)},
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				SecurityContext:          b.getSecurityContext(),
+				VolumeMounts: append([]corev1.VolumeMount{
+					{
+						Name:      "shulker-init",
+						MountPath: resources.InitManifestDir,
+						ReadOnly:  true,
+					},
 					{
 						Name:      "shulker-config",
 						MountPath: proxyShulkerConfigDir,
@@ -67,36 +75,22 @@ func (b *ProxyResourcePodBuilder) Update(object client.Object) error {
 						Name:      "proxy-data",
 						MountPath: proxyDataDir,
 					},
-				},
+				}, resourceVolumeMounts...),
 			},
 		},
 		Containers: []corev1.Container{
 			{
-				Image: "itzg/bungeecord:latest",
-				Name:  "proxy",
+				Image:                    b.getImage(),
+				Name:                     "proxy",
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 				Ports: []corev1.ContainerPort{{
 					Name:          "minecraft",
 					ContainerPort: 25577,
 				}},
-				Env: b.getEnv(),
-				LivenessProbe: &corev1.Probe{
-					ProbeHandler: corev1.ProbeHandler{
-						Exec: &corev1.ExecAction{
-							Command: []string{"bash", "/health.sh"},
-						},
-					},
-					InitialDelaySeconds: 10,
-					PeriodSeconds:       10,
-				},
-				ReadinessProbe: &corev1.Probe{
-					ProbeHandler: corev1.ProbeHandler{
-						Exec: &corev1.ExecAction{
-							Command: []string{"bash", fmt.Sprintf("%s/probe-readiness.sh", proxyDataDir)},
-						},
-					},
-					InitialDelaySeconds: 10,
-					PeriodSeconds:       10,
-				},
+				Env:             b.getEnv(),
+				StartupProbe:    startupProbe,
+				LivenessProbe:   livenessProbe,
+				ReadinessProbe:  readinessProbe,
 				SecurityContext: b.getSecurityContext(),
 				VolumeMounts: []corev1.VolumeMount{
 					{
@@ -122,7 +116,17 @@ func (b *ProxyResourcePodBuilder) Update(object client.Object) error {
 		},
 		ServiceAccountName: b.getServiceAccountName(),
 		RestartPolicy:      corev1.RestartPolicyNever,
-		Volumes: []corev1.Volume{
+		Volumes: append([]corev1.Volume{
+			{
+				Name: "shulker-init",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: b.GetInitConfigMapName(),
+						},
+					},
+				},
+			},
 			{
 				Name: "shulker-config",
 				VolumeSource: corev1.VolumeSource{
@@ -159,16 +163,67 @@ func (b *ProxyResourcePodBuilder) Update(object client.Object) error {
 					EmptyDir: &corev1.EmptyDirVolumeSource{},
 				},
 			},
-		},
+		}, resourceVolumes...),
 	}
 
+	// The agent creates the drain lock in the temporary directory of
+	// the proxy, which the probe sidecar reads to stop being ready.
+	// The sidecar also watches the proxy process to stop along with
+	// it, for the Pod to complete
+	if probeContainer := resources.GetProbeContainer(b.Instance.Spec.Probes, b.Images.Probe, 25577, b.getSecurityContext()); probeContainer != nil {
+		shareProcessNamespace := true
+		probeContainer.Args = append(probeContainer.Args, fmt.Sprintf("--drain-lock-file=%s/drain-lock", proxyProbeTmpDir))
+		probeContainer.VolumeMounts = append(probeContainer.VolumeMounts, corev1.VolumeMount{
+			Name:      "proxy-tmp",
+			MountPath: proxyProbeTmpDir,
+			ReadOnly:  true,
+		})
+		pod.Spec.Containers = append(pod.Spec.Containers, *probeContainer)
+		pod.Spec.ShareProcessNamespace = &shareProcessNamespace
+	}
+
+	var pullPolicy corev1.PullPolicy
+	var pullSecrets []corev1.LocalObjectReference
 	if b.Instance.Spec.PodOverrides != nil {
-		if b.Instance.Spec.PodOverrides.Resources != nil {
-			pod.Spec.Containers[0].Resources = *b.Instance.Spec.PodOverrides.Resources
+		podOverrides := b.Instance.Spec.PodOverrides
+
+		if podOverrides.Image != "" {
+			pod.Spec.Containers[0].Image = podOverrides.Image
+		}
+
+		if podOverrides.Resources != nil {
+			pod.Spec.Containers[0].Resources = *podOverrides.Resources
 		}
 
-		if b.Instance.Spec.PodOverrides.Affinity != nil {
-			pod.Spec.Affinity = b.Instance.Spec.PodOverrides.Affinity
+		if podOverrides.Affinity != nil {
+			pod.Spec.Affinity = podOverrides.Affinity
+		}
+
+		if podOverrides.ServiceAccountName != "" {
+			pod.Spec.ServiceAccountName = podOverrides.ServiceAccountName
+		}
+
+		// The labels of Shulker select the Pod, they cannot be
+		// overridden
+		pod.Labels = resources.MergeStringMaps(podOverrides.Labels, pod.Labels)
+		pod.Annotations = resources.MergeStringMaps(pod.Annotations, podOverrides.Annotations)
+
+		pod.Spec.NodeSelector = podOverrides.NodeSelector
+		pod.Spec.Tolerations = podOverrides.Tolerations
+		pod.Spec.TopologySpreadConstraints = podOverrides.TopologySpreadConstraints
+		pod.Spec.PriorityClassName = podOverrides.PriorityClassName
+		pod.Spec.Volumes = append(pod.Spec.Volumes, podOverrides.Volumes...)
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, podOverrides.VolumeMounts...)
+		pod.Spec.Containers = append(pod.Spec.Containers, podOverrides.Sidecars...)
+
+		pullPolicy = podOverrides.ImagePullPolicy
+		pullSecrets = podOverrides.ImagePullSecrets
+	}
+	b.Images.ApplyPullOptions(&pod.Spec, pullPolicy, pullSecrets)
+
+	if b.Instance.Spec.PodOverrides != nil {
+		if err := resources.ApplyPodTemplatePatch(pod, b.Instance.Spec.PodOverrides.PodTemplate); err != nil {
+			return err
 		}
 	}
 
@@ -209,61 +264,6 @@ func getVersionEnvFromVersionChannel(channel shulkermciov1alpha1.ProxyVersionCha
 	return ""
 }
 
-func (b *ProxyResourcePodBuilder) getInitEnv() ([]corev1.EnvVar, error) {
-	resourceRefResolver := resources.ResourceRefResolver{
-		Client:    b.Client,
-		Ctx:       b.Ctx,
-		Namespace: b.Instance.Namespace,
-	}
-
-	var pluginUrls []string
-	for _, ref := range b.Instance.Spec.Configuration.Plugins {
-		pluginUrl, err := resourceRefResolver.ResolveUrl(&ref)
-		if err != nil {
-			return []corev1.EnvVar{}, nil
-		}
-		pluginUrls = append(pluginUrls, pluginUrl)
-	}
-
-	var patchesUrls []string
-	for _, ref := range b.Instance.Spec.Configuration.Patches {
-		patchUrl, err := resourceRefResolver.ResolveUrl(&ref)
-		if err != nil {
-			return []corev1.EnvVar{}, nil
-		}
-		patchesUrls = append(patchesUrls, patchUrl)
-	}
-
-	env := []corev1.EnvVar{
-		{
-			Name:  "SHULKER_CONFIG_DIR",
-			Value: proxyShulkerConfigDir,
-		},
-		{
-			Name:  "PROXY_DATA_DIR",
-			Value: proxyDataDir,
-		},
-		{
-			Name:  "TYPE",
-			Value: getTypeFromVersionChannel(b.Instance.Spec.Version.Channel),
-		},
-		{
-			Name:  "SHULKER_PROXY_AGENT_VERSION",
-			Value: "0.0.1",
-		},
-		{
-			Name:  "PROXY_PLUGIN_URLS",
-			Value: strings.Join(pluginUrls, ";"),
-		},
-		{
-			Name:  "PROXY_PATCH_URLS",
-			Value: strings.Join(patchesUrls, ";"),
-		},
-	}
-
-	return env, nil
-}
-
 func (b *ProxyResourcePodBuilder) getEnv() []corev1.EnvVar {
 	env := []corev1.EnvVar{
 		{
@@ -295,6 +295,7 @@ func (b *ProxyResourcePodBuilder) getEnv() []corev1.EnvVar {
 			Value: b.Instance.Spec.Version.Name,
 		},
 	}
+	env = append(env, resources.GetJvmEnv(b.Instance.Spec.Jvm, b.getResources(), shulkermciov1alpha1.JvmFlagsVelocity)...)
 
 	if b.Instance.Spec.PodOverrides != nil {
 		env = append(env, b.Instance.Spec.PodOverrides.Env...)
@@ -303,6 +304,23 @@ func (b *ProxyResourcePodBuilder) getEnv() []corev1.EnvVar {
 	return env
 }
 
+// Unlike for servers, the version of the proxy does not tell which
+// version of Java it requires, the tag of the image is only changed
+// when asked.
+func (b *ProxyResourcePodBuilder) getImage() string {
+	if b.Instance.Spec.Jvm == nil || b.Instance.Spec.Jvm.JavaVersion == "" {
+		return b.Images.Proxy
+	}
+	return resources.WithImageTag(b.Images.Proxy, resources.GetJavaImageTag(b.Instance.Spec.Jvm.JavaVersion))
+}
+
+func (b *ProxyResourcePodBuilder) getResources() *corev1.ResourceRequirements {
+	if b.Instance.Spec.PodOverrides == nil {
+		return nil
+	}
+	return b.Instance.Spec.PodOverrides.Resources
+}
+
 func (b *ProxyResourcePodBuilder) getSecurityContext() *corev1.SecurityContext {
 	securityEscalation := false
 	readOnlyFs := true
@@ -4,73 +4,388 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/url"
+	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+	initfs "github.com/iamblueslime/shulker/libs/initfs/src"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// Directory where the credentials Secrets of the resources are
+// mounted in the init container, each one in a subdirectory named
+// after the Secret.
+const ResourceRefCredentialsDir = "/mnt/shulker/credentials"
+
+// Directories where the ConfigMaps and Secrets used as resources are
+// mounted in the init container, each one in a subdirectory named
+// after the object.
+const (
+	ResourceRefConfigMapsDir = "/mnt/shulker/resources/configmaps"
+	ResourceRefSecretsDir    = "/mnt/shulker/resources/secrets"
+)
+
+// ResourceRefResolutionError tells which ResourceRef of a spec could
+// not be resolved.
+type ResourceRefResolutionError struct {
+	// Path of the ResourceRef in the spec, e.g. spec.config.plugins[0]
+	Path string
+	Err  error
+}
+
+func (e *ResourceRefResolutionError) Error() string {
+	return fmt.Sprintf("failed to resolve %s: %v", e.Path, e.Err)
+}
+
+func (e *ResourceRefResolutionError) Unwrap() error {
+	return e.Err
+}
+
+// ResolvedResourceRef is a resource ready to be downloaded. The URL
+// never contains credentials, they are only given to the init
+// container through a mounted Secret.
+type ResolvedResourceRef struct {
+	// Path of the ResourceRef in the spec, e.g. spec.config.plugins[0]
+	Path string
+
+	Url string
+
+	// Concrete version of the resource, when its source has one.
+	Version string
+
+	// Name of the Secret containing the username and password to
+	// download the resource with, if any.
+	CredentialsSecretName string
+
+	// Expected hex-encoded checksums of the resource, if any.
+	Sha256 string
+	Sha512 string
+
+	// Whether the checksums files published next to the resource
+	// should be used to verify it.
+	VerifyPublishedChecksums bool
+
+	// ConfigMap or Secret containing the resource, in the Key key,
+	// when it is not downloaded.
+	ConfigMapName string
+	SecretName    string
+	Key           string
+
+	// Name of the file of the resource, when it cannot be guessed
+	// from its URL.
+	FileName string
+}
+
+// Returns how the resource was resolved, to be reported in status.
+func (r *ResolvedResourceRef) ToStatus() v1alpha1.ResolvedResourceRefStatus {
+	return v1alpha1.ResolvedResourceRefStatus{
+		Path:    r.Path,
+		Url:     r.Url,
+		Version: r.Version,
+		Sha256:  r.Sha256,
+		Sha512:  r.Sha512,
+	}
+}
+
+// Returns the status of a list of resources.
+func ToResolvedResourceRefStatuses(refs []ResolvedResourceRef) []v1alpha1.ResolvedResourceRefStatus {
+	statuses := make([]v1alpha1.ResolvedResourceRefStatus, 0, len(refs))
+	for i := range refs {
+		statuses = append(statuses, refs[i].ToStatus())
+	}
+	return statuses
+}
+
+// Returns the resource as locked by a deployment for its replicas.
+func (r *ResolvedResourceRef) ToLocked() v1alpha1.LockedResourceRef {
+	return v1alpha1.LockedResourceRef{
+		Path:                     r.Path,
+		Url:                      r.Url,
+		Version:                  r.Version,
+		CredentialsSecretName:    r.CredentialsSecretName,
+		Sha256:                   r.Sha256,
+		Sha512:                   r.Sha512,
+		VerifyPublishedChecksums: r.VerifyPublishedChecksums,
+		ConfigMapName:            r.ConfigMapName,
+		SecretName:               r.SecretName,
+		Key:                      r.Key,
+		FileName:                 r.FileName,
+	}
+}
+
+// Returns the locked form of a list of resources.
+func ToLockedResourceRefs(refs []ResolvedResourceRef) []v1alpha1.LockedResourceRef {
+	lockedRefs := make([]v1alpha1.LockedResourceRef, 0, len(refs))
+	for i := range refs {
+		lockedRefs = append(lockedRefs, refs[i].ToLocked())
+	}
+	return lockedRefs
+}
+
+// Returns the locked resources of the list at the given path of the
+// spec, e.g. spec.config.plugins, in their order. A path without
+// index, like spec.config.world, matches a single resource.
+func FromLockedResourceRefs(lockedRefs []v1alpha1.LockedResourceRef, path string) []ResolvedResourceRef {
+	resolvedRefs := []ResolvedResourceRef{}
+	for _, lockedRef := range lockedRefs {
+		if lockedRef.Path != path && !strings.HasPrefix(lockedRef.Path, path+"[") {
+			continue
+		}
+
+		resolvedRefs = append(resolvedRefs, ResolvedResourceRef{
+			Path:                     lockedRef.Path,
+			Url:                      lockedRef.Url,
+			Version:                  lockedRef.Version,
+			CredentialsSecretName:    lockedRef.CredentialsSecretName,
+			Sha256:                   lockedRef.Sha256,
+			Sha512:                   lockedRef.Sha512,
+			VerifyPublishedChecksums: lockedRef.VerifyPublishedChecksums,
+			ConfigMapName:            lockedRef.ConfigMapName,
+			SecretName:               lockedRef.SecretName,
+			Key:                      lockedRef.Key,
+			FileName:                 lockedRef.FileName,
+		})
+	}
+	return resolvedRefs
+}
+
+// Returns the entry of the init manifest setting up the resource.
+func (r *ResolvedResourceRef) ToManifestResource(name string, action initfs.ManifestResourceAction, destination string) initfs.ManifestResource {
+	resource := initfs.ManifestResource{
+		Name:                     name,
+		Url:                      r.Url,
+		Sha256:                   r.Sha256,
+		Sha512:                   r.Sha512,
+		VerifyPublishedChecksums: r.VerifyPublishedChecksums,
+		Action:                   action,
+		Destination:              destination,
+		FileName:                 r.FileName,
+	}
+
+	if r.CredentialsSecretName != "" {
+		resource.CredentialsDir = fmt.Sprintf("%s/%s", ResourceRefCredentialsDir, r.CredentialsSecretName)
+	}
+
+	if r.ConfigMapName != "" {
+		resource.Url = ""
+		resource.Path = fmt.Sprintf("%s/%s/%s", ResourceRefConfigMapsDir, r.ConfigMapName, r.Key)
+	} else if r.SecretName != "" {
+		resource.Url = ""
+		resource.Path = fmt.Sprintf("%s/%s/%s", ResourceRefSecretsDir, r.SecretName, r.Key)
+	}
+
+	return resource
+}
+
+// Returns the entries of the init manifest setting up a list of
+// resources, named after their path in the spec.
+func ToManifestResources(refs []ResolvedResourceRef, path string, action initfs.ManifestResourceAction, destination string) []initfs.ManifestResource {
+	resources := make([]initfs.ManifestResource, 0, len(refs))
+	for i := range refs {
+		resources = append(resources, refs[i].ToManifestResource(fmt.Sprintf("%s[%d]", path, i), action, destination))
+	}
+	return resources
+}
+
+// Returns the volumes exposing the credentials needed to download
+// the given resources, and the ConfigMaps and Secrets containing
+// them, and their mounts in the init container.
+func GetResourceRefVolumes(refs []ResolvedResourceRef) ([]corev1.Volume, []corev1.VolumeMount) {
+	credentialsSecretNames := map[string]bool{}
+	configMapKeys := map[string]map[string]bool{}
+	secretKeys := map[string]map[string]bool{}
+	for _, ref := range refs {
+		if ref.CredentialsSecretName != "" {
+			credentialsSecretNames[ref.CredentialsSecretName] = true
+		}
+		if ref.ConfigMapName != "" {
+			addResourceRefKey(configMapKeys, ref.ConfigMapName, ref.Key)
+		}
+		if ref.SecretName != "" {
+			addResourceRefKey(secretKeys, ref.SecretName, ref.Key)
+		}
+	}
+
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+
+	// Object names can be longer than volume names
+	for i, secretName := range sortedKeys(credentialsSecretNames) {
+		volumeName := fmt.Sprintf("resource-credentials-%d", i)
+
+		volumes = append(volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: secretName,
+					Items: []corev1.KeyToPath{
+						{Key: "username", Path: "username"},
+						{Key: "password", Path: "password"},
+					},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: fmt.Sprintf("%s/%s", ResourceRefCredentialsDir, secretName),
+			ReadOnly:  true,
+		})
+	}
+
+	for i, configMapName := range sortedKeys(configMapKeys) {
+		volumeName := fmt.Sprintf("resource-configmap-%d", i)
+
+		volumes = append(volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: configMapName},
+					Items:                getKeyToPaths(configMapKeys[configMapName]),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: fmt.Sprintf("%s/%s", ResourceRefConfigMapsDir, configMapName),
+			ReadOnly:  true,
+		})
+	}
+
+	for i, secretName := range sortedKeys(secretKeys) {
+		volumeName := fmt.Sprintf("resource-secret-%d", i)
+
+		volumes = append(volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: secretName,
+					Items:      getKeyToPaths(secretKeys[secretName]),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: fmt.Sprintf("%s/%s", ResourceRefSecretsDir, secretName),
+			ReadOnly:  true,
+		})
+	}
+
+	return volumes, volumeMounts
+}
+
+func addResourceRefKey(keys map[string]map[string]bool, name string, key string) {
+	if keys[name] == nil {
+		keys[name] = map[string]bool{}
+	}
+	keys[name][key] = true
+}
+
+// Only the keys used as resources are mounted.
+func getKeyToPaths(keys map[string]bool) []corev1.KeyToPath {
+	var items []corev1.KeyToPath
+	for _, key := range sortedKeys(keys) {
+		items = append(items, corev1.KeyToPath{Key: key, Path: key})
+	}
+	return items
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 type ResourceRefResolver struct {
 	client.Client
 	Ctx       context.Context
 	Namespace string
+
+	// Client to query the resource sources with, defaults to a
+	// client with a short timeout.
+	HTTPClient *http.Client
+
+	// Base URLs of the APIs of the resource sources, defaults to
+	// their public instances.
+	SourceAPIs *ResourceRefSourceAPIs
 }
 
-func (r *ResourceRefResolver) ResolveUrl(resourceRef *v1alpha1.ResourceRef) (string, error) {
-	if resourceRef == nil {
-		return "", errors.New("resourceRef is nil")
-	}
+// Resolves a list of resources, the path of the list in the spec is
+// used to report which one could not be resolved.
+func (r *ResourceRefResolver) ResolveAll(resourceRefs []v1alpha1.ResourceRef, path string) ([]ResolvedResourceRef, error) {
+	resolvedRefs := make([]ResolvedResourceRef, 0, len(resourceRefs))
 
-	if resourceRef.Url != "" {
-		return resourceRef.Url, nil
+	for i := range resourceRefs {
+		resolvedRef, err := r.ResolveAt(&resourceRefs[i], fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		resolvedRefs = append(resolvedRefs, *resolvedRef)
 	}
 
-	if resourceRef.UrlFrom != nil {
-		if resourceRef.UrlFrom.MavenRef != nil {
-			return r.resolveMavenRefUrl(resourceRef.UrlFrom.MavenRef)
-		}
+	return resolvedRefs, nil
+}
+
+// Resolves a resource, the path of the resource in the spec is used to
+// report it when it could not be resolved.
+func (r *ResourceRefResolver) ResolveAt(resourceRef *v1alpha1.ResourceRef, path string) (*ResolvedResourceRef, error) {
+	resolvedRef, err := r.Resolve(resourceRef)
+	if err != nil {
+		return nil, &ResourceRefResolutionError{Path: path, Err: err}
 	}
 
-	return "", errors.New("no resourceRef combination")
+	resolvedRef.Path = path
+	return resolvedRef, nil
 }
 
-func (r *ResourceRefResolver) resolveMavenRefUrl(mavenSelector *v1alpha1.ResourceRefMavenSelector) (string, error) {
-	mavenUrl, err := url.Parse(fmt.Sprintf(
-		"%[1]s/%[2]s/%[3]s/%[4]s/%[3]s-%[4]s.jar",
-		mavenSelector.Repository,
-		strings.ReplaceAll(mavenSelector.GroupId, ".", "/"),
-		mavenSelector.ArtifactId,
-		mavenSelector.Version,
-	))
+func (r *ResourceRefResolver) Resolve(resourceRef *v1alpha1.ResourceRef) (*ResolvedResourceRef, error) {
+	if resourceRef == nil {
+		return nil, errors.New("resourceRef is nil")
+	}
+
+	resolvedRef, err := r.resolveUrl(resourceRef)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if mavenSelector.CredentialsSecretName != "" {
-		secret := &corev1.Secret{}
-		err := r.Get(r.Ctx, types.NamespacedName{
-			Namespace: r.Namespace,
-			Name:      mavenSelector.CredentialsSecretName,
-		}, secret)
-		if err != nil {
-			return "", err
-		}
+	// The checksums given in the spec take precedence over the ones
+	// reported by the source
+	if resourceRef.Checksum != nil && (resourceRef.Checksum.Sha256 != "" || resourceRef.Checksum.Sha512 != "") {
+		resolvedRef.Sha256 = strings.ToLower(resourceRef.Checksum.Sha256)
+		resolvedRef.Sha512 = strings.ToLower(resourceRef.Checksum.Sha512)
+	}
 
-		username, ok := secret.Data["username"]
-		if !ok {
-			return "", errors.New("missing username in credential secret")
-		}
+	return resolvedRef, nil
+}
 
-		password, ok := secret.Data["password"]
-		if !ok {
-			return "", errors.New("missing password in credential secret")
-		}
+func (r *ResourceRefResolver) resolveUrl(resourceRef *v1alpha1.ResourceRef) (*ResolvedResourceRef, error) {
+	if resourceRef.Url != "" {
+		return &ResolvedResourceRef{Url: resourceRef.Url}, nil
+	}
 
-		mavenUrl.User = url.UserPassword(string(username), string(password))
+	if resourceRef.UrlFrom != nil {
+		if resourceRef.UrlFrom.MavenRef != nil {
+			return r.resolveMavenRef(resourceRef.UrlFrom.MavenRef)
+		} else if resourceRef.UrlFrom.ModrinthRef != nil {
+			return r.resolveModrinthRef(resourceRef.UrlFrom.ModrinthRef)
+		} else if resourceRef.UrlFrom.HangarRef != nil {
+			return r.resolveHangarRef(resourceRef.UrlFrom.HangarRef)
+		} else if resourceRef.UrlFrom.GitHubReleaseRef != nil {
+			return r.resolveGitHubReleaseRef(resourceRef.UrlFrom.GitHubReleaseRef)
+		} else if resourceRef.UrlFrom.CurseForgeRef != nil {
+			return r.resolveCurseForgeRef(resourceRef.UrlFrom.CurseForgeRef)
+		} else if resourceRef.UrlFrom.ConfigMapRef != nil {
+			return r.resolveConfigMapRef(resourceRef.UrlFrom.ConfigMapRef)
+		} else if resourceRef.UrlFrom.SecretRef != nil {
+			return r.resolveSecretRef(resourceRef.UrlFrom.SecretRef)
+		} else if resourceRef.UrlFrom.OCIRef != nil {
+			return r.resolveOCIRef(resourceRef.UrlFrom.OCIRef)
+		}
 	}
 
-	return mavenUrl.String(), nil
+	return nil, errors.New("no resourceRef combination")
 }
@@ -0,0 +1,35 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Locked resources", func() {
+	resolvedRefs := []ResolvedResourceRef{
+		{Path: "spec.config.world", Url: "https://example.com/world.tar.gz", Sha256: "aaaa"},
+		{Path: "spec.config.plugins[0]", Url: "https://example.com/plugin.jar", Version: "1.0", CredentialsSecretName: "credentials", VerifyPublishedChecksums: true},
+		{Path: "spec.config.plugins[1]", ConfigMapName: "patches", Key: "plugin.jar"},
+		{Path: "spec.config.patches[0]", Url: "https://example.com/patch.jar", FileName: "patch.jar"},
+	}
+
+	It("gives back the resources as they were resolved", func() {
+		lockedRefs := ToLockedResourceRefs(resolvedRefs)
+
+		Expect(FromLockedResourceRefs(lockedRefs, "spec.config.world")).To(Equal(resolvedRefs[0:1]))
+		Expect(FromLockedResourceRefs(lockedRefs, "spec.config.plugins")).To(Equal(resolvedRefs[1:3]))
+		Expect(FromLockedResourceRefs(lockedRefs, "spec.config.patches")).To(Equal(resolvedRefs[3:4]))
+	})
+
+	It("gives back no resource for unknown paths", func() {
+		lockedRefs := ToLockedResourceRefs(resolvedRefs)
+
+		Expect(FromLockedResourceRefs(lockedRefs, "spec.config.mods")).To(BeEmpty())
+		Expect(FromLockedResourceRefs(lockedRefs, "spec.config.plugin")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,131 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const resourceSourceMaxResponseSize = 4 << 20
+
+// Client used by the operator to query the resource sources, e.g. to
+// find the latest version of a Maven artifact.
+var defaultResourceRefHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// Base URLs of the APIs of the resource sources.
+type ResourceRefSourceAPIs struct {
+	Modrinth   string
+	Hangar     string
+	GitHub     string
+	CurseForge string
+}
+
+var DefaultResourceRefSourceAPIs = ResourceRefSourceAPIs{
+	Modrinth:   "https://api.modrinth.com",
+	Hangar:     "https://hangar.papermc.io",
+	GitHub:     "https://api.github.com",
+	CurseForge: "https://api.curseforge.com",
+}
+
+type sourceCredentials struct {
+	username string
+	password string
+}
+
+func (r *ResourceRefResolver) getSourceAPIs() *ResourceRefSourceAPIs {
+	if r.SourceAPIs != nil {
+		return r.SourceAPIs
+	}
+	return &DefaultResourceRefSourceAPIs
+}
+
+func (r *ResourceRefResolver) getHTTPClient() *http.Client {
+	if r.HTTPClient != nil {
+		return r.HTTPClient
+	}
+	return defaultResourceRefHTTPClient
+}
+
+// The credentials are mainly used by the init container, checking them
+// beforehand avoids creating a Pod which cannot start.
+func (r *ResourceRefResolver) getCredentials(secretName string) (*sourceCredentials, error) {
+	username, err := r.getSecretKey(secretName, "username")
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := r.getSecretKey(secretName, "password")
+	if err != nil {
+		return nil, err
+	}
+
+	return &sourceCredentials{username: username, password: password}, nil
+}
+
+func (r *ResourceRefResolver) getSecretKey(secretName string, key string) (string, error) {
+	secret := &corev1.Secret{}
+	err := r.Get(r.Ctx, types.NamespacedName{
+		Namespace: r.Namespace,
+		Name:      secretName,
+	}, secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to get credentials Secret %s: %v", secretName, err)
+	}
+
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("missing %s in credentials Secret %s", key, secretName)
+	}
+
+	return string(value), nil
+}
+
+// Queries a resource source, failing on unsuccessful responses.
+func (r *ResourceRefResolver) getSource(url string, credentials *sourceCredentials, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(r.Ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("User-Agent", "iamblueslime/shulker")
+	if credentials != nil {
+		req.SetBasicAuth(credentials.username, credentials.password)
+	}
+
+	res, err := r.getHTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, resourceSourceMaxResponseSize))
+}
+
+func (r *ResourceRefResolver) getSourceJSON(url string, credentials *sourceCredentials, header http.Header, value interface{}) error {
+	content, err := r.getSource(url, credentials, header)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %v", url, err)
+	}
+
+	if err := json.Unmarshal(content, value); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", url, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// HTTP server standing in for the API of a resource source, serving
+// fixed JSON responses per path and recording the requests it gets.
+type apiStandIn struct {
+	server    *httptest.Server
+	mutex     sync.Mutex
+	responses map[string]string
+	requests  []*http.Request
+}
+
+func newAPIStandIn() *apiStandIn {
+	standIn := &apiStandIn{responses: map[string]string{}}
+
+	standIn.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		standIn.mutex.Lock()
+		defer standIn.mutex.Unlock()
+
+		standIn.requests = append(standIn.requests, r)
+		response, ok := standIn.responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+
+	return standIn
+}
+
+func (s *apiStandIn) getLastRequest() *http.Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.requests) == 0 {
+		return nil
+	}
+	return s.requests[len(s.requests)-1]
+}
+
+// Returns a resolver using the stand-in for every source.
+func (s *apiStandIn) newResolver(objects ...client.Object) *ResourceRefResolver {
+	clientBuilder := fake.NewClientBuilder()
+	for _, object := range objects {
+		clientBuilder = clientBuilder.WithObjects(object)
+	}
+
+	return &ResourceRefResolver{
+		Client:     clientBuilder.Build(),
+		Ctx:        context.Background(),
+		Namespace:  "default",
+		HTTPClient: s.server.Client(),
+		SourceAPIs: &ResourceRefSourceAPIs{
+			Modrinth:   s.server.URL,
+			Hangar:     s.server.URL,
+			GitHub:     s.server.URL,
+			CurseForge: s.server.URL,
+		},
+	}
+}
+
+func newTestSecret(name string, data map[string]string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Data:       map[string][]byte{},
+	}
+	for key, value := range data {
+		secret.Data[key] = []byte(value)
+	}
+	return secret
+}
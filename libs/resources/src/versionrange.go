@@ -0,0 +1,109 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Returns whether a version selector is a range rather than an exact
+// version.
+func isVersionRange(selector string) bool {
+	return strings.ContainsAny(selector, "<>=")
+}
+
+// Returns whether a version matches a selector. The selector is either
+// an exact version or space-separated comparisons which must all be
+// satisfied, e.g. ">=1.2.0 <2.0.0". An empty selector matches every
+// version.
+func matchVersion(version string, selector string) (bool, error) {
+	if selector == "" {
+		return true, nil
+	}
+	if !isVersionRange(selector) {
+		return version == selector, nil
+	}
+
+	for _, comparison := range strings.Fields(selector) {
+		operator := strings.TrimRight(comparison, "0123456789.-+_abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+		bound := strings.TrimPrefix(comparison, operator)
+		if bound == "" {
+			return false, fmt.Errorf("invalid version range %q", selector)
+		}
+
+		result := compareVersions(version, bound)
+		var matches bool
+		switch operator {
+		case "=", "==":
+			matches = result == 0
+		case ">":
+			matches = result > 0
+		case ">=":
+			matches = result >= 0
+		case "<":
+			matches = result < 0
+		case "<=":
+			matches = result <= 0
+		default:
+			return false, fmt.Errorf("invalid operator %q in version range %q", operator, selector)
+		}
+
+		if !matches {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Compares two versions made of dot-separated numbers, optionally
+// followed by a pre-release after a dash. Pre-releases are lower than
+// their release.
+func compareVersions(a string, b string) int {
+	aCore, aPreRelease, aHasPreRelease := strings.Cut(a, "-")
+	bCore, bPreRelease, bHasPreRelease := strings.Cut(b, "-")
+
+	aParts := strings.Split(aCore, ".")
+	bParts := strings.Split(bCore, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aPart := "0"
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		bPart := "0"
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+
+		if result := compareVersionParts(aPart, bPart); result != 0 {
+			return result
+		}
+	}
+
+	if aHasPreRelease && !bHasPreRelease {
+		return -1
+	} else if !aHasPreRelease && bHasPreRelease {
+		return 1
+	}
+	return strings.Compare(aPreRelease, bPreRelease)
+}
+
+func compareVersionParts(a string, b string) int {
+	aNumber, aErr := strconv.Atoi(a)
+	bNumber, bErr := strconv.Atoi(b)
+	if aErr != nil || bErr != nil {
+		return strings.Compare(a, b)
+	}
+
+	if aNumber < bNumber {
+		return -1
+	} else if aNumber > bNumber {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,35 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("matchVersion", func() {
+	DescribeTable("matches versions against selectors",
+		func(version string, selector string, expected bool) {
+			matches, err := matchVersion(version, selector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches).To(Equal(expected))
+		},
+		Entry("empty selector", "1.0.0", "", true),
+		Entry("exact version", "1.0.0", "1.0.0", true),
+		Entry("other exact version", "1.0.1", "1.0.0", false),
+		Entry("inside range", "1.10.0", ">=1.2.0 <2.0.0", true),
+		Entry("above range", "2.0.0", ">=1.2.0 <2.0.0", false),
+		Entry("below range", "1.1.9", ">=1.2.0 <2.0.0", false),
+		Entry("shorter version", "1.2", ">=1.2.0", true),
+		Entry("pre-release below its release", "2.0.0-beta.1", "<2.0.0", true),
+		Entry("equal operator", "1.0.0", "=1.0.0", true),
+	)
+
+	It("fails on invalid operators", func() {
+		_, err := matchVersion("1.0.0", "~>1.0.0")
+		Expect(err).To(HaveOccurred())
+	})
+})
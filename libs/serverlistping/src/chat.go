@@ -0,0 +1,82 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package serverlistping
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Legacy formatting codes start with the section sign followed by a
+// single character.
+const legacyFormattingPrefix = '§'
+
+type chatComponent struct {
+	Text      string            `json:"text"`
+	Translate string            `json:"translate"`
+	Extra     []json.RawMessage `json:"extra"`
+}
+
+// ChatToPlainText returns the text of a chat component, which can be
+// a JSON string, object or array, without its formatting.
+func ChatToPlainText(raw json.RawMessage) string {
+	builder := strings.Builder{}
+	appendChatText(&builder, raw)
+	return stripLegacyFormatting(builder.String())
+}
+
+func appendChatText(builder *strings.Builder, raw json.RawMessage) {
+	if len(raw) == 0 {
+		return
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		builder.WriteString(text)
+		return
+	}
+
+	var components []json.RawMessage
+	if err := json.Unmarshal(raw, &components); err == nil {
+		for _, component := range components {
+			appendChatText(builder, component)
+		}
+		return
+	}
+
+	component := chatComponent{}
+	if err := json.Unmarshal(raw, &component); err != nil {
+		return
+	}
+
+	if component.Text != "" {
+		builder.WriteString(component.Text)
+	} else {
+		builder.WriteString(component.Translate)
+	}
+	for _, extra := range component.Extra {
+		appendChatText(builder, extra)
+	}
+}
+
+func stripLegacyFormatting(text string) string {
+	builder := strings.Builder{}
+
+	skipNext := false
+	for _, r := range text {
+		if skipNext {
+			skipNext = false
+			continue
+		}
+		if r == legacyFormattingPrefix {
+			skipNext = true
+			continue
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,26 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package serverlistping
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Chat", func() {
+	DescribeTable("converts chat components to plain text",
+		func(raw string, expected string) {
+			Expect(ChatToPlainText(json.RawMessage(raw))).To(Equal(expected))
+		},
+		Entry("string", `"§6A Minecraft §lServer"`, "A Minecraft Server"),
+		Entry("object", `{"text": "Hello", "extra": ["! ", {"text": "World", "color": "red"}]}`, "Hello! World"),
+		Entry("array", `[{"text": "Hello "}, "World"]`, "Hello World"),
+		Entry("translation", `{"translate": "multiplayer.status.unknown"}`, "multiplayer.status.unknown"),
+		Entry("missing", ``, ""),
+	)
+})
@@ -0,0 +1,238 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package serverlistping
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	packetIdHandshake int32 = 0x00
+	packetIdStatus    int32 = 0x00
+	packetIdPing      int32 = 0x01
+
+	handshakeNextStateStatus int32 = 1
+
+	// Protocol version sent in the handshake, -1 asks the server to
+	// report its own.
+	handshakeProtocolVersion int32 = -1
+
+	// The status response is a JSON document which can embed a
+	// favicon, so it may be large but not unbounded.
+	maxPacketBytes = 2 * 1024 * 1024
+)
+
+// Status reported by a Minecraft server to the clients listing it.
+type Status struct {
+	// Name of the version run by the server, e.g. "Paper 1.20.4".
+	VersionName string
+
+	// Number of the protocol spoken by the server.
+	ProtocolVersion int32
+
+	OnlinePlayers int32
+	MaxPlayers    int32
+
+	// Message of the day, as plain text without formatting.
+	Motd string
+
+	// Round-trip time of the ping packet.
+	Latency time.Duration
+}
+
+type statusResponse struct {
+	Version struct {
+		Name     string `json:"name"`
+		Protocol int32  `json:"protocol"`
+	} `json:"version"`
+	Players struct {
+		Max    int32 `json:"max"`
+		Online int32 `json:"online"`
+	} `json:"players"`
+	Description json.RawMessage `json:"description"`
+}
+
+// Ping asks the server listening at the given address for its
+// status using the Server List Ping protocol of the Java Edition.
+// The context bounds the whole exchange.
+func Ping(ctx context.Context, address string) (*Status, error) {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %s: %w", portString, err)
+	}
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	handshake := bytes.Buffer{}
+	writeVarInt(&handshake, handshakeProtocolVersion)
+	writeString(&handshake, host)
+	binary.Write(&handshake, binary.BigEndian, uint16(port))
+	writeVarInt(&handshake, handshakeNextStateStatus)
+	if err := writePacket(conn, packetIdHandshake, handshake.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := writePacket(conn, packetIdStatus, nil); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	packetId, payload, err := readPacket(reader)
+	if err != nil {
+		return nil, err
+	}
+	if packetId != packetIdStatus {
+		return nil, fmt.Errorf("unexpected packet 0x%02x instead of status response", packetId)
+	}
+
+	payloadReader := bytes.NewReader(payload)
+	rawResponse, err := readString(payloadReader)
+	if err != nil {
+		return nil, err
+	}
+
+	response := statusResponse{}
+	if err := json.Unmarshal([]byte(rawResponse), &response); err != nil {
+		return nil, fmt.Errorf("invalid status response: %w", err)
+	}
+
+	status := &Status{
+		VersionName:     response.Version.Name,
+		ProtocolVersion: response.Version.Protocol,
+		OnlinePlayers:   response.Players.Online,
+		MaxPlayers:      response.Players.Max,
+		Motd:            ChatToPlainText(response.Description),
+	}
+
+	// The server answers the ping with the same payload, the time it
+	// takes is the latency
+	sentAt := time.Now()
+	pingPayload := bytes.Buffer{}
+	binary.Write(&pingPayload, binary.BigEndian, sentAt.UnixMilli())
+	if err := writePacket(conn, packetIdPing, pingPayload.Bytes()); err != nil {
+		return nil, err
+	}
+
+	packetId, payload, err = readPacket(reader)
+	if err != nil {
+		return nil, err
+	}
+	if packetId != packetIdPing || !bytes.Equal(payload, pingPayload.Bytes()) {
+		return nil, errors.New("invalid pong response")
+	}
+	status.Latency = time.Since(sentAt)
+
+	return status, nil
+}
+
+func writePacket(writer io.Writer, packetId int32, payload []byte) error {
+	body := bytes.Buffer{}
+	writeVarInt(&body, packetId)
+	body.Write(payload)
+
+	packet := bytes.Buffer{}
+	writeVarInt(&packet, int32(body.Len()))
+	packet.Write(body.Bytes())
+
+	_, err := writer.Write(packet.Bytes())
+	return err
+}
+
+func readPacket(reader io.ByteReader) (int32, []byte, error) {
+	length, err := readVarInt(reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	if length <= 0 || length > maxPacketBytes {
+		return 0, nil, fmt.Errorf("invalid packet length: %d", length)
+	}
+
+	body := make([]byte, length)
+	for i := range body {
+		if body[i], err = reader.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	bodyReader := bytes.NewReader(body)
+	packetId, err := readVarInt(bodyReader)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return packetId, body[len(body)-bodyReader.Len():], nil
+}
+
+func writeVarInt(buffer *bytes.Buffer, value int32) {
+	unsigned := uint32(value)
+	for {
+		if unsigned&^0x7f == 0 {
+			buffer.WriteByte(byte(unsigned))
+			return
+		}
+		buffer.WriteByte(byte(unsigned&0x7f | 0x80))
+		unsigned >>= 7
+	}
+}
+
+func readVarInt(reader io.ByteReader) (int32, error) {
+	var value uint32
+	for i := 0; i < 5; i++ {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		value |= uint32(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(value), nil
+		}
+	}
+	return 0, errors.New("varint is too long")
+}
+
+func writeString(buffer *bytes.Buffer, value string) {
+	writeVarInt(buffer, int32(len(value)))
+	buffer.WriteString(value)
+}
+
+func readString(reader *bytes.Reader) (string, error) {
+	length, err := readVarInt(reader)
+	if err != nil {
+		return "", err
+	}
+	if length < 0 || int(length) > reader.Len() {
+		return "", fmt.Errorf("invalid string length: %d", length)
+	}
+
+	value := make([]byte, length)
+	if _, err := io.ReadFull(reader, value); err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
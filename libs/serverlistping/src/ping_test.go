@@ -0,0 +1,117 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package serverlistping
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Answers a single Server List Ping with the given status response.
+func serveStatus(listener net.Listener, response string) {
+	defer GinkgoRecover()
+
+	conn, err := listener.Accept()
+	Expect(err).NotTo(HaveOccurred())
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	packetId, payload, err := readPacket(reader)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(packetId).To(Equal(packetIdHandshake))
+	payloadReader := bytes.NewReader(payload)
+	protocolVersion, _ := readVarInt(payloadReader)
+	Expect(protocolVersion).To(Equal(handshakeProtocolVersion))
+	host, _ := readString(payloadReader)
+	Expect(host).To(Equal("127.0.0.1"))
+	var port uint16
+	binary.Read(payloadReader, binary.BigEndian, &port)
+	nextState, _ := readVarInt(payloadReader)
+	Expect(nextState).To(Equal(handshakeNextStateStatus))
+
+	packetId, _, err = readPacket(reader)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(packetId).To(Equal(packetIdStatus))
+
+	statusPayload := bytes.Buffer{}
+	writeString(&statusPayload, response)
+	Expect(writePacket(conn, packetIdStatus, statusPayload.Bytes())).To(Succeed())
+
+	packetId, payload, err = readPacket(reader)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(packetId).To(Equal(packetIdPing))
+	Expect(writePacket(conn, packetIdPing, payload)).To(Succeed())
+}
+
+var _ = Describe("Ping", func() {
+	var listener net.Listener
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(listener.Close)
+	})
+
+	It("reads the status of the server", func() {
+		go serveStatus(listener, `{
+			"version": {"name": "Paper 1.20.4", "protocol": 765},
+			"players": {"max": 100, "online": 7, "sample": []},
+			"description": {"text": "§aWelcome ", "extra": [{"text": "to Shulker", "bold": true}]}
+		}`)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		status, err := Ping(ctx, listener.Addr().String())
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.VersionName).To(Equal("Paper 1.20.4"))
+		Expect(status.ProtocolVersion).To(Equal(int32(765)))
+		Expect(status.OnlinePlayers).To(Equal(int32(7)))
+		Expect(status.MaxPlayers).To(Equal(int32(100)))
+		Expect(status.Motd).To(Equal("Welcome to Shulker"))
+		Expect(status.Latency).To(BeNumerically(">", 0))
+	})
+
+	It("fails when the server does not answer in time", func() {
+		go func() {
+			conn, err := listener.Accept()
+			if err == nil {
+				defer conn.Close()
+				time.Sleep(time.Second)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_, err := Ping(ctx, listener.Addr().String())
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("encodes varints",
+		func(value int32, encoded []byte) {
+			buffer := bytes.Buffer{}
+			writeVarInt(&buffer, value)
+			Expect(buffer.Bytes()).To(Equal(encoded))
+
+			decoded, err := readVarInt(bytes.NewReader(encoded))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded).To(Equal(value))
+		},
+		Entry("zero", int32(0), []byte{0x00}),
+		Entry("one byte", int32(127), []byte{0x7f}),
+		Entry("two bytes", int32(300), []byte{0xac, 0x02}),
+		Entry("negative", int32(-1), []byte{0xff, 0xff, 0xff, 0xff, 0x0f}),
+	)
+})
@@ -0,0 +1,57 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package serverlistping
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// ProbeHandler answers the HTTP probes of the kubelet by pinging the
+// server at the given address. GET /probe succeeds when the server
+// answers the ping in time. GET /ready also fails while the drain
+// lock file exists, if any, for the server to stop receiving players
+// without being restarted. GET /healthz only tells the handler itself
+// is running.
+func ProbeHandler(address string, timeout time.Duration, drainLockFile string, logger logr.Logger) http.Handler {
+	probe := func(w http.ResponseWriter, r *http.Request) {
+		// The kubelet closes the connection when its own timeout is
+		// shorter, which cancels the ping
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		status, err := Ping(ctx, address)
+		if err != nil {
+			logger.Info("Server did not answer the ping", "address", address, "error", err.Error())
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "%d/%d players, %s\n", status.OnlinePlayers, status.MaxPlayers, status.VersionName)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/probe", probe)
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		if drainLockFile != "" {
+			if _, err := os.Stat(drainLockFile); err == nil {
+				http.Error(w, "drain lock found", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		probe(w, r)
+	})
+	return mux
+}
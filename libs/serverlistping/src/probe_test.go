@@ -0,0 +1,63 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package serverlistping
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Probe", func() {
+	var listener net.Listener
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() {
+			listener.Close()
+		})
+	})
+
+	It("succeeds when the server answers", func() {
+		go serveStatus(listener, `{"version": {"name": "Velocity 3.2.0", "protocol": 763}, "players": {"max": 500, "online": 3}, "description": ""}`)
+
+		recorder := httptest.NewRecorder()
+		ProbeHandler(listener.Addr().String(), 5*time.Second, "", logr.Discard()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/probe", nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(Equal("3/500 players, Velocity 3.2.0\n"))
+	})
+
+	It("fails when the server is down", func() {
+		address := listener.Addr().String()
+		listener.Close()
+
+		recorder := httptest.NewRecorder()
+		ProbeHandler(address, 5*time.Second, "", logr.Discard()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/probe", nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("is not ready while the drain lock exists", func() {
+		drainLockFile := filepath.Join(GinkgoT().TempDir(), "drain-lock")
+		Expect(os.WriteFile(drainLockFile, nil, 0644)).To(Succeed())
+
+		recorder := httptest.NewRecorder()
+		ProbeHandler(listener.Addr().String(), 5*time.Second, drainLockFile, logr.Discard()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(recorder.Body.String()).To(ContainSubstring("drain lock found"))
+	})
+})
@@ -0,0 +1,57 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package sidecar
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Tells whether a process with the given name is running. The
+// processes of the other containers of the Pod are only visible when
+// the Pod shares its process namespace.
+func IsProcessRunning(procDir string, name string) bool {
+	commFiles, err := filepath.Glob(filepath.Join(procDir, "[0-9]*", "comm"))
+	if err != nil {
+		return false
+	}
+
+	for _, commFile := range commFiles {
+		comm, err := os.ReadFile(commFile)
+		if err == nil && strings.TrimSpace(string(comm)) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Waits for a process with the given name to start and then to stop,
+// for sidecars to end along with the main container of their Pod.
+// Returns false when the context is done before.
+func WaitForProcessEnd(ctx context.Context, procDir string, name string, pollInterval time.Duration) bool {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	started := false
+	for {
+		running := IsProcessRunning(procDir, name)
+		if running {
+			started = true
+		} else if started {
+			return true
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
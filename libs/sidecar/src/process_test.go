@@ -0,0 +1,62 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package sidecar
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Process", func() {
+	var procDir string
+
+	startProcess := func(pid string, name string) {
+		Expect(os.MkdirAll(filepath.Join(procDir, pid), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(procDir, pid, "comm"), []byte(name+"\n"), 0o644)).To(Succeed())
+	}
+
+	stopProcess := func(pid string) {
+		Expect(os.RemoveAll(filepath.Join(procDir, pid))).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		procDir = GinkgoT().TempDir()
+	})
+
+	It("finds the running processes by name", func() {
+		startProcess("1", "pause")
+		startProcess("42", "java")
+
+		Expect(IsProcessRunning(procDir, "java")).To(BeTrue())
+		Expect(IsProcessRunning(procDir, "bash")).To(BeFalse())
+	})
+
+	It("waits for the process to start and then to stop", func() {
+		ended := make(chan bool, 1)
+		go func() {
+			ended <- WaitForProcessEnd(context.Background(), procDir, "java", 10*time.Millisecond)
+		}()
+
+		Consistently(ended, 50*time.Millisecond).ShouldNot(Receive())
+		startProcess("42", "java")
+		Consistently(ended, 50*time.Millisecond).ShouldNot(Receive())
+		stopProcess("42")
+		Eventually(ended).Should(Receive(BeTrue()))
+	})
+
+	It("stops waiting when the context is done", func() {
+		startProcess("42", "java")
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		Expect(WaitForProcessEnd(ctx, procDir, "java", 10*time.Millisecond)).To(BeFalse())
+	})
+})
@@ -0,0 +1,188 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+const defaultProxyMaxPlayers = 100
+const defaultProxyMotd = "A Minecraft Cluster on Shulker"
+const defaultProxyTimeToLiveSeconds = 86400
+
+func defaultMinecraftServerSpec(ctx context.Context, reader client.Reader, namespace string, clusterName string, spec *shulkermciov1alpha1.MinecraftServerSpec) error {
+	if spec.Version.Channel == "" {
+		spec.Version.Channel = shulkermciov1alpha1.MinecraftServerVersionPaper
+	}
+
+	if spec.Configuration.MaxPlayers == nil {
+		maxPlayers := shulkermciov1alpha1.DefaultMinecraftServerMaxPlayers
+		spec.Configuration.MaxPlayers = &maxPlayers
+	}
+
+	if spec.Persistence != nil {
+		if spec.Persistence.RetentionPolicy.WhenDeleted == "" {
+			spec.Persistence.RetentionPolicy.WhenDeleted = shulkermciov1alpha1.RetainPersistentVolumeClaimRetentionPolicyType
+		}
+		if spec.Persistence.RetentionPolicy.WhenScaled == "" {
+			spec.Persistence.RetentionPolicy.WhenScaled = shulkermciov1alpha1.RetainPersistentVolumeClaimRetentionPolicyType
+		}
+	}
+
+	if spec.Backup != nil && spec.Backup.Bucket.Region == "" {
+		spec.Backup.Bucket.Region = shulkermciov1alpha1.DefaultMinecraftServerBackupBucketRegion
+	}
+
+	defaultJvmSpec(spec.Jvm)
+	defaultProbesSpec(spec.Probes)
+	if spec.Configuration.World != nil {
+		defaultResourceRef(spec.Configuration.World)
+	}
+	defaultResourceRefs(spec.Configuration.Plugins)
+	defaultResourceRefs(spec.Configuration.Mods)
+	defaultResourceRefs(spec.Configuration.Patches)
+
+	if spec.Configuration.ProxyForwardingMode == "" {
+		forwardingMode, err := getForwardingModeInCluster(ctx, reader, namespace, clusterName)
+		if err != nil {
+			return err
+		}
+		spec.Configuration.ProxyForwardingMode = forwardingMode
+	}
+
+	return nil
+}
+
+func defaultProxySpec(spec *shulkermciov1alpha1.ProxySpec) {
+	if spec.Version.Channel == "" {
+		spec.Version.Channel = shulkermciov1alpha1.ProxyVersionVelocity
+	}
+
+	if spec.Configuration.MaxPlayers == 0 {
+		spec.Configuration.MaxPlayers = defaultProxyMaxPlayers
+	}
+
+	if spec.Configuration.Motd == "" {
+		spec.Configuration.Motd = defaultProxyMotd
+	}
+
+	if spec.Configuration.TimeToLiveSeconds == 0 {
+		spec.Configuration.TimeToLiveSeconds = defaultProxyTimeToLiveSeconds
+	}
+
+	defaultJvmSpec(spec.Jvm)
+	defaultProbesSpec(spec.Probes)
+	defaultResourceRefs(spec.Configuration.Plugins)
+	defaultResourceRefs(spec.Configuration.Patches)
+}
+
+func defaultMinecraftClusterSpec(spec *shulkermciov1alpha1.MinecraftClusterSpec) {
+	if spec.Cache != nil && spec.Cache.Resources == nil {
+		spec.Cache.Resources = &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(shulkermciov1alpha1.DefaultMinecraftClusterCacheCpuRequest),
+				corev1.ResourceMemory: resource.MustParse(shulkermciov1alpha1.DefaultMinecraftClusterCacheMemoryRequest),
+			},
+		}
+	}
+}
+
+func defaultJvmSpec(jvm *shulkermciov1alpha1.JvmSpec) {
+	if jvm == nil {
+		return
+	}
+
+	if jvm.HeapPolicy == "" {
+		jvm.HeapPolicy = shulkermciov1alpha1.JvmHeapPercentage
+	}
+
+	if jvm.HeapPercentage == 0 {
+		jvm.HeapPercentage = shulkermciov1alpha1.DefaultJvmHeapPercentage
+	}
+}
+
+func defaultProbesSpec(probes *shulkermciov1alpha1.ProbesSpec) {
+	if probes != nil && probes.Mode == "" {
+		probes.Mode = shulkermciov1alpha1.ProbeModeExec
+	}
+}
+
+func defaultResourceRefs(resourceRefs []shulkermciov1alpha1.ResourceRef) {
+	for i := range resourceRefs {
+		defaultResourceRef(&resourceRefs[i])
+	}
+}
+
+func defaultResourceRef(resourceRef *shulkermciov1alpha1.ResourceRef) {
+	if resourceRef.UrlFrom != nil && resourceRef.UrlFrom.MavenRef != nil && resourceRef.UrlFrom.MavenRef.Extension == "" {
+		resourceRef.UrlFrom.MavenRef.Extension = shulkermciov1alpha1.DefaultResourceRefMavenExtension
+	}
+}
+
+func defaultDeploymentStrategy(strategy *shulkermciov1alpha1.DeploymentStrategy) {
+	if strategy.Type == "" {
+		strategy.Type = shulkermciov1alpha1.DeploymentStrategyRollingUpdate
+	}
+
+	if strategy.Type != shulkermciov1alpha1.DeploymentStrategyRollingUpdate {
+		return
+	}
+
+	if strategy.RollingUpdate == nil {
+		strategy.RollingUpdate = &shulkermciov1alpha1.RollingUpdateDeploymentStrategy{}
+	}
+	if strategy.RollingUpdate.MaxSurge == nil {
+		maxSurge := shulkermciov1alpha1.DefaultRollingUpdateMaxSurge
+		strategy.RollingUpdate.MaxSurge = &maxSurge
+	}
+	if strategy.RollingUpdate.MaxUnavailable == nil {
+		maxUnavailable := shulkermciov1alpha1.DefaultRollingUpdateMaxUnavailable
+		strategy.RollingUpdate.MaxUnavailable = &maxUnavailable
+	}
+}
+
+func defaultProxyDeploymentServiceSpec(spec *shulkermciov1alpha1.ProxyDeploymentServiceSpec) {
+	if spec.Type == "" {
+		spec.Type = corev1.ServiceTypeLoadBalancer
+	}
+
+	if spec.ExternalTrafficPolicy == "" {
+		spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+	}
+}
+
+// Computes the forwarding mode the MinecraftServers of a cluster
+// should use from the channel of its proxies, falling back to
+// Velocity when the cluster has no proxy yet.
+func getForwardingModeInCluster(ctx context.Context, reader client.Reader, namespace string, clusterName string) (shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingMode, error) {
+	proxyDeploymentList := &shulkermciov1alpha1.ProxyDeploymentList{}
+	if err := reader.List(ctx, proxyDeploymentList, client.InNamespace(namespace)); err != nil {
+		return "", err
+	}
+	for _, proxyDeployment := range proxyDeploymentList.Items {
+		if proxyDeployment.Spec.ClusterRef.Name == clusterName && proxyDeployment.Spec.Template.Spec.Version.Channel != "" {
+			return getForwardingModeForProxyChannel(proxyDeployment.Spec.Template.Spec.Version.Channel), nil
+		}
+	}
+
+	proxyList := &shulkermciov1alpha1.ProxyList{}
+	if err := reader.List(ctx, proxyList, client.InNamespace(namespace)); err != nil {
+		return "", err
+	}
+	for _, proxy := range proxyList.Items {
+		if proxy.Spec.ClusterRef.Name == clusterName && proxy.Spec.Version.Channel != "" {
+			return getForwardingModeForProxyChannel(proxy.Spec.Version.Channel), nil
+		}
+	}
+
+	return shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity, nil
+}
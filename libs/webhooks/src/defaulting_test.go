@@ -0,0 +1,240 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("Defaulting", func() {
+	mavenRef := func() shulkermciov1alpha1.ResourceRef {
+		return shulkermciov1alpha1.ResourceRef{
+			UrlFrom: &shulkermciov1alpha1.ResourceRefSource{
+				MavenRef: &shulkermciov1alpha1.ResourceRefMavenSelector{
+					Repository: "https://maven.example.com",
+					GroupId:    "com.example",
+					ArtifactId: "plugin",
+					Version:    "1.0.0",
+				},
+			},
+		}
+	}
+
+	It("defaults the spec of a MinecraftServer", func() {
+		minecraftServer := &shulkermciov1alpha1.MinecraftServer{
+			ObjectMeta: testObjectMeta("server"),
+			MinecraftServerTemplate: shulkermciov1alpha1.MinecraftServerTemplate{
+				Spec: shulkermciov1alpha1.MinecraftServerSpec{
+					ClusterRef: testClusterRef(),
+					Configuration: shulkermciov1alpha1.MinecraftServerConfigurationSpec{
+						World:   &shulkermciov1alpha1.ResourceRef{Url: "https://example.com/world.tar.gz"},
+						Plugins: []shulkermciov1alpha1.ResourceRef{mavenRef()},
+						Mods:    []shulkermciov1alpha1.ResourceRef{mavenRef()},
+						Patches: []shulkermciov1alpha1.ResourceRef{mavenRef()},
+					},
+					Jvm:    &shulkermciov1alpha1.JvmSpec{},
+					Probes: &shulkermciov1alpha1.ProbesSpec{},
+				},
+			},
+		}
+
+		webhook := &MinecraftServerWebhook{Reader: newFakeReader()}
+		Expect(webhook.Default(context.Background(), minecraftServer)).To(Succeed())
+
+		spec := minecraftServer.Spec
+		Expect(spec.Version.Channel).To(Equal(shulkermciov1alpha1.MinecraftServerVersionPaper))
+		Expect(*spec.Configuration.MaxPlayers).To(Equal(shulkermciov1alpha1.DefaultMinecraftServerMaxPlayers))
+		Expect(spec.Configuration.ProxyForwardingMode).To(Equal(shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity))
+		Expect(spec.Jvm.HeapPolicy).To(Equal(shulkermciov1alpha1.JvmHeapPercentage))
+		Expect(spec.Jvm.HeapPercentage).To(Equal(int32(shulkermciov1alpha1.DefaultJvmHeapPercentage)))
+		Expect(spec.Probes.Mode).To(Equal(shulkermciov1alpha1.ProbeModeExec))
+		Expect(spec.Configuration.World.UrlFrom).To(BeNil())
+		Expect(spec.Configuration.Plugins[0].UrlFrom.MavenRef.Extension).To(Equal(shulkermciov1alpha1.DefaultResourceRefMavenExtension))
+		Expect(spec.Configuration.Mods[0].UrlFrom.MavenRef.Extension).To(Equal(shulkermciov1alpha1.DefaultResourceRefMavenExtension))
+		Expect(spec.Configuration.Patches[0].UrlFrom.MavenRef.Extension).To(Equal(shulkermciov1alpha1.DefaultResourceRefMavenExtension))
+	})
+
+	It("keeps the values set in the spec of a MinecraftServer", func() {
+		maxPlayers := 50
+		plugin := mavenRef()
+		plugin.UrlFrom.MavenRef.Extension = "zip"
+		minecraftServer := &shulkermciov1alpha1.MinecraftServer{
+			ObjectMeta: testObjectMeta("server"),
+			MinecraftServerTemplate: shulkermciov1alpha1.MinecraftServerTemplate{
+				Spec: shulkermciov1alpha1.MinecraftServerSpec{
+					ClusterRef: testClusterRef(),
+					Version:    shulkermciov1alpha1.MinecraftServerVersionSpec{Channel: shulkermciov1alpha1.MinecraftServerVersionFabric},
+					Configuration: shulkermciov1alpha1.MinecraftServerConfigurationSpec{
+						MaxPlayers:          &maxPlayers,
+						ProxyForwardingMode: shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord,
+						Plugins:             []shulkermciov1alpha1.ResourceRef{plugin},
+					},
+					Jvm:    &shulkermciov1alpha1.JvmSpec{HeapPolicy: shulkermciov1alpha1.JvmHeapFixed, HeapPercentage: 50},
+					Probes: &shulkermciov1alpha1.ProbesSpec{Mode: shulkermciov1alpha1.ProbeModeServerListPing},
+				},
+			},
+		}
+
+		webhook := &MinecraftServerWebhook{Reader: newFakeReader()}
+		Expect(webhook.Default(context.Background(), minecraftServer)).To(Succeed())
+
+		spec := minecraftServer.Spec
+		Expect(spec.Version.Channel).To(Equal(shulkermciov1alpha1.MinecraftServerVersionFabric))
+		Expect(*spec.Configuration.MaxPlayers).To(Equal(50))
+		Expect(spec.Configuration.ProxyForwardingMode).To(Equal(shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord))
+		Expect(spec.Jvm.HeapPolicy).To(Equal(shulkermciov1alpha1.JvmHeapFixed))
+		Expect(spec.Jvm.HeapPercentage).To(Equal(int32(50)))
+		Expect(spec.Probes.Mode).To(Equal(shulkermciov1alpha1.ProbeModeServerListPing))
+		Expect(spec.Configuration.Plugins[0].UrlFrom.MavenRef.Extension).To(Equal("zip"))
+	})
+
+	DescribeTable("defaults the forwarding mode from the proxies of the cluster",
+		func(channel shulkermciov1alpha1.ProxyVersionChannel, clusterName string, expected shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingMode) {
+			proxyDeployment := &shulkermciov1alpha1.ProxyDeployment{
+				ObjectMeta: testObjectMeta("proxy"),
+				Spec: shulkermciov1alpha1.ProxyDeploymentSpec{
+					ClusterRef: shulkermciov1alpha1.MinecraftClusterRef{Name: clusterName},
+					Template: shulkermciov1alpha1.ProxyTemplate{
+						Spec: shulkermciov1alpha1.ProxySpec{
+							Version: shulkermciov1alpha1.ProxyVersionSpec{Channel: channel},
+						},
+					},
+				},
+			}
+			minecraftServerDeployment := &shulkermciov1alpha1.MinecraftServerDeployment{
+				ObjectMeta: testObjectMeta("lobby"),
+				Spec: shulkermciov1alpha1.MinecraftServerDeploymentSpec{
+					ClusterRef: testClusterRef(),
+				},
+			}
+
+			webhook := &MinecraftServerDeploymentWebhook{Reader: newFakeReader(proxyDeployment)}
+			Expect(webhook.Default(context.Background(), minecraftServerDeployment)).To(Succeed())
+
+			Expect(minecraftServerDeployment.Spec.Template.Spec.Configuration.ProxyForwardingMode).To(Equal(expected))
+		},
+		Entry("Velocity proxies", shulkermciov1alpha1.ProxyVersionVelocity, testClusterName, shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity),
+		Entry("BungeeCord proxies", shulkermciov1alpha1.ProxyVersionBungeeCord, testClusterName, shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord),
+		Entry("Waterfall proxies", shulkermciov1alpha1.ProxyVersionWaterfall, testClusterName, shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord),
+		Entry("proxies of another cluster", shulkermciov1alpha1.ProxyVersionBungeeCord, "other-cluster", shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity),
+	)
+
+	It("defaults the spec of a Proxy", func() {
+		proxy := &shulkermciov1alpha1.Proxy{
+			ObjectMeta: testObjectMeta("proxy"),
+			ProxyTemplate: shulkermciov1alpha1.ProxyTemplate{
+				Spec: shulkermciov1alpha1.ProxySpec{
+					ClusterRef: testClusterRef(),
+					Configuration: shulkermciov1alpha1.ProxyConfigurationSpec{
+						Plugins: []shulkermciov1alpha1.ResourceRef{mavenRef()},
+						Patches: []shulkermciov1alpha1.ResourceRef{mavenRef()},
+					},
+					Jvm:    &shulkermciov1alpha1.JvmSpec{},
+					Probes: &shulkermciov1alpha1.ProbesSpec{},
+				},
+			},
+		}
+
+		webhook := &ProxyWebhook{Reader: newFakeReader()}
+		Expect(webhook.Default(context.Background(), proxy)).To(Succeed())
+
+		spec := proxy.Spec
+		Expect(spec.Version.Channel).To(Equal(shulkermciov1alpha1.ProxyVersionVelocity))
+		Expect(spec.Configuration.MaxPlayers).To(Equal(int32(defaultProxyMaxPlayers)))
+		Expect(spec.Configuration.Motd).To(Equal(defaultProxyMotd))
+		Expect(spec.Configuration.TimeToLiveSeconds).To(Equal(int32(defaultProxyTimeToLiveSeconds)))
+		Expect(spec.Jvm.HeapPolicy).To(Equal(shulkermciov1alpha1.JvmHeapPercentage))
+		Expect(spec.Jvm.HeapPercentage).To(Equal(int32(shulkermciov1alpha1.DefaultJvmHeapPercentage)))
+		Expect(spec.Probes.Mode).To(Equal(shulkermciov1alpha1.ProbeModeExec))
+		Expect(spec.Configuration.Plugins[0].UrlFrom.MavenRef.Extension).To(Equal(shulkermciov1alpha1.DefaultResourceRefMavenExtension))
+		Expect(spec.Configuration.Patches[0].UrlFrom.MavenRef.Extension).To(Equal(shulkermciov1alpha1.DefaultResourceRefMavenExtension))
+	})
+
+	It("defaults the strategy and service of a ProxyDeployment", func() {
+		proxyDeployment := &shulkermciov1alpha1.ProxyDeployment{
+			ObjectMeta: testObjectMeta("proxy"),
+			Spec: shulkermciov1alpha1.ProxyDeploymentSpec{
+				ClusterRef: testClusterRef(),
+			},
+		}
+
+		webhook := &ProxyDeploymentWebhook{Reader: newFakeReader()}
+		Expect(webhook.Default(context.Background(), proxyDeployment)).To(Succeed())
+
+		strategy := proxyDeployment.Spec.Strategy
+		Expect(strategy.Type).To(Equal(shulkermciov1alpha1.DeploymentStrategyRollingUpdate))
+		Expect(*strategy.RollingUpdate.MaxSurge).To(Equal(shulkermciov1alpha1.DefaultRollingUpdateMaxSurge))
+		Expect(*strategy.RollingUpdate.MaxUnavailable).To(Equal(shulkermciov1alpha1.DefaultRollingUpdateMaxUnavailable))
+		Expect(proxyDeployment.Spec.Service.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(proxyDeployment.Spec.Service.ExternalTrafficPolicy).To(Equal(corev1.ServiceExternalTrafficPolicyTypeCluster))
+		Expect(proxyDeployment.Spec.Template.Spec.Version.Channel).To(Equal(shulkermciov1alpha1.ProxyVersionVelocity))
+	})
+
+	It("keeps the rolling update of a deployment", func() {
+		maxSurge := intstr.FromString("50%")
+		proxyDeployment := &shulkermciov1alpha1.ProxyDeployment{
+			ObjectMeta: testObjectMeta("proxy"),
+			Spec: shulkermciov1alpha1.ProxyDeploymentSpec{
+				ClusterRef: testClusterRef(),
+				Strategy: shulkermciov1alpha1.DeploymentStrategy{
+					RollingUpdate: &shulkermciov1alpha1.RollingUpdateDeploymentStrategy{MaxSurge: &maxSurge},
+				},
+			},
+		}
+
+		webhook := &ProxyDeploymentWebhook{Reader: newFakeReader()}
+		Expect(webhook.Default(context.Background(), proxyDeployment)).To(Succeed())
+
+		rollingUpdate := proxyDeployment.Spec.Strategy.RollingUpdate
+		Expect(*rollingUpdate.MaxSurge).To(Equal(maxSurge))
+		Expect(*rollingUpdate.MaxUnavailable).To(Equal(shulkermciov1alpha1.DefaultRollingUpdateMaxUnavailable))
+	})
+
+	DescribeTable("defaults the cache of a MinecraftCluster",
+		func(cache *shulkermciov1alpha1.MinecraftClusterCacheSpec, expected *corev1.ResourceRequirements) {
+			minecraftCluster := &shulkermciov1alpha1.MinecraftCluster{
+				ObjectMeta: testObjectMeta(testClusterName),
+				Spec:       shulkermciov1alpha1.MinecraftClusterSpec{Cache: cache},
+			}
+
+			webhook := &MinecraftClusterWebhook{Reader: newFakeReader()}
+			Expect(webhook.Default(context.Background(), minecraftCluster)).To(Succeed())
+
+			if expected == nil {
+				Expect(minecraftCluster.Spec.Cache).To(BeNil())
+			} else {
+				Expect(*minecraftCluster.Spec.Cache.Resources).To(Equal(*expected))
+			}
+		},
+		Entry("without cache", nil, nil),
+		Entry("without resources",
+			&shulkermciov1alpha1.MinecraftClusterCacheSpec{Size: resource.MustParse("10Gi")},
+			&corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(shulkermciov1alpha1.DefaultMinecraftClusterCacheCpuRequest),
+					corev1.ResourceMemory: resource.MustParse(shulkermciov1alpha1.DefaultMinecraftClusterCacheMemoryRequest),
+				},
+			}),
+		Entry("with resources",
+			&shulkermciov1alpha1.MinecraftClusterCacheSpec{
+				Size: resource.MustParse("10Gi"),
+				Resources: &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+			},
+			&corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			}),
+	)
+})
@@ -0,0 +1,67 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+//+kubebuilder:webhook:path=/mutate-shulkermc-io-v1alpha1-minecraftcluster,mutating=true,failurePolicy=fail,sideEffects=None,groups=shulkermc.io,resources=minecraftclusters,verbs=create;update,versions=v1alpha1,name=mminecraftcluster.shulkermc.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-shulkermc-io-v1alpha1-minecraftcluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=shulkermc.io,resources=minecraftclusters,verbs=create;update,versions=v1alpha1,name=vminecraftcluster.shulkermc.io,admissionReviewVersions=v1
+
+// MinecraftClusterWebhook defaults and validates MinecraftCluster objects
+type MinecraftClusterWebhook struct {
+	client.Reader
+}
+
+var _ webhook.CustomDefaulter = &MinecraftClusterWebhook{}
+var _ webhook.CustomValidator = &MinecraftClusterWebhook{}
+
+// SetupWithManager registers the webhook with the Manager.
+func (w *MinecraftClusterWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&shulkermciov1alpha1.MinecraftCluster{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+func (w *MinecraftClusterWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	minecraftCluster := obj.(*shulkermciov1alpha1.MinecraftCluster)
+
+	defaultMinecraftClusterSpec(&minecraftCluster.Spec)
+
+	return nil
+}
+
+func (w *MinecraftClusterWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	minecraftCluster := obj.(*shulkermciov1alpha1.MinecraftCluster)
+
+	allErrs := validateClusterName(minecraftCluster.Name, field.NewPath("metadata", "name"))
+	allErrs = append(allErrs, validateMinecraftClusterSpec(&minecraftCluster.Spec, field.NewPath("spec"))...)
+
+	return toInvalidError("MinecraftCluster", minecraftCluster.Name, allErrs)
+}
+
+func (w *MinecraftClusterWebhook) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) error {
+	minecraftCluster := newObj.(*shulkermciov1alpha1.MinecraftCluster)
+
+	allErrs := validateMinecraftClusterSpec(&minecraftCluster.Spec, field.NewPath("spec"))
+
+	return toInvalidError("MinecraftCluster", minecraftCluster.Name, allErrs)
+}
+
+func (w *MinecraftClusterWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
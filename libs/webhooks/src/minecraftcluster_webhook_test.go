@@ -0,0 +1,40 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("MinecraftCluster webhook", func() {
+	DescribeTable("ValidateCreate",
+		func(name string, cache *shulkermciov1alpha1.MinecraftClusterCacheSpec, expectedFields []string) {
+			webhook := &MinecraftClusterWebhook{Reader: newFakeReader()}
+			minecraftCluster := &shulkermciov1alpha1.MinecraftCluster{
+				ObjectMeta: testObjectMeta(name),
+				Spec:       shulkermciov1alpha1.MinecraftClusterSpec{Cache: cache},
+			}
+
+			err := webhook.ValidateCreate(context.Background(), minecraftCluster)
+
+			if len(expectedFields) == 0 {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(getInvalidFields(err)).To(ConsistOf(expectedFields))
+			}
+		},
+		Entry("a valid cluster", "cluster", &shulkermciov1alpha1.MinecraftClusterCacheSpec{Size: resource.MustParse("10Gi")}, nil),
+		Entry("a name too long for a label value", "a-cluster-with-a-name-longer-than-the-sixty-three-characters-of-a-label", nil,
+			[]string{"metadata.name"}),
+		Entry("an empty cache", "cluster", &shulkermciov1alpha1.MinecraftClusterCacheSpec{}, []string{"spec.cache.size"}),
+	)
+})
@@ -0,0 +1,82 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+//+kubebuilder:webhook:path=/mutate-shulkermc-io-v1alpha1-minecraftserver,mutating=true,failurePolicy=fail,sideEffects=None,groups=shulkermc.io,resources=minecraftservers,verbs=create;update,versions=v1alpha1,name=mminecraftserver.shulkermc.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-shulkermc-io-v1alpha1-minecraftserver,mutating=false,failurePolicy=fail,sideEffects=None,groups=shulkermc.io,resources=minecraftservers,verbs=create;update,versions=v1alpha1,name=vminecraftserver.shulkermc.io,admissionReviewVersions=v1
+
+// MinecraftServerWebhook defaults and validates MinecraftServer objects
+type MinecraftServerWebhook struct {
+	client.Reader
+}
+
+var _ webhook.CustomDefaulter = &MinecraftServerWebhook{}
+var _ webhook.CustomValidator = &MinecraftServerWebhook{}
+
+// SetupWithManager registers the webhook with the Manager.
+func (w *MinecraftServerWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&shulkermciov1alpha1.MinecraftServer{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+func (w *MinecraftServerWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	minecraftServer := obj.(*shulkermciov1alpha1.MinecraftServer)
+
+	return defaultMinecraftServerSpec(ctx, w, minecraftServer.Namespace, minecraftServer.Spec.ClusterRef.Name, &minecraftServer.Spec)
+}
+
+func (w *MinecraftServerWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	minecraftServer := obj.(*shulkermciov1alpha1.MinecraftServer)
+	specPath := field.NewPath("spec")
+
+	allErrs := validateClusterRef(ctx, w, minecraftServer.Namespace, &minecraftServer.Spec.ClusterRef, specPath.Child("clusterRef"))
+	allErrs = append(allErrs, validateMinecraftServerSpec(&minecraftServer.Spec, specPath)...)
+	if len(allErrs) == 0 {
+		allErrs = append(allErrs, validateForwardingModeInCluster(ctx, w, minecraftServer.Namespace, minecraftServer.Spec.ClusterRef.Name,
+			minecraftServer.Spec.Configuration.ProxyForwardingMode, specPath.Child("config", "proxyForwardingMode"))...)
+	}
+
+	return toInvalidError("MinecraftServer", minecraftServer.Name, allErrs)
+}
+
+func (w *MinecraftServerWebhook) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) error {
+	oldMinecraftServer := oldObj.(*shulkermciov1alpha1.MinecraftServer)
+	minecraftServer := newObj.(*shulkermciov1alpha1.MinecraftServer)
+	specPath := field.NewPath("spec")
+
+	// Never prevent a resource from being finalized
+	if minecraftServer.DeletionTimestamp != nil {
+		return nil
+	}
+
+	allErrs := validateClusterRefUpdate(&minecraftServer.Spec.ClusterRef, &oldMinecraftServer.Spec.ClusterRef, specPath.Child("clusterRef"))
+	allErrs = append(allErrs, validateMinecraftServerSpec(&minecraftServer.Spec, specPath)...)
+	if len(allErrs) == 0 && minecraftServer.Spec.Configuration.ProxyForwardingMode != oldMinecraftServer.Spec.Configuration.ProxyForwardingMode {
+		allErrs = append(allErrs, validateForwardingModeInCluster(ctx, w, minecraftServer.Namespace, minecraftServer.Spec.ClusterRef.Name,
+			minecraftServer.Spec.Configuration.ProxyForwardingMode, specPath.Child("config", "proxyForwardingMode"))...)
+	}
+
+	return toInvalidError("MinecraftServer", minecraftServer.Name, allErrs)
+}
+
+func (w *MinecraftServerWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
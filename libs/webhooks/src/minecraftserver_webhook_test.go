@@ -0,0 +1,158 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("MinecraftServer webhook", func() {
+	newMinecraftServer := func(mutate func(spec *shulkermciov1alpha1.MinecraftServerSpec)) *shulkermciov1alpha1.MinecraftServer {
+		minecraftServer := &shulkermciov1alpha1.MinecraftServer{
+			ObjectMeta: testObjectMeta("server"),
+			MinecraftServerTemplate: shulkermciov1alpha1.MinecraftServerTemplate{
+				Spec: shulkermciov1alpha1.MinecraftServerSpec{
+					ClusterRef: testClusterRef(),
+					Version: shulkermciov1alpha1.MinecraftServerVersionSpec{
+						Channel: shulkermciov1alpha1.MinecraftServerVersionPaper,
+						Name:    "1.20.1",
+					},
+					Configuration: shulkermciov1alpha1.MinecraftServerConfigurationSpec{
+						ProxyForwardingMode: shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity,
+					},
+				},
+			},
+		}
+		if mutate != nil {
+			mutate(&minecraftServer.Spec)
+		}
+		return minecraftServer
+	}
+
+	newProxy := func(clusterName string, channel shulkermciov1alpha1.ProxyVersionChannel) client.Object {
+		return &shulkermciov1alpha1.Proxy{
+			ObjectMeta: testObjectMeta("proxy-" + string(channel)),
+			ProxyTemplate: shulkermciov1alpha1.ProxyTemplate{
+				Spec: shulkermciov1alpha1.ProxySpec{
+					ClusterRef: shulkermciov1alpha1.MinecraftClusterRef{Name: clusterName},
+					Version:    shulkermciov1alpha1.ProxyVersionSpec{Channel: channel},
+				},
+			},
+		}
+	}
+
+	newProxyDeployment := func(clusterName string, channel shulkermciov1alpha1.ProxyVersionChannel) client.Object {
+		return &shulkermciov1alpha1.ProxyDeployment{
+			ObjectMeta: testObjectMeta("proxies-" + string(channel)),
+			Spec: shulkermciov1alpha1.ProxyDeploymentSpec{
+				ClusterRef: shulkermciov1alpha1.MinecraftClusterRef{Name: clusterName},
+				Template: shulkermciov1alpha1.ProxyTemplate{
+					Spec: shulkermciov1alpha1.ProxySpec{
+						Version: shulkermciov1alpha1.ProxyVersionSpec{Channel: channel},
+					},
+				},
+			},
+		}
+	}
+
+	DescribeTable("ValidateCreate",
+		func(objects []client.Object, mutate func(spec *shulkermciov1alpha1.MinecraftServerSpec), expectedFields []string) {
+			webhook := &MinecraftServerWebhook{Reader: newFakeReader(objects...)}
+
+			err := webhook.ValidateCreate(context.Background(), newMinecraftServer(mutate))
+
+			if len(expectedFields) == 0 {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(getInvalidFields(err)).To(ConsistOf(expectedFields))
+			}
+		},
+		Entry("a valid server", nil, nil, nil),
+		Entry("a missing cluster reference", nil,
+			func(spec *shulkermciov1alpha1.MinecraftServerSpec) { spec.ClusterRef.Name = "" },
+			[]string{"spec.clusterRef.name"}),
+		Entry("an unknown cluster", nil,
+			func(spec *shulkermciov1alpha1.MinecraftServerSpec) { spec.ClusterRef.Name = "unknown" },
+			[]string{"spec.clusterRef.name"}),
+		Entry("mods on a Paper server", nil,
+			func(spec *shulkermciov1alpha1.MinecraftServerSpec) {
+				spec.Configuration.Mods = []shulkermciov1alpha1.ResourceRef{{Url: "https://example.com/mod.jar"}}
+			},
+			[]string{"spec.config.mods"}),
+		Entry("an incomplete Maven reference", nil,
+			func(spec *shulkermciov1alpha1.MinecraftServerSpec) {
+				spec.Configuration.Plugins = []shulkermciov1alpha1.ResourceRef{{
+					UrlFrom: &shulkermciov1alpha1.ResourceRefSource{
+						MavenRef: &shulkermciov1alpha1.ResourceRefMavenSelector{Repository: "https://maven.example.com"},
+					},
+				}}
+			},
+			[]string{"spec.config.plugins[0].urlFrom.mavenRef.groupId", "spec.config.plugins[0].urlFrom.mavenRef.artifactId", "spec.config.plugins[0].urlFrom.mavenRef.version"}),
+		Entry("the Velocity forwarding mode on Spigot", nil,
+			func(spec *shulkermciov1alpha1.MinecraftServerSpec) {
+				spec.Version.Channel = shulkermciov1alpha1.MinecraftServerVersionSpigot
+			},
+			[]string{"spec.config.proxyForwardingMode"}),
+		Entry("the forwarding mode of the Velocity proxies of the cluster",
+			[]client.Object{newProxy(testClusterName, shulkermciov1alpha1.ProxyVersionVelocity)},
+			nil, nil),
+		Entry("a forwarding mode not matching a proxy of the cluster",
+			[]client.Object{newProxy(testClusterName, shulkermciov1alpha1.ProxyVersionBungeeCord)},
+			nil,
+			[]string{"spec.config.proxyForwardingMode"}),
+		Entry("a forwarding mode not matching a proxy deployment of the cluster",
+			[]client.Object{newProxyDeployment(testClusterName, shulkermciov1alpha1.ProxyVersionWaterfall)},
+			nil,
+			[]string{"spec.config.proxyForwardingMode"}),
+		Entry("a forwarding mode not matching the proxies of another cluster",
+			[]client.Object{newProxy("other-cluster", shulkermciov1alpha1.ProxyVersionBungeeCord), newProxyDeployment("other-cluster", shulkermciov1alpha1.ProxyVersionWaterfall)},
+			nil, nil),
+	)
+
+	DescribeTable("ValidateUpdate",
+		func(objects []client.Object, mutate func(spec *shulkermciov1alpha1.MinecraftServerSpec), deleting bool, expectedFields []string) {
+			webhook := &MinecraftServerWebhook{Reader: newFakeReader(objects...)}
+			minecraftServer := newMinecraftServer(mutate)
+			if deleting {
+				now := metav1.Now()
+				minecraftServer.DeletionTimestamp = &now
+			}
+
+			err := webhook.ValidateUpdate(context.Background(), newMinecraftServer(nil), minecraftServer)
+
+			if len(expectedFields) == 0 {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(getInvalidFields(err)).To(ConsistOf(expectedFields))
+			}
+		},
+		Entry("an unchanged server", nil, nil, false, nil),
+		Entry("a changed cluster reference", nil,
+			func(spec *shulkermciov1alpha1.MinecraftServerSpec) { spec.ClusterRef.Name = "other-cluster" },
+			false,
+			[]string{"spec.clusterRef"}),
+		Entry("a changed cluster reference of a server being deleted", nil,
+			func(spec *shulkermciov1alpha1.MinecraftServerSpec) { spec.ClusterRef.Name = "other-cluster" },
+			true, nil),
+		Entry("an unchanged forwarding mode not matching a proxy of the cluster",
+			[]client.Object{newProxy(testClusterName, shulkermciov1alpha1.ProxyVersionBungeeCord)},
+			nil, false, nil),
+		Entry("a changed forwarding mode not matching a proxy of the cluster",
+			[]client.Object{newProxy(testClusterName, shulkermciov1alpha1.ProxyVersionVelocity)},
+			func(spec *shulkermciov1alpha1.MinecraftServerSpec) {
+				spec.Configuration.ProxyForwardingMode = shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord
+			},
+			false,
+			[]string{"spec.config.proxyForwardingMode"}),
+	)
+})
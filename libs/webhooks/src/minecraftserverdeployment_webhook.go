@@ -0,0 +1,111 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+//+kubebuilder:webhook:path=/mutate-shulkermc-io-v1alpha1-minecraftserverdeployment,mutating=true,failurePolicy=fail,sideEffects=None,groups=shulkermc.io,resources=minecraftserverdeployments,verbs=create;update,versions=v1alpha1,name=mminecraftserverdeployment.shulkermc.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-shulkermc-io-v1alpha1-minecraftserverdeployment,mutating=false,failurePolicy=fail,sideEffects=None,groups=shulkermc.io,resources=minecraftserverdeployments,verbs=create;update,versions=v1alpha1,name=vminecraftserverdeployment.shulkermc.io,admissionReviewVersions=v1
+
+// MinecraftServerDeploymentWebhook defaults and validates MinecraftServerDeployment objects
+type MinecraftServerDeploymentWebhook struct {
+	client.Reader
+}
+
+var _ webhook.CustomDefaulter = &MinecraftServerDeploymentWebhook{}
+var _ webhook.CustomValidator = &MinecraftServerDeploymentWebhook{}
+
+// SetupWithManager registers the webhook with the Manager.
+func (w *MinecraftServerDeploymentWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&shulkermciov1alpha1.MinecraftServerDeployment{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+func (w *MinecraftServerDeploymentWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	minecraftServerDeployment := obj.(*shulkermciov1alpha1.MinecraftServerDeployment)
+
+	defaultDeploymentStrategy(&minecraftServerDeployment.Spec.Strategy)
+
+	return defaultMinecraftServerSpec(ctx, w, minecraftServerDeployment.Namespace, minecraftServerDeployment.Spec.ClusterRef.Name, &minecraftServerDeployment.Spec.Template.Spec)
+}
+
+func (w *MinecraftServerDeploymentWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	minecraftServerDeployment := obj.(*shulkermciov1alpha1.MinecraftServerDeployment)
+	specPath := field.NewPath("spec")
+
+	allErrs := validateClusterRef(ctx, w, minecraftServerDeployment.Namespace, &minecraftServerDeployment.Spec.ClusterRef, specPath.Child("clusterRef"))
+	allErrs = append(allErrs, validateMinecraftServerDeploymentSpec(&minecraftServerDeployment.Spec, specPath)...)
+	if len(allErrs) == 0 {
+		allErrs = append(allErrs, validateForwardingModeInCluster(ctx, w, minecraftServerDeployment.Namespace, minecraftServerDeployment.Spec.ClusterRef.Name,
+			minecraftServerDeployment.Spec.Template.Spec.Configuration.ProxyForwardingMode, specPath.Child("template", "spec", "config", "proxyForwardingMode"))...)
+	}
+
+	return toInvalidError("MinecraftServerDeployment", minecraftServerDeployment.Name, allErrs)
+}
+
+func (w *MinecraftServerDeploymentWebhook) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) error {
+	oldMinecraftServerDeployment := oldObj.(*shulkermciov1alpha1.MinecraftServerDeployment)
+	minecraftServerDeployment := newObj.(*shulkermciov1alpha1.MinecraftServerDeployment)
+	specPath := field.NewPath("spec")
+
+	// Never prevent a resource from being finalized
+	if minecraftServerDeployment.DeletionTimestamp != nil {
+		return nil
+	}
+
+	allErrs := validateClusterRefUpdate(&minecraftServerDeployment.Spec.ClusterRef, &oldMinecraftServerDeployment.Spec.ClusterRef, specPath.Child("clusterRef"))
+	allErrs = append(allErrs, validateMinecraftServerDeploymentSpec(&minecraftServerDeployment.Spec, specPath)...)
+	forwardingMode := minecraftServerDeployment.Spec.Template.Spec.Configuration.ProxyForwardingMode
+	if len(allErrs) == 0 && forwardingMode != oldMinecraftServerDeployment.Spec.Template.Spec.Configuration.ProxyForwardingMode {
+		allErrs = append(allErrs, validateForwardingModeInCluster(ctx, w, minecraftServerDeployment.Namespace, minecraftServerDeployment.Spec.ClusterRef.Name,
+			forwardingMode, specPath.Child("template", "spec", "config", "proxyForwardingMode"))...)
+	}
+
+	return toInvalidError("MinecraftServerDeployment", minecraftServerDeployment.Name, allErrs)
+}
+
+func (w *MinecraftServerDeploymentWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func validateMinecraftServerDeploymentSpec(spec *shulkermciov1alpha1.MinecraftServerDeploymentSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), spec.Replicas, "must be greater than or equal to 0"))
+	}
+	allErrs = append(allErrs, validateDeploymentStrategy(&spec.Strategy, fldPath.Child("strategy"))...)
+	allErrs = append(allErrs, validateMinecraftServerSpec(&spec.Template.Spec, fldPath.Child("template", "spec"))...)
+
+	// The resources are locked by the operator from the template
+	if len(spec.Template.Spec.LockedResources) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("template", "spec", "lockedResources"), "is set by the operator"))
+	}
+
+	// The claims deleted with their MinecraftServer are also deleted
+	// when the MinecraftServerDeployment deletes all of them
+	if persistence := spec.Template.Spec.Persistence; persistence != nil {
+		retentionPolicy := persistence.RetentionPolicy
+		if retentionPolicy.WhenScaled == shulkermciov1alpha1.DeletePersistentVolumeClaimRetentionPolicyType && retentionPolicy.WhenDeleted == shulkermciov1alpha1.RetainPersistentVolumeClaimRetentionPolicyType {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("template", "spec", "persistence", "retentionPolicy", "whenDeleted"), "must be Delete when whenScaled is Delete"))
+		}
+	}
+
+	return allErrs
+}
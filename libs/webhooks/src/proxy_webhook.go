@@ -0,0 +1,84 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+//+kubebuilder:webhook:path=/mutate-shulkermc-io-v1alpha1-proxy,mutating=true,failurePolicy=fail,sideEffects=None,groups=shulkermc.io,resources=proxies,verbs=create;update,versions=v1alpha1,name=mproxy.shulkermc.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-shulkermc-io-v1alpha1-proxy,mutating=false,failurePolicy=fail,sideEffects=None,groups=shulkermc.io,resources=proxies,verbs=create;update,versions=v1alpha1,name=vproxy.shulkermc.io,admissionReviewVersions=v1
+
+// ProxyWebhook defaults and validates Proxy objects
+type ProxyWebhook struct {
+	client.Reader
+}
+
+var _ webhook.CustomDefaulter = &ProxyWebhook{}
+var _ webhook.CustomValidator = &ProxyWebhook{}
+
+// SetupWithManager registers the webhook with the Manager.
+func (w *ProxyWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&shulkermciov1alpha1.Proxy{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+func (w *ProxyWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	proxy := obj.(*shulkermciov1alpha1.Proxy)
+
+	defaultProxySpec(&proxy.Spec)
+
+	return nil
+}
+
+func (w *ProxyWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	proxy := obj.(*shulkermciov1alpha1.Proxy)
+	specPath := field.NewPath("spec")
+
+	allErrs := validateClusterRef(ctx, w, proxy.Namespace, &proxy.Spec.ClusterRef, specPath.Child("clusterRef"))
+	allErrs = append(allErrs, validateProxySpec(&proxy.Spec, specPath)...)
+	if len(allErrs) == 0 {
+		allErrs = append(allErrs, validateProxyChannelInCluster(ctx, w, proxy.Namespace, proxy.Spec.ClusterRef.Name,
+			proxy.Spec.Version.Channel, specPath.Child("version", "channel"))...)
+	}
+
+	return toInvalidError("Proxy", proxy.Name, allErrs)
+}
+
+func (w *ProxyWebhook) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) error {
+	oldProxy := oldObj.(*shulkermciov1alpha1.Proxy)
+	proxy := newObj.(*shulkermciov1alpha1.Proxy)
+	specPath := field.NewPath("spec")
+
+	// Never prevent a resource from being finalized
+	if proxy.DeletionTimestamp != nil {
+		return nil
+	}
+
+	allErrs := validateClusterRefUpdate(&proxy.Spec.ClusterRef, &oldProxy.Spec.ClusterRef, specPath.Child("clusterRef"))
+	allErrs = append(allErrs, validateProxySpec(&proxy.Spec, specPath)...)
+	if len(allErrs) == 0 && proxy.Spec.Version.Channel != oldProxy.Spec.Version.Channel {
+		allErrs = append(allErrs, validateProxyChannelInCluster(ctx, w, proxy.Namespace, proxy.Spec.ClusterRef.Name,
+			proxy.Spec.Version.Channel, specPath.Child("version", "channel"))...)
+	}
+
+	return toInvalidError("Proxy", proxy.Name, allErrs)
+}
+
+func (w *ProxyWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
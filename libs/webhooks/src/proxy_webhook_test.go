@@ -0,0 +1,140 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+var _ = Describe("Proxy webhook", func() {
+	newProxy := func(mutate func(spec *shulkermciov1alpha1.ProxySpec)) *shulkermciov1alpha1.Proxy {
+		proxy := &shulkermciov1alpha1.Proxy{
+			ObjectMeta: testObjectMeta("proxy"),
+			ProxyTemplate: shulkermciov1alpha1.ProxyTemplate{
+				Spec: shulkermciov1alpha1.ProxySpec{
+					ClusterRef: testClusterRef(),
+					Version: shulkermciov1alpha1.ProxyVersionSpec{
+						Channel: shulkermciov1alpha1.ProxyVersionVelocity,
+						Name:    "3.2.0",
+					},
+				},
+			},
+		}
+		if mutate != nil {
+			mutate(&proxy.Spec)
+		}
+		return proxy
+	}
+
+	newMinecraftServer := func(clusterName string, forwardingMode shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingMode) client.Object {
+		return &shulkermciov1alpha1.MinecraftServer{
+			ObjectMeta: testObjectMeta("server-" + string(forwardingMode)),
+			MinecraftServerTemplate: shulkermciov1alpha1.MinecraftServerTemplate{
+				Spec: shulkermciov1alpha1.MinecraftServerSpec{
+					ClusterRef: shulkermciov1alpha1.MinecraftClusterRef{Name: clusterName},
+					Configuration: shulkermciov1alpha1.MinecraftServerConfigurationSpec{
+						ProxyForwardingMode: forwardingMode,
+					},
+				},
+			},
+		}
+	}
+
+	newMinecraftServerDeployment := func(clusterName string, forwardingMode shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingMode) client.Object {
+		return &shulkermciov1alpha1.MinecraftServerDeployment{
+			ObjectMeta: testObjectMeta("servers-" + string(forwardingMode)),
+			Spec: shulkermciov1alpha1.MinecraftServerDeploymentSpec{
+				ClusterRef: shulkermciov1alpha1.MinecraftClusterRef{Name: clusterName},
+				Template: shulkermciov1alpha1.MinecraftServerTemplate{
+					Spec: shulkermciov1alpha1.MinecraftServerSpec{
+						Configuration: shulkermciov1alpha1.MinecraftServerConfigurationSpec{
+							ProxyForwardingMode: forwardingMode,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	DescribeTable("ValidateCreate",
+		func(objects []client.Object, mutate func(spec *shulkermciov1alpha1.ProxySpec), expectedFields []string) {
+			webhook := &ProxyWebhook{Reader: newFakeReader(objects...)}
+
+			err := webhook.ValidateCreate(context.Background(), newProxy(mutate))
+
+			if len(expectedFields) == 0 {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(getInvalidFields(err)).To(ConsistOf(expectedFields))
+			}
+		},
+		Entry("a valid proxy", nil, nil, nil),
+		Entry("an unknown cluster", nil,
+			func(spec *shulkermciov1alpha1.ProxySpec) { spec.ClusterRef.Name = "unknown" },
+			[]string{"spec.clusterRef.name"}),
+		Entry("a sidecar named like a container of Shulker", nil,
+			func(spec *shulkermciov1alpha1.ProxySpec) {
+				spec.PodOverrides = &shulkermciov1alpha1.ProxyPodOverridesSpec{
+					Sidecars: []corev1.Container{{Name: "proxy", Image: "busybox"}},
+				}
+			},
+			[]string{"spec.podOverrides.sidecars[0].name"}),
+		Entry("the channel matching the servers of the cluster",
+			[]client.Object{newMinecraftServer(testClusterName, shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity)},
+			nil, nil),
+		Entry("a channel not matching a server of the cluster",
+			[]client.Object{newMinecraftServer(testClusterName, shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord)},
+			nil,
+			[]string{"spec.version.channel"}),
+		Entry("a channel not matching a server deployment of the cluster",
+			[]client.Object{newMinecraftServerDeployment(testClusterName, shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord)},
+			nil,
+			[]string{"spec.version.channel"}),
+		Entry("a BungeeCord channel matching the servers of the cluster",
+			[]client.Object{newMinecraftServerDeployment(testClusterName, shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord)},
+			func(spec *shulkermciov1alpha1.ProxySpec) {
+				spec.Version.Channel = shulkermciov1alpha1.ProxyVersionWaterfall
+			},
+			nil),
+		Entry("a channel not matching the servers of another cluster",
+			[]client.Object{newMinecraftServer("other-cluster", shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord)},
+			nil, nil),
+	)
+
+	DescribeTable("ValidateUpdate",
+		func(objects []client.Object, mutate func(spec *shulkermciov1alpha1.ProxySpec), expectedFields []string) {
+			webhook := &ProxyWebhook{Reader: newFakeReader(objects...)}
+
+			err := webhook.ValidateUpdate(context.Background(), newProxy(nil), newProxy(mutate))
+
+			if len(expectedFields) == 0 {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(getInvalidFields(err)).To(ConsistOf(expectedFields))
+			}
+		},
+		Entry("an unchanged proxy", nil, nil, nil),
+		Entry("a changed cluster reference", nil,
+			func(spec *shulkermciov1alpha1.ProxySpec) { spec.ClusterRef.Name = "other-cluster" },
+			[]string{"spec.clusterRef"}),
+		Entry("an unchanged channel not matching a server of the cluster",
+			[]client.Object{newMinecraftServer(testClusterName, shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord)},
+			nil, nil),
+		Entry("a changed channel not matching a server of the cluster",
+			[]client.Object{newMinecraftServer(testClusterName, shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity)},
+			func(spec *shulkermciov1alpha1.ProxySpec) {
+				spec.Version.Channel = shulkermciov1alpha1.ProxyVersionBungeeCord
+			},
+			[]string{"spec.version.channel"}),
+	)
+})
@@ -0,0 +1,104 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+//+kubebuilder:webhook:path=/mutate-shulkermc-io-v1alpha1-proxydeployment,mutating=true,failurePolicy=fail,sideEffects=None,groups=shulkermc.io,resources=proxydeployments,verbs=create;update,versions=v1alpha1,name=mproxydeployment.shulkermc.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-shulkermc-io-v1alpha1-proxydeployment,mutating=false,failurePolicy=fail,sideEffects=None,groups=shulkermc.io,resources=proxydeployments,verbs=create;update,versions=v1alpha1,name=vproxydeployment.shulkermc.io,admissionReviewVersions=v1
+
+// ProxyDeploymentWebhook defaults and validates ProxyDeployment objects
+type ProxyDeploymentWebhook struct {
+	client.Reader
+}
+
+var _ webhook.CustomDefaulter = &ProxyDeploymentWebhook{}
+var _ webhook.CustomValidator = &ProxyDeploymentWebhook{}
+
+// SetupWithManager registers the webhook with the Manager.
+func (w *ProxyDeploymentWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&shulkermciov1alpha1.ProxyDeployment{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+func (w *ProxyDeploymentWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	proxyDeployment := obj.(*shulkermciov1alpha1.ProxyDeployment)
+
+	defaultDeploymentStrategy(&proxyDeployment.Spec.Strategy)
+	defaultProxyDeploymentServiceSpec(&proxyDeployment.Spec.Service)
+	defaultProxySpec(&proxyDeployment.Spec.Template.Spec)
+
+	return nil
+}
+
+func (w *ProxyDeploymentWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	proxyDeployment := obj.(*shulkermciov1alpha1.ProxyDeployment)
+	specPath := field.NewPath("spec")
+
+	allErrs := validateClusterRef(ctx, w, proxyDeployment.Namespace, &proxyDeployment.Spec.ClusterRef, specPath.Child("clusterRef"))
+	allErrs = append(allErrs, validateProxyDeploymentSpec(&proxyDeployment.Spec, specPath)...)
+	if len(allErrs) == 0 {
+		allErrs = append(allErrs, validateProxyChannelInCluster(ctx, w, proxyDeployment.Namespace, proxyDeployment.Spec.ClusterRef.Name,
+			proxyDeployment.Spec.Template.Spec.Version.Channel, specPath.Child("template", "spec", "version", "channel"))...)
+	}
+
+	return toInvalidError("ProxyDeployment", proxyDeployment.Name, allErrs)
+}
+
+func (w *ProxyDeploymentWebhook) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) error {
+	oldProxyDeployment := oldObj.(*shulkermciov1alpha1.ProxyDeployment)
+	proxyDeployment := newObj.(*shulkermciov1alpha1.ProxyDeployment)
+	specPath := field.NewPath("spec")
+
+	// Never prevent a resource from being finalized
+	if proxyDeployment.DeletionTimestamp != nil {
+		return nil
+	}
+
+	allErrs := validateClusterRefUpdate(&proxyDeployment.Spec.ClusterRef, &oldProxyDeployment.Spec.ClusterRef, specPath.Child("clusterRef"))
+	allErrs = append(allErrs, validateProxyDeploymentSpec(&proxyDeployment.Spec, specPath)...)
+	channel := proxyDeployment.Spec.Template.Spec.Version.Channel
+	if len(allErrs) == 0 && channel != oldProxyDeployment.Spec.Template.Spec.Version.Channel {
+		allErrs = append(allErrs, validateProxyChannelInCluster(ctx, w, proxyDeployment.Namespace, proxyDeployment.Spec.ClusterRef.Name,
+			channel, specPath.Child("template", "spec", "version", "channel"))...)
+	}
+
+	return toInvalidError("ProxyDeployment", proxyDeployment.Name, allErrs)
+}
+
+func (w *ProxyDeploymentWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+func validateProxyDeploymentSpec(spec *shulkermciov1alpha1.ProxyDeploymentSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.Replicas < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), spec.Replicas, "must be greater than or equal to 0"))
+	}
+	allErrs = append(allErrs, validateDeploymentStrategy(&spec.Strategy, fldPath.Child("strategy"))...)
+	allErrs = append(allErrs, validateProxySpec(&spec.Template.Spec, fldPath.Child("template", "spec"))...)
+
+	// The resources are locked by the operator from the template
+	if len(spec.Template.Spec.LockedResources) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("template", "spec", "lockedResources"), "is set by the operator"))
+	}
+
+	return allErrs
+}
@@ -0,0 +1,557 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backup "github.com/iamblueslime/shulker/libs/backup/src"
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+// Wraps a list of field errors into an Invalid API error, or returns
+// nil if there is none.
+func toInvalidError(kind string, name string, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(shulkermciov1alpha1.GroupVersion.WithKind(kind).GroupKind(), name, allErrs)
+}
+
+func validateClusterName(name string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// The name of the cluster is used as a label value on every
+	// resource created by Shulker
+	for _, msg := range validation.IsValidLabelValue(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, msg))
+	}
+
+	return allErrs
+}
+
+func validateClusterRef(ctx context.Context, reader client.Reader, namespace string, clusterRef *shulkermciov1alpha1.MinecraftClusterRef, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if clusterRef.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), "a MinecraftCluster must be referenced"))
+		return allErrs
+	}
+
+	cluster := &shulkermciov1alpha1.MinecraftCluster{}
+	err := reader.Get(ctx, types.NamespacedName{
+		Namespace: namespace,
+		Name:      clusterRef.Name,
+	}, cluster)
+	if apierrors.IsNotFound(err) {
+		allErrs = append(allErrs, field.NotFound(fldPath.Child("name"), clusterRef.Name))
+	} else if err != nil {
+		allErrs = append(allErrs, field.InternalError(fldPath.Child("name"), err))
+	}
+
+	return allErrs
+}
+
+func validateClusterRefUpdate(newClusterRef *shulkermciov1alpha1.MinecraftClusterRef, oldClusterRef *shulkermciov1alpha1.MinecraftClusterRef, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if newClusterRef.Name != oldClusterRef.Name {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "field is immutable"))
+	}
+
+	return allErrs
+}
+
+func validateResourceRef(ref *shulkermciov1alpha1.ResourceRef, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if ref.Url == "" && ref.UrlFrom == nil {
+		allErrs = append(allErrs, field.Required(fldPath, "one of url or urlFrom must be set"))
+		return allErrs
+	} else if ref.Url != "" && ref.UrlFrom != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("urlFrom"), "may not be set when url is set"))
+		return allErrs
+	}
+
+	if ref.UrlFrom != nil {
+		allErrs = append(allErrs, validateResourceRefSource(ref.UrlFrom, fldPath.Child("urlFrom"))...)
+	}
+
+	if ref.Checksum != nil && ref.Checksum.Sha256 == "" && ref.Checksum.Sha512 == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("checksum"), "one of sha256 or sha512 must be set"))
+	}
+
+	return allErrs
+}
+
+func validateResourceRefs(refs []shulkermciov1alpha1.ResourceRef, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i := range refs {
+		allErrs = append(allErrs, validateResourceRef(&refs[i], fldPath.Index(i))...)
+	}
+
+	return allErrs
+}
+
+func validateResourceRefSource(source *shulkermciov1alpha1.ResourceRefSource, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	sourceCount := 0
+	for _, isSet := range []bool{source.MavenRef != nil, source.ModrinthRef != nil, source.HangarRef != nil, source.GitHubReleaseRef != nil, source.CurseForgeRef != nil, source.ConfigMapRef != nil, source.SecretRef != nil, source.OCIRef != nil} {
+		if isSet {
+			sourceCount++
+		}
+	}
+	if sourceCount == 0 {
+		allErrs = append(allErrs, field.Required(fldPath, "a source must be set"))
+		return allErrs
+	} else if sourceCount > 1 {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "only one source can be set"))
+		return allErrs
+	}
+
+	if source.MavenRef != nil {
+		mavenPath := fldPath.Child("mavenRef")
+		if source.MavenRef.Repository == "" {
+			allErrs = append(allErrs, field.Required(mavenPath.Child("repository"), ""))
+		}
+		if source.MavenRef.GroupId == "" {
+			allErrs = append(allErrs, field.Required(mavenPath.Child("groupId"), ""))
+		}
+		if source.MavenRef.ArtifactId == "" {
+			allErrs = append(allErrs, field.Required(mavenPath.Child("artifactId"), ""))
+		}
+		if source.MavenRef.Version == "" {
+			allErrs = append(allErrs, field.Required(mavenPath.Child("version"), ""))
+		}
+		if strings.ContainsAny(source.MavenRef.Classifier, "/.") {
+			allErrs = append(allErrs, field.Invalid(mavenPath.Child("classifier"), source.MavenRef.Classifier, "must not contain / or ."))
+		}
+		if strings.Contains(source.MavenRef.Extension, "/") {
+			allErrs = append(allErrs, field.Invalid(mavenPath.Child("extension"), source.MavenRef.Extension, "must not contain /"))
+		}
+	}
+
+	if source.ModrinthRef != nil && source.ModrinthRef.Project == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("modrinthRef", "project"), ""))
+	}
+
+	if source.HangarRef != nil {
+		hangarPath := fldPath.Child("hangarRef")
+		if source.HangarRef.Project == "" {
+			allErrs = append(allErrs, field.Required(hangarPath.Child("project"), ""))
+		}
+		if source.HangarRef.Platform == "" {
+			allErrs = append(allErrs, field.Required(hangarPath.Child("platform"), ""))
+		}
+	}
+
+	if source.GitHubReleaseRef != nil {
+		gitHubPath := fldPath.Child("gitHubReleaseRef")
+		if owner, name, found := strings.Cut(source.GitHubReleaseRef.Repository, "/"); !found || owner == "" || name == "" || strings.Contains(name, "/") {
+			allErrs = append(allErrs, field.Invalid(gitHubPath.Child("repository"), source.GitHubReleaseRef.Repository, "must be in the owner/name form"))
+		}
+		if _, err := path.Match(source.GitHubReleaseRef.AssetName, ""); err != nil {
+			allErrs = append(allErrs, field.Invalid(gitHubPath.Child("assetName"), source.GitHubReleaseRef.AssetName, err.Error()))
+		}
+	}
+
+	if source.CurseForgeRef != nil {
+		curseForgePath := fldPath.Child("curseForgeRef")
+		if source.CurseForgeRef.ProjectId <= 0 {
+			allErrs = append(allErrs, field.Required(curseForgePath.Child("projectId"), ""))
+		}
+		if source.CurseForgeRef.ApiKeySecretName == "" {
+			allErrs = append(allErrs, field.Required(curseForgePath.Child("apiKeySecretName"), ""))
+		}
+	}
+
+	if source.ConfigMapRef != nil {
+		allErrs = append(allErrs, validateResourceRefKeySelector(source.ConfigMapRef, fldPath.Child("configMapRef"))...)
+	}
+
+	if source.SecretRef != nil {
+		allErrs = append(allErrs, validateResourceRefKeySelector(source.SecretRef, fldPath.Child("secretRef"))...)
+	}
+
+	if source.OCIRef != nil {
+		ociPath := fldPath.Child("ociRef")
+		if source.OCIRef.Repository == "" {
+			allErrs = append(allErrs, field.Required(ociPath.Child("repository"), ""))
+		}
+		if source.OCIRef.Tag != "" && source.OCIRef.Digest != "" {
+			allErrs = append(allErrs, field.Forbidden(ociPath.Child("tag"), "may not be set when digest is set"))
+		}
+	}
+
+	return allErrs
+}
+
+// The key is used as file name in the init container.
+func validateResourceRefKeySelector(keySelector *shulkermciov1alpha1.ResourceRefKeySelector, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if keySelector.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	}
+
+	if keySelector.Key == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("key"), ""))
+	} else {
+		for _, msg := range validation.IsConfigMapKey(keySelector.Key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("key"), keySelector.Key, msg))
+		}
+	}
+
+	return allErrs
+}
+
+func validateDeploymentStrategy(strategy *shulkermciov1alpha1.DeploymentStrategy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if strategy.RollingUpdate == nil {
+		return allErrs
+	}
+
+	rollingUpdatePath := fldPath.Child("rollingUpdate")
+	if strategy.GetType() != shulkermciov1alpha1.DeploymentStrategyRollingUpdate {
+		allErrs = append(allErrs, field.Forbidden(rollingUpdatePath, "may not be set when type is not RollingUpdate"))
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateIntOrPercent(strategy.RollingUpdate.MaxSurge, rollingUpdatePath.Child("maxSurge"))...)
+	allErrs = append(allErrs, validateIntOrPercent(strategy.RollingUpdate.MaxUnavailable, rollingUpdatePath.Child("maxUnavailable"))...)
+
+	return allErrs
+}
+
+func validateIntOrPercent(value *intstr.IntOrString, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if value == nil {
+		return allErrs
+	}
+
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(value, 100, false)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, value.String(), "must be an integer or a percentage"))
+	} else if scaled < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, value.String(), "must be greater than or equal to 0"))
+	}
+
+	return allErrs
+}
+
+// Returns the forwarding mode the MinecraftServers must use to accept
+// players coming from a proxy of the given channel.
+func getForwardingModeForProxyChannel(channel shulkermciov1alpha1.ProxyVersionChannel) shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingMode {
+	if channel == shulkermciov1alpha1.ProxyVersionVelocity {
+		return shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity
+	}
+
+	return shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeBungeeCord
+}
+
+func validateMinecraftServerSpec(spec *shulkermciov1alpha1.MinecraftServerSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	configPath := fldPath.Child("config")
+	if spec.Configuration.World != nil {
+		allErrs = append(allErrs, validateResourceRef(spec.Configuration.World, configPath.Child("world"))...)
+	}
+	allErrs = append(allErrs, validateResourceRefs(spec.Configuration.Plugins, configPath.Child("plugins"))...)
+	allErrs = append(allErrs, validateResourceRefs(spec.Configuration.Mods, configPath.Child("mods"))...)
+	allErrs = append(allErrs, validateResourceRefs(spec.Configuration.Patches, configPath.Child("patches"))...)
+
+	if len(spec.Configuration.Mods) > 0 {
+		switch spec.Version.Channel {
+		case shulkermciov1alpha1.MinecraftServerVersionForge, shulkermciov1alpha1.MinecraftServerVersionFabric, shulkermciov1alpha1.MinecraftServerVersionQuilt:
+		default:
+			allErrs = append(allErrs, field.Forbidden(configPath.Child("mods"), fmt.Sprintf("mods are not supported by the %s channel", spec.Version.Channel)))
+		}
+	}
+
+	if spec.Persistence != nil {
+		allErrs = append(allErrs, validateMinecraftServerPersistence(spec.Persistence, fldPath.Child("persistence"))...)
+	}
+
+	if spec.Backup != nil {
+		allErrs = append(allErrs, validateMinecraftServerBackup(spec.Backup, fldPath.Child("backup"))...)
+	}
+
+	var podResources *corev1.ResourceRequirements
+	if spec.PodOverrides != nil {
+		podResources = spec.PodOverrides.Resources
+	}
+	if spec.Jvm != nil {
+		allErrs = append(allErrs, validateJvm(spec.Jvm, podResources, fldPath.Child("jvm"))...)
+	}
+
+	if spec.PodOverrides != nil {
+		podOverridesPath := fldPath.Child("podOverrides")
+		allErrs = append(allErrs, validateSidecars(spec.PodOverrides.Sidecars, []string{"init-fs", "minecraft-server", "backup", "probe"}, podOverridesPath.Child("sidecars"))...)
+		allErrs = append(allErrs, validatePodTemplatePatch(spec.PodOverrides.PodTemplate, podOverridesPath.Child("podTemplate"))...)
+	}
+
+	// Bukkit and Spigot do not implement Velocity's modern forwarding
+	if spec.Configuration.ProxyForwardingMode == shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity {
+		switch spec.Version.Channel {
+		case shulkermciov1alpha1.MinecraftServerVersionBukkit, shulkermciov1alpha1.MinecraftServerVersionSpigot:
+			allErrs = append(allErrs, field.Invalid(configPath.Child("proxyForwardingMode"), spec.Configuration.ProxyForwardingMode,
+				fmt.Sprintf("is not supported by the %s channel", spec.Version.Channel)))
+		}
+	}
+
+	return allErrs
+}
+
+func validateMinecraftClusterSpec(spec *shulkermciov1alpha1.MinecraftClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spec.Cache != nil && spec.Cache.Size.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("cache", "size"), spec.Cache.Size.String(), "must be greater than 0"))
+	}
+
+	return allErrs
+}
+
+func validateMinecraftServerPersistence(persistence *shulkermciov1alpha1.MinecraftServerPersistenceSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if persistence.Size.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("size"), persistence.Size.String(), "must be greater than 0"))
+	}
+
+	return allErrs
+}
+
+func validateMinecraftServerBackup(backupSpec *shulkermciov1alpha1.MinecraftServerBackupSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if backupSpec.Schedule != "" {
+		if _, err := backup.ParseSchedule(backupSpec.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), backupSpec.Schedule, err.Error()))
+		}
+	}
+
+	if backupSpec.Retention < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("retention"), backupSpec.Retention, "must be greater than or equal to 0"))
+	}
+
+	bucketPath := fldPath.Child("bucket")
+	if backupSpec.Bucket.Endpoint == "" {
+		allErrs = append(allErrs, field.Required(bucketPath.Child("endpoint"), ""))
+	} else if endpoint, err := url.Parse(backupSpec.Bucket.Endpoint); err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		allErrs = append(allErrs, field.Invalid(bucketPath.Child("endpoint"), backupSpec.Bucket.Endpoint, "must be an http or https URL"))
+	}
+
+	if backupSpec.Bucket.Name == "" {
+		allErrs = append(allErrs, field.Required(bucketPath.Child("name"), ""))
+	}
+
+	if backupSpec.Bucket.CredentialsSecretName == "" {
+		allErrs = append(allErrs, field.Required(bucketPath.Child("credentialsSecretName"), ""))
+	}
+
+	return allErrs
+}
+
+func validateProxySpec(spec *shulkermciov1alpha1.ProxySpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	configPath := fldPath.Child("config")
+	allErrs = append(allErrs, validateResourceRefs(spec.Configuration.Plugins, configPath.Child("plugins"))...)
+	allErrs = append(allErrs, validateResourceRefs(spec.Configuration.Patches, configPath.Child("patches"))...)
+
+	var podResources *corev1.ResourceRequirements
+	if spec.PodOverrides != nil {
+		podResources = spec.PodOverrides.Resources
+	}
+	if spec.Jvm != nil {
+		allErrs = append(allErrs, validateJvm(spec.Jvm, podResources, fldPath.Child("jvm"))...)
+	}
+
+	if spec.PodOverrides != nil {
+		podOverridesPath := fldPath.Child("podOverrides")
+		allErrs = append(allErrs, validateSidecars(spec.PodOverrides.Sidecars, []string{"init-fs", "proxy", "probe"}, podOverridesPath.Child("sidecars"))...)
+		allErrs = append(allErrs, validatePodTemplatePatch(spec.PodOverrides.PodTemplate, podOverridesPath.Child("podTemplate"))...)
+	}
+
+	return allErrs
+}
+
+// A fixed heap must leave room for the JVM itself within the memory
+// limit of the container.
+func validateJvm(jvm *shulkermciov1alpha1.JvmSpec, podResources *corev1.ResourceRequirements, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	heapSizePath := fldPath.Child("heapSize")
+	if jvm.GetHeapPolicy() == shulkermciov1alpha1.JvmHeapFixed {
+		if jvm.HeapSize == nil {
+			allErrs = append(allErrs, field.Required(heapSizePath, "must be set when heapPolicy is Fixed"))
+		} else if jvm.HeapSize.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(heapSizePath, jvm.HeapSize.String(), "must be greater than 0"))
+		} else if podResources != nil {
+			if limit, ok := podResources.Limits[corev1.ResourceMemory]; ok && jvm.HeapSize.Cmp(limit) >= 0 {
+				allErrs = append(allErrs, field.Invalid(heapSizePath, jvm.HeapSize.String(), fmt.Sprintf("must be lower than the memory limit of %s", limit.String())))
+			}
+		}
+	} else if jvm.HeapSize != nil {
+		allErrs = append(allErrs, field.Forbidden(heapSizePath, "can only be set when heapPolicy is Fixed"))
+	}
+
+	for i, arg := range jvm.ExtraArgs {
+		if strings.TrimSpace(arg) == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("extraArgs").Index(i), arg, "must not be empty"))
+		}
+	}
+
+	return allErrs
+}
+
+// Sidecars cannot take the name of a container created by Shulker.
+func validateSidecars(sidecars []corev1.Container, reservedNames []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	names := map[string]bool{}
+	for _, name := range reservedNames {
+		names[name] = true
+	}
+
+	for i, sidecar := range sidecars {
+		namePath := fldPath.Index(i).Child("name")
+		if sidecar.Name == "" {
+			allErrs = append(allErrs, field.Required(namePath, ""))
+		} else if names[sidecar.Name] {
+			allErrs = append(allErrs, field.Duplicate(namePath, sidecar.Name))
+		}
+		names[sidecar.Name] = true
+	}
+
+	return allErrs
+}
+
+// The patch is applied to an empty Pod to find out whether it can be
+// applied at all.
+func validatePodTemplatePatch(patch *runtime.RawExtension, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if patch == nil || len(patch.Raw) == 0 {
+		return allErrs
+	}
+
+	if _, err := strategicpatch.StrategicMergePatch([]byte("{}"), patch.Raw, corev1.Pod{}); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, string(patch.Raw), fmt.Sprintf("is not a valid strategic merge patch of a Pod: %v", err)))
+	}
+
+	return allErrs
+}
+
+// Ensures the MinecraftServers of a cluster can accept players
+// coming from its proxies, given the forwarding mode they use.
+func validateForwardingModeInCluster(ctx context.Context, reader client.Reader, namespace string, clusterName string, forwardingMode shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingMode, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	proxyList := &shulkermciov1alpha1.ProxyList{}
+	if err := reader.List(ctx, proxyList, client.InNamespace(namespace)); err != nil {
+		allErrs = append(allErrs, field.InternalError(fldPath, err))
+		return allErrs
+	}
+	for _, proxy := range proxyList.Items {
+		if proxy.Spec.ClusterRef.Name != clusterName {
+			continue
+		}
+		if expected := getForwardingModeForProxyChannel(proxy.Spec.Version.Channel); expected != forwardingMode {
+			allErrs = append(allErrs, field.Invalid(fldPath, forwardingMode,
+				fmt.Sprintf("Proxy %s uses the %s channel which requires the %s forwarding mode", proxy.Name, proxy.Spec.Version.Channel, expected)))
+			return allErrs
+		}
+	}
+
+	proxyDeploymentList := &shulkermciov1alpha1.ProxyDeploymentList{}
+	if err := reader.List(ctx, proxyDeploymentList, client.InNamespace(namespace)); err != nil {
+		allErrs = append(allErrs, field.InternalError(fldPath, err))
+		return allErrs
+	}
+	for _, proxyDeployment := range proxyDeploymentList.Items {
+		if proxyDeployment.Spec.ClusterRef.Name != clusterName {
+			continue
+		}
+		channel := proxyDeployment.Spec.Template.Spec.Version.Channel
+		if expected := getForwardingModeForProxyChannel(channel); expected != forwardingMode {
+			allErrs = append(allErrs, field.Invalid(fldPath, forwardingMode,
+				fmt.Sprintf("ProxyDeployment %s uses the %s channel which requires the %s forwarding mode", proxyDeployment.Name, channel, expected)))
+			return allErrs
+		}
+	}
+
+	return allErrs
+}
+
+// Ensures the proxies of a cluster can forward players to its
+// MinecraftServers, given the forwarding mode they use.
+func validateProxyChannelInCluster(ctx context.Context, reader client.Reader, namespace string, clusterName string, channel shulkermciov1alpha1.ProxyVersionChannel, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	expected := getForwardingModeForProxyChannel(channel)
+
+	minecraftServerList := &shulkermciov1alpha1.MinecraftServerList{}
+	if err := reader.List(ctx, minecraftServerList, client.InNamespace(namespace)); err != nil {
+		allErrs = append(allErrs, field.InternalError(fldPath, err))
+		return allErrs
+	}
+	for _, minecraftServer := range minecraftServerList.Items {
+		if minecraftServer.Spec.ClusterRef.Name != clusterName {
+			continue
+		}
+		if forwardingMode := minecraftServer.Spec.Configuration.ProxyForwardingMode; forwardingMode != expected {
+			allErrs = append(allErrs, field.Invalid(fldPath, channel,
+				fmt.Sprintf("MinecraftServer %s uses the %s forwarding mode which requires a %s proxy", minecraftServer.Name, forwardingMode, describeProxyChannelsForForwardingMode(forwardingMode))))
+			return allErrs
+		}
+	}
+
+	minecraftServerDeploymentList := &shulkermciov1alpha1.MinecraftServerDeploymentList{}
+	if err := reader.List(ctx, minecraftServerDeploymentList, client.InNamespace(namespace)); err != nil {
+		allErrs = append(allErrs, field.InternalError(fldPath, err))
+		return allErrs
+	}
+	for _, minecraftServerDeployment := range minecraftServerDeploymentList.Items {
+		if minecraftServerDeployment.Spec.ClusterRef.Name != clusterName {
+			continue
+		}
+		if forwardingMode := minecraftServerDeployment.Spec.Template.Spec.Configuration.ProxyForwardingMode; forwardingMode != expected {
+			allErrs = append(allErrs, field.Invalid(fldPath, channel,
+				fmt.Sprintf("MinecraftServerDeployment %s uses the %s forwarding mode which requires a %s proxy", minecraftServerDeployment.Name, forwardingMode, describeProxyChannelsForForwardingMode(forwardingMode))))
+			return allErrs
+		}
+	}
+
+	return allErrs
+}
+
+func describeProxyChannelsForForwardingMode(forwardingMode shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingMode) string {
+	if forwardingMode == shulkermciov1alpha1.MincraftServerConfigurationProxyForwardingModeVelocity {
+		return string(shulkermciov1alpha1.ProxyVersionVelocity)
+	}
+
+	return fmt.Sprintf("%s or %s", shulkermciov1alpha1.ProxyVersionBungeeCord, shulkermciov1alpha1.ProxyVersionWaterfall)
+}
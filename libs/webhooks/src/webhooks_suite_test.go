@@ -0,0 +1,67 @@
+/*
+Copyright (c) Jérémy Levilain
+SPDX-License-Identifier: GPL-3.0-or-later
+*/
+
+package webhooks
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	shulkermciov1alpha1 "github.com/iamblueslime/shulker/libs/crds/v1alpha1"
+)
+
+func TestWebhooks(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Webhooks Suite")
+}
+
+const testNamespace = "default"
+const testClusterName = "my-cluster"
+
+// Returns a client knowing the given objects and the cluster they
+// are all part of.
+func newFakeReader(objects ...client.Object) client.Reader {
+	scheme := runtime.NewScheme()
+	Expect(shulkermciov1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	cluster := &shulkermciov1alpha1.MinecraftCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: testClusterName},
+	}
+
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(append(objects, cluster)...).
+		Build()
+}
+
+func testObjectMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Namespace: testNamespace, Name: name}
+}
+
+func testClusterRef() shulkermciov1alpha1.MinecraftClusterRef {
+	return shulkermciov1alpha1.MinecraftClusterRef{Name: testClusterName}
+}
+
+// Returns the paths of the fields an Invalid error complains about.
+func getInvalidFields(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an Invalid error, got %v", err)
+	fields := []string{}
+	for _, cause := range err.(apierrors.APIStatus).Status().Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	return fields
+}